@@ -6,10 +6,14 @@ import (
 	"os"
 	"time"
 
+	"backend/internal/bankfile"
 	appdb "backend/internal/db"
 	"backend/internal/handlers"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/ssofile"
 	"backend/internal/storage"
 	pgstore "backend/internal/storage/pg"
 
@@ -20,6 +24,43 @@ func main() {
 	port := getenv("PORT", "3000")
 	jwtSecret := getenv("JWT_SECRET", "dev_secret")
 
+	// นโยบายปัดเศษสตางค์ (half_up | half_even | down)
+	rounding, err := money.ParseRounding(os.Getenv("MONEY_ROUNDING"))
+	if err != nil {
+		log.Fatalf("invalid MONEY_ROUNDING: %v", err)
+	}
+	money.SetRounding(rounding)
+
+	// สูตรค่าจ้างรายวันสำหรับหักลาไม่รับค่าจ้าง (calendar30 | working_days)
+	basis, err := payroll.ParseDailyRateBasis(os.Getenv("UNPAID_LEAVE_DAILY_RATE"))
+	if err != nil {
+		log.Fatalf("invalid UNPAID_LEAVE_DAILY_RATE: %v", err)
+	}
+	payroll.SetDailyRateBasis(basis)
+
+	// บัญชีจ่ายเงินเดือนของบริษัท (บัญชีที่ถูกหักในไฟล์ pain.001 และธนาคารตั้งต้นของพนักงานที่ไม่ระบุธนาคาร)
+	co := bankfile.Company{
+		Name:     os.Getenv("COMPANY_NAME"),
+		BankCode: os.Getenv("COMPANY_BANK"),
+		Account:  os.Getenv("COMPANY_BANK_ACCOUNT"),
+		ID:       os.Getenv("COMPANY_BANK_ID"),
+	}
+	if err := co.Validate(); err != nil {
+		log.Fatalf("invalid company bank settings: %v", err)
+	}
+	bankfile.SetCompany(co)
+
+	// ทะเบียนนายจ้างประกันสังคม (ไฟล์ สปส.1-10) ชื่อสถานประกอบการใช้ชื่อบริษัท
+	er := ssofile.Employer{
+		Account: os.Getenv("SSO_EMPLOYER_ACCOUNT"),
+		Branch:  os.Getenv("SSO_BRANCH"),
+		Name:    co.Name,
+	}
+	ssofile.SetEmployer(er)
+	if err := ssofile.CurrentEmployer().Validate(); err != nil {
+		log.Fatalf("invalid SSO employer settings: %v", err)
+	}
+
 	// เลือก storage ตาม ENV
 	var store storage.Port
 	if os.Getenv("USE_DATABASE") == "1" {
@@ -52,6 +93,13 @@ func main() {
 	payH := handlers.NewPayrollHandler(store)
 	psH := handlers.NewPayslipHandler(store)
 	lvH := handlers.NewLeaveHandler(store)
+	rtH := handlers.NewRateHandler(store)
+	pcH := handlers.NewComponentHandler(store)
+	pgH := handlers.NewPayGroupHandler(store)
+	salH := handlers.NewSalaryHandler(store)
+	tmH := handlers.NewTerminationHandler(store)
+	lnH := handlers.NewLoanHandler(store)
+	gnH := handlers.NewGarnishmentHandler(store)
 
 	// Routes
 	api := r.Group("/api/v1")
@@ -68,13 +116,61 @@ func main() {
 		// Employees
 		secured.GET("/employees", empH.List)
 		secured.POST("/employees", empH.Create)
+		secured.GET("/banks", empH.Banks)
+		secured.GET("/employees/:id/components", pcH.List)
+		secured.POST("/employees/:id/components", pcH.Create)
+		secured.PUT("/employees/:id/components/:componentId", pcH.Update)
+		secured.PUT("/employees/:id/sso", empH.UpdateSSO)
+		secured.GET("/employees/:id/salary", salH.Timeline)
+		secured.POST("/employees/:id/salary", salH.Create)
+		secured.GET("/employees/:id/settlement", tmH.Settlement)
+		secured.POST("/employees/:id/terminate", tmH.Terminate)
+		secured.GET("/employees/:id/loans", lnH.List)
+		secured.POST("/employees/:id/loans", lnH.Create)
+		secured.GET("/employees/:id/garnishments", gnH.List)
+		secured.POST("/employees/:id/garnishments", gnH.Create)
+
+		// Loans (เงินกู้/เงินเดือนล่วงหน้า ผ่อนชำระผ่าน payroll)
+		secured.GET("/loans/:id", lnH.Get)
+		secured.POST("/loans/:id/pause", lnH.Pause)
+		secured.POST("/loans/:id/resume", lnH.Resume)
+		secured.POST("/loans/:id/payoff", lnH.Payoff)
+
+		// Garnishments (คำสั่งอายัดเงินเดือนของกรมบังคับคดี)
+		secured.GET("/garnishments/:id", gnH.Get)
+		secured.POST("/garnishments/:id/status", gnH.SetStatus)
+
+		// Pay groups (ความถี่การจ่ายและปฏิทินงวด)
+		secured.GET("/pay-groups", pgH.List)
+		secured.POST("/pay-groups", pgH.Create)
+		secured.GET("/pay-groups/:id/calendar", pgH.Calendar)
+		secured.PUT("/pay-groups/:id/employees", pgH.AssignEmployees)
 
 		// Payroll
+		secured.GET("/payroll/runs", payH.ListRuns)
 		secured.POST("/payroll/runs", payH.CreateRun)
+		secured.GET("/payroll/runs/:id", payH.GetRun)
+		secured.DELETE("/payroll/runs/:id", payH.DeleteRun)
+		secured.GET("/payroll/runs/:id/preview", payH.PreviewRun)
 		secured.POST("/payroll/runs/:id/calculate", payH.CalculateRun)
+		secured.POST("/payroll/runs/:id/status", payH.TransitionRun)
+		secured.GET("/payroll/runs/:id/transitions", payH.ListRunTransitions)
+		secured.GET("/payroll/runs/:id/inputs", payH.ListRunInputs)
+		secured.POST("/payroll/runs/:id/inputs", payH.CreateRunInput)
+		secured.DELETE("/payroll/runs/:id/inputs/:inputId", payH.DeleteRunInput)
 		secured.GET("/payroll/runs/:id/items", payH.ListRunItems)
-		secured.POST("/payroll/runs/:id/export-bank-csv", payH.ExportBankCSV)
+		secured.GET("/payroll/runs/:id/totals", payH.RunTotals)
+		secured.GET("/payroll/runs/:id/retro", payH.RetroReport)
+		secured.GET("/payroll/runs/:id/garnishments", payH.RemittanceReport)
+		secured.GET("/payroll/runs/:id/bank-file", payH.ExportBankFile)
+		secured.GET("/payroll/runs/:id/pain001", payH.ExportPain001)
+		secured.GET("/payroll/runs/:id/promptpay-file", payH.ExportPromptPay)
+		secured.GET("/payroll/runs/:id/payments", payH.PaymentSummary)
+		secured.GET("/payroll/runs/:id/sso", payH.SSOSummary)
+		secured.GET("/payroll/runs/:id/sso-file", payH.ExportSSOFile)
+		secured.POST("/payroll/runs/:id/export-bank-csv", payH.ExportBankFile) // เส้นทางเดิม
 		secured.POST("/payroll/items/:id", payH.UpdatePayrollItem)
+		secured.GET("/payroll/items/:id/trace", payH.ItemTrace)
 
 		// Payslips
 		secured.GET("/payslips/:runId", psH.ListByRun)
@@ -83,6 +179,24 @@ func main() {
 		// Leaves
 		secured.GET("/leave", lvH.List)
 		secured.POST("/leave", lvH.Create)
+		secured.GET("/leave/balances", lvH.Balances)
+		secured.GET("/leave/policies", lvH.Policies)
+		secured.PUT("/leave/policies/:type", lvH.SavePolicy)
+		secured.GET("/leave/:id", lvH.Get)
+		secured.POST("/leave/:id/cancel", lvH.Cancel)
+
+		// Leave approval (เฉพาะผู้อนุมัติ)
+		approver := secured.Group("/")
+		if os.Getenv("NO_AUTH") != "1" {
+			approver.Use(middleware.RequireRole(leaveApproverRoles...))
+		}
+		approver.POST("/leave/:id/approve", lvH.Approve)
+		approver.POST("/leave/:id/reject", lvH.Reject)
+
+		// Statutory rate tables
+		secured.GET("/rates", rtH.List)
+		secured.GET("/rates/effective", rtH.Effective)
+		secured.POST("/rates", rtH.Create)
 	}
 
 	log.Printf("✅ Server ready at http://localhost:%s", port)
@@ -91,6 +205,9 @@ func main() {
 	}
 }
 
+// leaveApproverRoles role ที่อนุมัติ/ไม่อนุมัติใบลาได้
+var leaveApproverRoles = []string{"ADMIN", "HR", "MANAGER"}
+
 func getenv(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
@@ -122,12 +239,14 @@ func seedSampleData(store storage.Port) {
 		position   string
 		baseSalary float64
 		bankAcc    string
+		bankCode   string
+		nationalID string
 	}{
-		{"E001", "สมชาย", "ใจดี", "IT", "Senior Developer", 50000, "001-234567-8"},
-		{"E002", "สมหญิง", "รักสงบ", "HR", "HR Manager", 45000, "001-345678-9"},
-		{"E003", "ประเสริฐ", "มั่นคง", "Accounting", "Accountant", 40000, "001-456789-0"},
-		{"E004", "วิไล", "สว่างใจ", "IT", "Junior Developer", 30000, "001-567890-1"},
-		{"E005", "ธนากร", "มีเงิน", "Finance", "Financial Analyst", 48000, "001-678901-2"},
+		{"E001", "สมชาย", "ใจดี", "IT", "Senior Developer", 50000, "0012345678", "004", "1101700123456"},
+		{"E002", "สมหญิง", "รักสงบ", "HR", "HR Manager", 45000, "0013456789", "014", "3100600234561"},
+		{"E003", "ประเสริฐ", "มั่นคง", "Accounting", "Accountant", 40000, "0014567890", "002", "1509900345677"},
+		{"E004", "วิไล", "สว่างใจ", "IT", "Junior Developer", 30000, "0015678901", "006", "1200800456781"},
+		{"E005", "ธนากร", "มีเงิน", "Finance", "Financial Analyst", 48000, "0016789012", "025", "3401200567898"},
 	}
 
 	for _, emp := range employees {
@@ -137,9 +256,13 @@ func seedSampleData(store storage.Port) {
 			LastName:    emp.lastName,
 			Department:  emp.department,
 			Position:    emp.position,
-			BaseSalary:  emp.baseSalary,
+			BaseSalary:  money.FromBaht(emp.baseSalary),
 			Status:      "active",
 			BankAccount: emp.bankAcc,
+			BankCode:    emp.bankCode,
+			PVDRate:     0.03,
+			SSOEnabled:  true,
+			NationalID:  emp.nationalID,
 			HiredAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 		}
 		if err := store.CreateEmployee(e); err != nil {
@@ -0,0 +1,206 @@
+// Package bankfile สร้างไฟล์โอนเงินเดือนเข้าบัญชีพนักงาน (payroll direct credit)
+// ไฟล์ธนาคารเป็น CSV กลางสำหรับนำเข้า และ ISO 20022 pain.001 สำหรับ corporate banking portal
+// ยังไม่มี layout fixed-width เฉพาะธนาคาร: จะเพิ่มได้เมื่อมีเอกสาร spec ของธนาคาร (ชื่อและเลขฉบับ) พร้อมไฟล์ตัวอย่างที่ธนาคารเผยแพร่ไว้ทำ golden test
+package bankfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/internal/money"
+)
+
+// Company บัญชีของบริษัทที่ใช้จ่ายเงินเดือน (ตั้งจาก config ตอนเริ่มระบบ)
+type Company struct {
+	Name     string // ชื่อบริษัทตามบัญชี
+	BankCode string // รหัสธนาคาร 3 หลัก เช่น 004 = กสิกรไทย
+	Account  string // เลขที่บัญชีที่ถูกหักเงิน
+	ID       string // รหัสบริษัทที่ธนาคารกำหนดให้ (company/customer ID ของบริการ payroll)
+}
+
+var company Company
+
+// SetCompany ตั้งบัญชีจ่ายเงินเดือนของบริษัทที่ใช้ทั้งระบบ
+func SetCompany(c Company) { company = c }
+
+// CurrentCompany บัญชีจ่ายเงินเดือนของบริษัทที่ตั้งไว้
+func CurrentCompany() Company { return company }
+
+// Validate ตรวจรหัสธนาคารและเลขบัญชีของบริษัท (ไม่ตั้งธนาคาร = ยังไม่ได้ตั้งบัญชีจ่ายเงินเดือน)
+func (c Company) Validate() error {
+	if c.BankCode == "" {
+		return nil
+	}
+	if c.Name == "" || digits(c.Account) == "" {
+		return errors.New("company name and bank account are required")
+	}
+	if _, err := ValidateAccount(c.BankCode, c.Account); err != nil {
+		return fmt.Errorf("company bank account: %w", err)
+	}
+	return nil
+}
+
+// companyAccount เลขบัญชีที่ถูกหักเงินของบริษัท (ตัดขีดออกแล้ว) ตรวจตามธนาคารของบริษัทและต้องพอดี field width หลักของ layout
+// ไม่ตัดหรือเติมเลขบัญชีเอง: บัญชีที่ผิดรูปแบบทำให้ธนาคารปฏิเสธทั้งไฟล์หรือหักผิดบัญชี
+func companyAccount(co Company, width int) (string, error) {
+	acct, err := ValidateAccount(co.BankCode, co.Account)
+	if err == nil && len(acct) > width {
+		err = fmt.Errorf("%w: company account is longer than %d digits", ErrInvalidAccount, width)
+	}
+	if err != nil {
+		return "", &BatchError{Problems: []Problem{{Field: "companyAccount", Message: err.Error()}}}
+	}
+	return acct, nil
+}
+
+// Credit รายการโอนเข้าบัญชีพนักงานหนึ่งรายการ
+type Credit struct {
+	Ref      string       // รหัสพนักงาน (แสดงใน statement ของธนาคาร)
+	Name     string       // ชื่อเจ้าของบัญชี
+	BankCode string       // ธนาคารของบัญชีปลายทาง (ว่างได้เฉพาะไฟล์ CSV)
+	Account  string       // เลขที่บัญชีปลายทาง (ขีด/ช่องว่างถูกตัดออกตอนเขียนไฟล์)
+	Amount   money.Amount // ยอดโอน
+
+	// ProxyType / ProxyID หมายเลขพร้อมเพย์ (ไฟล์ PromptPay ใช้แทน BankCode/Account)
+	ProxyType string
+	ProxyID   string
+}
+
+// Batch ชุดรายการโอนของ payroll run หนึ่ง run
+type Batch struct {
+	Company   Company
+	Reference string    // อ้างอิงของชุด เช่น RUN-12
+	Effective time.Time // วันที่โอนเข้าบัญชีพนักงาน
+	Created   time.Time
+	Credits   []Credit
+}
+
+// Total ยอดรวมของทุกรายการ (control total ใน trailer)
+func (b Batch) Total() money.Amount {
+	var t money.Amount
+	for _, c := range b.Credits {
+		t += c.Amount
+	}
+	return t
+}
+
+// Problem ข้อมูลของรายการที่ทำให้สร้างไฟล์ไม่ได้
+type Problem struct {
+	Ref     string `json:"ref"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// BatchError รายการที่ไม่ผ่านการตรวจทั้งหมด (ธนาคารจะปฏิเสธทั้งไฟล์ จึงไม่สร้างไฟล์บางส่วน)
+type BatchError struct {
+	Problems []Problem
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("bank file has %d invalid credit(s)", len(e.Problems))
+}
+
+// Writer เขียนไฟล์โอนเงินของธนาคารหนึ่ง
+type Writer interface {
+	Bank() string      // รหัสธนาคาร 3 หลักของ layout เฉพาะธนาคาร ("" = ใช้ได้กับทุกธนาคาร)
+	Format() string    // ชื่อรูปแบบไฟล์
+	Extension() string // นามสกุลไฟล์
+	Write(w io.Writer, b Batch) error
+}
+
+// For Writer ของไฟล์ธนาคาร: ทุกธนาคารใช้ไฟล์ CSV กลาง รหัสธนาคารที่ไม่รู้จักเป็น error
+func For(bankCode string) (Writer, error) {
+	if bankCode != "" {
+		if _, ok := LookupBank(bankCode); !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownBank, bankCode)
+		}
+	}
+	return csvWriter{}, nil
+}
+
+// validate ตรวจรายการก่อนเขียนไฟล์ตามความกว้างของ field เลขบัญชี ยอดเงิน และยอดรวมใน trailer ของรูปแบบธนาคาร
+// รายการพร้อมเพย์ตรวจหมายเลขพร้อมเพย์แทนเลขบัญชี
+func validate(b Batch, accountWidth, amountWidth, totalWidth int) error {
+	var probs []Problem
+	if len(b.Credits) == 0 {
+		probs = append(probs, Problem{Field: "credits", Message: "no credits to transfer"})
+	}
+	limit := maxAmount(amountWidth)
+	for _, c := range b.Credits {
+		if c.ProxyType != "" {
+			if _, err := ValidatePromptPay(c.ProxyType, c.ProxyID); err != nil {
+				probs = append(probs, Problem{c.Ref, "promptPayId", err.Error()})
+			}
+		} else {
+			probs = append(probs, validateAccount(c, accountWidth)...)
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			probs = append(probs, Problem{c.Ref, "name", "account name is missing"})
+		}
+		if c.Amount <= 0 || c.Amount > limit {
+			probs = append(probs, Problem{c.Ref, "amount", "amount must be > 0 and fit the file layout"})
+		}
+	}
+	if b.Total() > maxAmount(totalWidth) {
+		probs = append(probs, Problem{Field: "total", Message: "batch total does not fit the file layout"})
+	}
+	if len(probs) > 0 {
+		return &BatchError{Problems: probs}
+	}
+	return nil
+}
+
+// validateAccount ตรวจบัญชีปลายทางของรายการโอนเข้าบัญชีธนาคาร
+func validateAccount(c Credit, accountWidth int) []Problem {
+	var probs []Problem
+	acct := NormalizeAccount(c.Account)
+	switch {
+	case strings.TrimSpace(c.Account) == "":
+		probs = append(probs, Problem{c.Ref, "account", "bank account is missing"})
+	case acct == "":
+		probs = append(probs, Problem{c.Ref, "account", "bank account must contain digits only"})
+	case c.BankCode != "":
+		if _, err := ValidateAccount(c.BankCode, acct); err != nil {
+			field := "account"
+			if errors.Is(err, ErrUnknownBank) {
+				field = "bankCode"
+			}
+			probs = append(probs, Problem{c.Ref, field, err.Error()})
+		}
+	}
+	if len(acct) > accountWidth {
+		probs = append(probs, Problem{c.Ref, "account", fmt.Sprintf("bank account is longer than %d digits", accountWidth)})
+	}
+	return probs
+}
+
+// maxAmount ยอดสูงสุดที่เขียนเป็นสตางค์ได้ใน field ตัวเลข width หลัก
+func maxAmount(width int) money.Amount {
+	if width >= 18 {
+		width = 18
+	}
+	var n int64 = 1
+	for i := 0; i < width; i++ {
+		n *= 10
+	}
+	return money.Satang(n - 1)
+}
+
+// notAccountRune อักขระที่ไม่ควรอยู่ในเลขบัญชี (ยอมให้มีขีดและช่องว่างคั่น)
+func notAccountRune(r rune) bool {
+	return (r < '0' || r > '9') && r != '-' && r != ' '
+}
+
+// digits ตัดทุกอักขระที่ไม่ใช่ตัวเลข (เลขบัญชีที่บันทึกแบบ 001-234567-8)
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package bankfile
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backend/internal/money"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+// sampleBatch ชุดโอนเงินตัวอย่างที่ใช้กับ golden file (วันที่คงที่ ชื่อไทยตรวจการเข้ารหัส TIS-620)
+func sampleBatch(bank string) Batch {
+	return Batch{
+		Company:   Company{Name: "บริษัท ตัวอย่าง จำกัด", BankCode: bank, Account: "123-4-56789-0", ID: "ACME001"},
+		Reference: "RUN-12",
+		Effective: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC),
+		Created:   time.Date(2026, 2, 2, 9, 30, 15, 0, time.UTC),
+		Credits: []Credit{
+			{Ref: "E001", Name: "สมชาย ใจดี", BankCode: bank, Account: "987-6-54321-0", Amount: money.FromBaht(25430.50)},
+			{Ref: "E002", Name: "Jane Doe", BankCode: "014", Account: "1112223334", Amount: money.Satang(1234567)},
+		},
+	}
+}
+
+func promptPayBatch() Batch {
+	b := sampleBatch("004")
+	b.Reference = "RUN-12-PP"
+	b.Credits = []Credit{
+		{Ref: "E003", Name: "สมหญิง รักงาน", ProxyType: "mobile", ProxyID: "081-234-5678", Amount: money.FromBaht(18000)},
+		{Ref: "E004", Name: "John Smith", ProxyType: "national_id", ProxyID: "1101700123456", Amount: money.FromBaht(9999.99)},
+	}
+	return b
+}
+
+// golden เทียบผลกับ testdata/<name>.golden (go test -update เขียนไฟล์ใหม่)
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from %s:\n got: %q\nwant: %q", name, path, got, want)
+	}
+}
+
+// ไฟล์พร้อมเพย์เป็น pain.001 ที่ระบุผู้รับด้วย proxy (MBNO/NIDN) ไม่มีเลขบัญชีหรือธนาคารผู้รับ
+func TestPromptPayGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (PromptPay{}).Write(&buf, promptPayBatch()); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "promptpay", buf.Bytes())
+	validateSchema(t, buf.Bytes())
+
+	// รายการโอนเข้าบัญชีปนมาในไฟล์พร้อมเพย์ไม่ได้
+	b := promptPayBatch()
+	b.Credits = append(b.Credits, sampleBatch("004").Credits[0])
+	err := (PromptPay{}).Write(&bytes.Buffer{}, b)
+	var be *BatchError
+	if !errors.As(err, &be) || len(be.Problems) != 1 || be.Problems[0].Ref != "E001" || be.Problems[0].Field != "promptPayId" {
+		t.Errorf("bank credit in PromptPay file: err = %v, want one promptPayId problem for E001", err)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	cases := []struct {
+		typ, id string
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{"mobile", "081-234-5678", "MBNO", "0066812345678", false},
+		{"mobile", "+66 81 234 5678", "MBNO", "0066812345678", false},
+		{"national_id", "1101700123456", "NIDN", "1101700123456", false},
+		{"mobile", "0212345678", "", "", true},
+		{"national_id", "1101700123457", "", "", true},
+		{"email", "a@b.c", "", "", true},
+	}
+	for _, tc := range cases {
+		got, err := proxy(Credit{ProxyType: tc.typ, ProxyID: tc.id})
+		if (err != nil) != tc.wantErr {
+			t.Errorf("proxy(%s, %q): err = %v, wantErr %v", tc.typ, tc.id, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (got.Tp.Cd != tc.code || got.ID != tc.want) {
+			t.Errorf("proxy(%s, %q) = %s/%s, want %s/%s", tc.typ, tc.id, got.Tp.Cd, got.ID, tc.code, tc.want)
+		}
+	}
+}
+
+// บัญชีบริษัทที่ไม่ตรงกับธนาคารหรือไม่พอดี field ต้องถูกปฏิเสธ ไม่ถูกตัด/เติมเงียบ ๆ
+func TestCompanyAccountIsValidated(t *testing.T) {
+	writers := map[string]Writer{
+		"promptpay": PromptPay{}, "pain001": Pain001{},
+	}
+	bankOf := map[string]string{"promptpay": "004", "pain001": "004"}
+	for name, w := range writers {
+		for _, acct := range []string{"12345", "123-456-789-012", "1111111111", "12A4567890"} {
+			b := sampleBatch(bankOf[name])
+			if name == "promptpay" {
+				b = promptPayBatch()
+			}
+			b.Company.Account = acct
+			err := w.Write(&bytes.Buffer{}, b)
+			var be *BatchError
+			if !errors.As(err, &be) || len(be.Problems) != 1 || be.Problems[0].Field != "companyAccount" {
+				t.Errorf("%s with company account %q: err = %v, want a companyAccount problem", name, acct, err)
+			}
+		}
+	}
+}
+
+func TestCompanyValidate(t *testing.T) {
+	ok := Company{Name: "ACME", BankCode: "004", Account: "123-4-56789-0"}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("valid company: %v", err)
+	}
+	bad := ok
+	bad.Account = "123456789012"
+	if err := bad.Validate(); !errors.Is(err, ErrInvalidAccount) {
+		t.Fatalf("12-digit KBank account: err = %v, want ErrInvalidAccount", err)
+	}
+}
+
+// ทุกธนาคารที่รู้จักได้ไฟล์ CSV กลาง (ยังไม่มี layout เฉพาะธนาคารที่ยืนยันกับ spec ได้) รหัสที่ไม่รู้จักเป็น error
+func TestFor(t *testing.T) {
+	cases := []struct {
+		bank    string
+		format  string
+		wantErr error
+	}{
+		{"", "CSV", nil},
+		{"002", "CSV", nil},
+		{"004", "CSV", nil},
+		{"014", "CSV", nil},
+		{"999", "", ErrUnknownBank},
+	}
+	for _, tc := range cases {
+		w, err := For(tc.bank)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("For(%q): err = %v, want %v", tc.bank, err, tc.wantErr)
+			continue
+		}
+		if err == nil && w.Format() != tc.format {
+			t.Errorf("For(%q) = %s, want %s", tc.bank, w.Format(), tc.format)
+		}
+	}
+}
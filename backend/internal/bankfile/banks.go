@@ -0,0 +1,92 @@
+package bankfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownBank    = errors.New("unknown bank code")
+	ErrInvalidAccount = errors.New("invalid bank account")
+)
+
+// Bank ธนาคารที่รับโอนเงินเดือนได้ (รหัสสมาชิกระบบชำระเงินของ ธปท.)
+type Bank struct {
+	Code           string `json:"code"`
+	Abbr           string `json:"abbr"`
+	Name           string `json:"name"`
+	NameEN         string `json:"nameEn"`
+	AccountLengths []int  `json:"accountLengths"` // จำนวนหลักของเลขบัญชีที่ธนาคารใช้
+}
+
+// ThaiBanks รายชื่อธนาคารเรียงตามรหัส (เพิ่ม/แก้ที่นี่เมื่อ ธปท. ประกาศรหัสใหม่)
+var ThaiBanks = []Bank{
+	{"002", "BBL", "ธนาคารกรุงเทพ", "Bangkok Bank", []int{10}},
+	{"004", "KBANK", "ธนาคารกสิกรไทย", "Kasikornbank", []int{10}},
+	{"006", "KTB", "ธนาคารกรุงไทย", "Krung Thai Bank", []int{10}},
+	{"011", "TTB", "ธนาคารทหารไทยธนชาต", "TMBThanachart Bank", []int{10}},
+	{"014", "SCB", "ธนาคารไทยพาณิชย์", "Siam Commercial Bank", []int{10}},
+	{"017", "CITI", "ธนาคารซิตี้แบงก์", "Citibank", []int{10}},
+	{"020", "SCBT", "ธนาคารสแตนดาร์ดชาร์เตอร์ด (ไทย)", "Standard Chartered Bank (Thai)", []int{11}},
+	{"022", "CIMBT", "ธนาคารซีไอเอ็มบี ไทย", "CIMB Thai Bank", []int{10}},
+	{"024", "UOBT", "ธนาคารยูโอบี", "United Overseas Bank (Thai)", []int{10}},
+	{"025", "BAY", "ธนาคารกรุงศรีอยุธยา", "Bank of Ayudhya", []int{10}},
+	{"030", "GSB", "ธนาคารออมสิน", "Government Savings Bank", []int{12}},
+	{"033", "GHB", "ธนาคารอาคารสงเคราะห์", "Government Housing Bank", []int{12}},
+	{"034", "BAAC", "ธนาคารเพื่อการเกษตรและสหกรณ์การเกษตร", "Bank for Agriculture and Agricultural Cooperatives", []int{12}},
+	{"066", "IBANK", "ธนาคารอิสลามแห่งประเทศไทย", "Islamic Bank of Thailand", []int{10}},
+	{"067", "TISCO", "ธนาคารทิสโก้", "TISCO Bank", []int{10}},
+	{"069", "KKP", "ธนาคารเกียรตินาคินภัทร", "Kiatnakin Phatra Bank", []int{10}},
+	{"070", "ICBCT", "ธนาคารไอซีบีซี (ไทย)", "ICBC (Thai)", []int{10}},
+	{"071", "TCRB", "ธนาคารไทยเครดิต", "Thai Credit Bank", []int{10, 12}},
+	{"073", "LHFG", "ธนาคารแลนด์ แอนด์ เฮ้าส์", "Land and Houses Bank", []int{10}},
+}
+
+// LookupBank ธนาคารตามรหัส
+func LookupBank(code string) (Bank, bool) {
+	for _, b := range ThaiBanks {
+		if b.Code == code {
+			return b, true
+		}
+	}
+	return Bank{}, false
+}
+
+// NormalizeAccount เลขบัญชีที่ตัดขีดและช่องว่างออก (อักขระอื่นที่ไม่ใช่ตัวเลขทำให้ผลเป็น "")
+func NormalizeAccount(s string) string {
+	if strings.IndexFunc(s, notAccountRune) >= 0 {
+		return ""
+	}
+	return digits(s)
+}
+
+// ValidateAccount ตรวจรหัสธนาคารและเลขบัญชีตามจำนวนหลักของธนาคาร คืนเลขบัญชีที่ตัดขีดออกแล้ว
+// (ธนาคารไม่เปิดเผยสูตรหลักตรวจสอบ เลขบัญชีที่ผ่านยังต้องให้ธนาคารยืนยันชื่อบัญชีตอนนำเข้าไฟล์)
+func ValidateAccount(bankCode, account string) (string, error) {
+	b, ok := LookupBank(bankCode)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownBank, bankCode)
+	}
+	acct := NormalizeAccount(account)
+	if acct == "" {
+		return "", fmt.Errorf("%w: account number must contain digits only", ErrInvalidAccount)
+	}
+	for _, n := range b.AccountLengths {
+		if len(acct) == n {
+			if strings.Count(acct, acct[:1]) == len(acct) {
+				return "", fmt.Errorf("%w: account number cannot be a single repeated digit", ErrInvalidAccount)
+			}
+			return acct, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s account numbers have %s digits", ErrInvalidAccount, b.Abbr, joinInts(b.AccountLengths))
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = fmt.Sprint(n)
+	}
+	return strings.Join(s, " or ")
+}
@@ -0,0 +1,26 @@
+package bankfile
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter ไฟล์ CSV กลาง (UTF-8) สำหรับตรวจยอดและนำเข้าบริการ payroll ของธนาคารที่รับไฟล์ CSV หรือนำเข้าด้วยมือ
+type csvWriter struct{}
+
+func (csvWriter) Bank() string      { return "" }
+func (csvWriter) Format() string    { return "CSV" }
+func (csvWriter) Extension() string { return "csv" }
+
+func (csvWriter) Write(w io.Writer, b Batch) error {
+	if err := validate(b, 20, 15, 18); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"employee_code", "name", "bank_code", "account", "amount", "ref"})
+	for _, c := range b.Credits {
+		_ = cw.Write([]string{c.Ref, c.Name, c.BankCode, digits(c.Account), c.Amount.String(), b.Reference})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,285 @@
+package bankfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"backend/internal/money"
+)
+
+// Pain001 ไฟล์ ISO 20022 pain.001.001.09 (Customer Credit Transfer Initiation) สำหรับ corporate banking portal
+// หนึ่ง PmtInf ต่อ run: หักบัญชีบริษัทครั้งเดียว (batch booking) โอนเข้าบัญชีพนักงานรายการละหนึ่ง CdtTrfTxInf
+// รายการพร้อมเพย์ระบุผู้รับด้วย CdtrAcct/Prxy (ดู PromptPay)
+// ธนาคารระบุด้วยรหัสสมาชิกระบบชำระเงิน 3 หลักใน ClrSysMmbId/MmbId และ category purpose = SALA (เงินเดือน)
+type Pain001 struct{}
+
+// Pain001Namespace namespace ของ schema pain.001.001.09
+const Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+
+const (
+	isoMax34  = 34 // เลขบัญชี (GenericAccountIdentification1/Id)
+	isoMax35  = 35
+	isoMax140 = 140
+)
+
+func (Pain001) Bank() string      { return "" }
+func (Pain001) Format() string    { return "ISO 20022 pain.001.001.09" }
+func (Pain001) Extension() string { return "xml" }
+
+func (Pain001) Write(w io.Writer, b Batch) error {
+	if err := validate(b, isoMax34, 18, 18); err != nil {
+		return err
+	}
+	co := b.Company
+	if co.Name == "" || co.BankCode == "" || digits(co.Account) == "" {
+		return &BatchError{Problems: []Problem{{Field: "company", Message: "company name, bank and account are required (COMPANY_NAME, COMPANY_BANK, COMPANY_BANK_ACCOUNT)"}}}
+	}
+	acct, err := companyAccount(co, isoMax34)
+	if err != nil {
+		return err
+	}
+	msgID := b.Reference + "-" + b.Created.Format("20060102150405")
+	if err := checkIDs(b, msgID); err != nil {
+		return err
+	}
+
+	count, total := fmt.Sprint(len(b.Credits)), decimal(b.Total())
+	pmt := painPmtInf{
+		PmtInfID:    b.Reference,
+		PmtMtd:      "TRF",
+		BtchBookg:   true,
+		NbOfTxs:     count,
+		CtrlSum:     total,
+		PmtTpInf:    &painPmtTpInf{CtgyPurp: &painCode{Cd: "SALA"}},
+		ReqdExctnDt: painDateChoice{Dt: b.Effective.Format("2006-01-02")},
+		Dbtr:        painParty{Nm: max140(co.Name)},
+		DbtrAcct:    painAccount{ID: &painAccountID{Othr: &painOther{ID: acct}}, Ccy: "THB"},
+		DbtrAgt:     *agent(co.BankCode),
+	}
+	for _, c := range b.Credits {
+		cdtrAgt, cdtrAcct, err := creditor(co, c)
+		if err != nil {
+			return err
+		}
+		pmt.CdtTrfTxInf = append(pmt.CdtTrfTxInf, painTx{
+			PmtID:    painPmtID{InstrID: c.Ref, EndToEndID: endToEndID(b, c)},
+			Amt:      painAmt{InstdAmt: painAmount{Ccy: "THB", Value: decimal(c.Amount)}},
+			CdtrAgt:  cdtrAgt,
+			Cdtr:     painParty{Nm: max140(c.Name)},
+			CdtrAcct: cdtrAcct,
+			Purp:     &painCode{Cd: "SALA"},
+			RmtInf:   &painRmtInf{Ustrd: max140("Salary " + b.Reference)},
+		})
+	}
+
+	initg := painParty{Nm: max140(co.Name)}
+	if co.ID != "" {
+		initg.ID = &painPartyID{OrgID: &painOrgID{Othr: &painOther{ID: co.ID}}}
+	}
+	doc := painDocument{
+		Xmlns: Pain001Namespace,
+		Initn: painInitn{
+			GrpHdr: painGrpHdr{
+				MsgID:    msgID,
+				CreDtTm:  b.Created.Format("2006-01-02T15:04:05"),
+				NbOfTxs:  count,
+				CtrlSum:  total,
+				InitgPty: initg,
+			},
+			PmtInf: []painPmtInf{pmt},
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// creditor ธนาคารและบัญชีของผู้รับ: รายการพร้อมเพย์ใช้ proxy (ไม่ระบุธนาคาร)
+// รายการโอนเข้าบัญชีใช้เลขบัญชีกับธนาคารของพนักงาน (ว่าง = ธนาคารเดียวกับบริษัท)
+func creditor(co Company, c Credit) (*painAgent, painAccount, error) {
+	if c.ProxyType != "" {
+		prxy, err := proxy(c)
+		if err != nil {
+			return nil, painAccount{}, err
+		}
+		return nil, painAccount{Prxy: prxy}, nil
+	}
+	bank := c.BankCode
+	if bank == "" {
+		bank = co.BankCode
+	}
+	return agent(bank), painAccount{ID: &painAccountID{Othr: &painOther{ID: digits(c.Account)}}}, nil
+}
+
+// agent ธนาคารตามรหัสสมาชิกระบบชำระเงิน (ไม่มีรหัส = ไม่ระบุธนาคาร)
+func agent(bankCode string) *painAgent {
+	if bankCode == "" {
+		return nil
+	}
+	return &painAgent{FinInstnID: painFinInstnID{ClrSysMmbID: &painClrSysMmbID{MmbID: bankCode}}}
+}
+
+// decimal ยอดเงินทศนิยม 2 ตำแหน่งตาม ActiveOrHistoricCurrencyAndAmount/DecimalNumber
+func decimal(a money.Amount) string { return a.String() }
+
+// endToEndID รหัสอ้างอิงที่ส่งต่อถึงผู้รับโอน ธนาคารใช้จับคู่รายการตอนตอบกลับสถานะ (pain.002) และใน statement
+func endToEndID(b Batch, c Credit) string { return b.Reference + "-" + c.Ref }
+
+// checkIDs ตรวจรหัสอ้างอิงที่ต้องไม่เกิน Max35Text
+// ไม่ตัดให้สั้นลงเหมือนชื่อ: รหัสที่ถูกตัดอาจซ้ำกันหรือจับคู่กับรายการเดิมไม่ได้ จึงปฏิเสธทั้งไฟล์แทน
+func checkIDs(b Batch, msgID string) error {
+	var probs []Problem
+	long := func(ref, field, id string) {
+		if n := len([]rune(id)); n > isoMax35 {
+			probs = append(probs, Problem{ref, field, fmt.Sprintf("%s is %d characters, pain.001 allows %d", field, n, isoMax35)})
+		}
+	}
+	long("", "msgId", msgID)
+	long("", "companyId", b.Company.ID)
+	for _, c := range b.Credits {
+		long(c.Ref, "instrId", c.Ref)
+		long(c.Ref, "endToEndId", endToEndID(b, c))
+	}
+	if len(probs) > 0 {
+		return &BatchError{Problems: probs}
+	}
+	return nil
+}
+
+// max140 ชื่อและข้อความอิสระตัดให้พอดี Max140Text ได้ (ไม่ใช้จับคู่รายการ)
+func max140(s string) string { return truncate(s, isoMax140) }
+
+func truncate(s string, n int) string {
+	if rs := []rune(s); len(rs) > n {
+		return string(rs[:n])
+	}
+	return s
+}
+
+// element ของ pain.001.001.09 เรียงตามลำดับใน schema (sequence) เฉพาะที่ใช้
+
+type painDocument struct {
+	XMLName xml.Name  `xml:"Document"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Initn   painInitn `xml:"CstmrCdtTrfInitn"`
+}
+
+type painInitn struct {
+	GrpHdr painGrpHdr   `xml:"GrpHdr"`
+	PmtInf []painPmtInf `xml:"PmtInf"`
+}
+
+type painGrpHdr struct {
+	MsgID    string    `xml:"MsgId"`
+	CreDtTm  string    `xml:"CreDtTm"`
+	NbOfTxs  string    `xml:"NbOfTxs"`
+	CtrlSum  string    `xml:"CtrlSum"`
+	InitgPty painParty `xml:"InitgPty"`
+}
+
+type painPmtInf struct {
+	PmtInfID    string         `xml:"PmtInfId"`
+	PmtMtd      string         `xml:"PmtMtd"`
+	BtchBookg   bool           `xml:"BtchBookg"`
+	NbOfTxs     string         `xml:"NbOfTxs"`
+	CtrlSum     string         `xml:"CtrlSum"`
+	PmtTpInf    *painPmtTpInf  `xml:"PmtTpInf,omitempty"`
+	ReqdExctnDt painDateChoice `xml:"ReqdExctnDt"`
+	Dbtr        painParty      `xml:"Dbtr"`
+	DbtrAcct    painAccount    `xml:"DbtrAcct"`
+	DbtrAgt     painAgent      `xml:"DbtrAgt"`
+	CdtTrfTxInf []painTx       `xml:"CdtTrfTxInf"`
+}
+
+type painPmtTpInf struct {
+	CtgyPurp *painCode `xml:"CtgyPurp,omitempty"`
+}
+
+type painDateChoice struct {
+	Dt string `xml:"Dt"`
+}
+
+type painParty struct {
+	Nm string       `xml:"Nm,omitempty"`
+	ID *painPartyID `xml:"Id,omitempty"`
+}
+
+type painPartyID struct {
+	OrgID *painOrgID `xml:"OrgId,omitempty"`
+}
+
+type painOrgID struct {
+	Othr *painOther `xml:"Othr,omitempty"`
+}
+
+type painOther struct {
+	ID string `xml:"Id"`
+}
+
+type painAccount struct {
+	ID   *painAccountID `xml:"Id,omitempty"`
+	Ccy  string         `xml:"Ccy,omitempty"`
+	Prxy *painProxy     `xml:"Prxy,omitempty"`
+}
+
+type painProxy struct {
+	Tp *painCode `xml:"Tp,omitempty"`
+	ID string    `xml:"Id"`
+}
+
+type painAccountID struct {
+	Othr *painOther `xml:"Othr,omitempty"`
+}
+
+type painAgent struct {
+	FinInstnID painFinInstnID `xml:"FinInstnId"`
+}
+
+type painFinInstnID struct {
+	ClrSysMmbID *painClrSysMmbID `xml:"ClrSysMmbId,omitempty"`
+}
+
+type painClrSysMmbID struct {
+	MmbID string `xml:"MmbId"`
+}
+
+type painTx struct {
+	PmtID    painPmtID   `xml:"PmtId"`
+	Amt      painAmt     `xml:"Amt"`
+	CdtrAgt  *painAgent  `xml:"CdtrAgt,omitempty"`
+	Cdtr     painParty   `xml:"Cdtr"`
+	CdtrAcct painAccount `xml:"CdtrAcct"`
+	Purp     *painCode   `xml:"Purp,omitempty"`
+	RmtInf   *painRmtInf `xml:"RmtInf,omitempty"`
+}
+
+type painPmtID struct {
+	InstrID    string `xml:"InstrId,omitempty"`
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type painAmt struct {
+	InstdAmt painAmount `xml:"InstdAmt"`
+}
+
+type painAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type painCode struct {
+	Cd string `xml:"Cd"`
+}
+
+type painRmtInf struct {
+	Ustrd string `xml:"Ustrd"`
+}
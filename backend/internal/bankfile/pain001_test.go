@@ -0,0 +1,115 @@
+package bankfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backend/internal/money"
+)
+
+// ไฟล์ของ run ที่อนุมัติแล้วต้องผ่าน schema pain.001.001.09 และยอดควบคุม (NbOfTxs/CtrlSum) ตรงกับรายการทั้งใน GrpHdr และ PmtInf
+func TestPain001Schema(t *testing.T) {
+	b := sampleBatch("004")
+	b.Credits = append(b.Credits, Credit{Ref: "E005", Name: "Somsak", Account: "555-1-23456-7", Amount: money.FromBaht(0.01)}) // ธนาคารเดียวกับบริษัท
+	var buf bytes.Buffer
+	if err := (Pain001{}).Write(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		GrpHdr struct {
+			NbOfTxs string `xml:"NbOfTxs"`
+			CtrlSum string `xml:"CtrlSum"`
+		} `xml:"CstmrCdtTrfInitn>GrpHdr"`
+		PmtInf []struct {
+			NbOfTxs string   `xml:"NbOfTxs"`
+			CtrlSum string   `xml:"CtrlSum"`
+			Amounts []string `xml:"CdtTrfTxInf>Amt>InstdAmt"`
+			Agents  []string `xml:"CdtTrfTxInf>CdtrAgt>FinInstnId>ClrSysMmbId>MmbId"`
+		} `xml:"CstmrCdtTrfInitn>PmtInf"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.GrpHdr.NbOfTxs != "3" || doc.GrpHdr.CtrlSum != "37776.18" {
+		t.Errorf("GrpHdr NbOfTxs/CtrlSum = %s/%s, want 3/37776.18", doc.GrpHdr.NbOfTxs, doc.GrpHdr.CtrlSum)
+	}
+	if len(doc.PmtInf) != 1 {
+		t.Fatalf("%d PmtInf, want 1", len(doc.PmtInf))
+	}
+	pmt := doc.PmtInf[0]
+	if pmt.NbOfTxs != doc.GrpHdr.NbOfTxs || pmt.CtrlSum != doc.GrpHdr.CtrlSum {
+		t.Errorf("PmtInf NbOfTxs/CtrlSum = %s/%s, want %s/%s", pmt.NbOfTxs, pmt.CtrlSum, doc.GrpHdr.NbOfTxs, doc.GrpHdr.CtrlSum)
+	}
+	if want := []string{"25430.50", "12345.67", "0.01"}; len(pmt.Amounts) != len(want) || pmt.Amounts[0] != want[0] || pmt.Amounts[1] != want[1] || pmt.Amounts[2] != want[2] {
+		t.Errorf("InstdAmt = %v, want %v", pmt.Amounts, want)
+	}
+	if want := []string{"004", "014", "004"}; len(pmt.Agents) != 3 || pmt.Agents[1] != want[1] || pmt.Agents[2] != want[2] {
+		t.Errorf("creditor agents = %v, want %v", pmt.Agents, want)
+	}
+
+	validateSchema(t, buf.Bytes())
+}
+
+// validateSchema ตรวจไฟล์กับ testdata/pain.001.001.09.xsd ด้วย xmllint
+func validateSchema(t *testing.T, doc []byte) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Fatal("xmllint (libxml2) is required to validate pain.001 output against the schema")
+	}
+	path := filepath.Join(t.TempDir(), "pain001.xml")
+	if err := os.WriteFile(path, doc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", "pain.001.001.09.xsd"), path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("schema validation failed: %v\n%s\n%s", err, out, doc)
+	}
+}
+
+// รหัสอ้างอิงที่ยาวเกิน Max35Text ต้องถูกปฏิเสธ ไม่ถูกตัดให้สั้นลงเงียบๆ
+func TestPain001RejectsLongIDs(t *testing.T) {
+	long := strings.Repeat("X", 36)
+	cases := []struct {
+		name  string
+		edit  func(b *Batch)
+		ref   string
+		field string
+	}{
+		{"end-to-end id", func(b *Batch) { b.Credits[0].Ref = strings.Repeat("E", 30) }, strings.Repeat("E", 30), "endToEndId"},
+		{"instruction id", func(b *Batch) { b.Credits[1].Ref = long }, long, "instrId"},
+		{"message id", func(b *Batch) { b.Reference = strings.Repeat("R", 21) }, "", "msgId"},
+		{"company id", func(b *Batch) { b.Company.ID = long }, "", "companyId"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := sampleBatch("004")
+			tc.edit(&b)
+			err := (Pain001{}).Write(&bytes.Buffer{}, b)
+			var be *BatchError
+			if !errors.As(err, &be) {
+				t.Fatalf("err = %v, want BatchError", err)
+			}
+			for _, p := range be.Problems {
+				if p.Ref == tc.ref && p.Field == tc.field {
+					return
+				}
+			}
+			t.Errorf("problems = %+v, want %s/%s", be.Problems, tc.ref, tc.field)
+		})
+	}
+
+	// ยาวพอดี 35 ตัวอักษรยังเขียนได้
+	b := sampleBatch("004")
+	b.Credits[0].Ref = strings.Repeat("E", 35-len(b.Reference)-1)
+	if err := (Pain001{}).Write(&bytes.Buffer{}, b); err != nil {
+		t.Errorf("35-character EndToEndId: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package bankfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"backend/internal/models"
+)
+
+var ErrInvalidPromptPay = errors.New("invalid PromptPay ID")
+
+// ValidatePromptPay ตรวจหมายเลขพร้อมเพย์ตามประเภท คืนค่าที่ตัดขีด/ช่องว่างออกแล้ว
+// mobile: เบอร์มือถือ 10 หลักขึ้นต้น 06/08/09 (รับ +66/66 นำหน้าได้), national_id: เลขประจำตัวประชาชน 13 หลักพร้อมหลักตรวจสอบ
+func ValidatePromptPay(typ, id string) (string, error) {
+	n := NormalizeAccount(strings.TrimPrefix(strings.TrimSpace(id), "+"))
+	switch typ {
+	case models.PromptPayMobile:
+		if len(n) == 11 && strings.HasPrefix(n, "66") {
+			n = "0" + n[2:]
+		}
+		if len(n) != 10 || !(strings.HasPrefix(n, "06") || strings.HasPrefix(n, "08") || strings.HasPrefix(n, "09")) {
+			return "", fmt.Errorf("%w: mobile number must be 10 digits starting with 06, 08 or 09", ErrInvalidPromptPay)
+		}
+		return n, nil
+	case models.PromptPayNationalID:
+		if !models.ValidNationalID(n) {
+			return "", fmt.Errorf("%w: national ID must be 13 digits with a valid check digit", ErrInvalidPromptPay)
+		}
+		return n, nil
+	}
+	return "", fmt.Errorf("%w: type must be one of %s", ErrInvalidPromptPay, strings.Join(models.PromptPayTypes, ", "))
+}
+
+// proxy บัญชีผู้รับแบบ proxy ของ pain.001.001.09 (CdtrAcct/Prxy): ประเภทตาม ISO 20022 ExternalProxyAccountType1Code
+// (MBNO = เบอร์มือถือ, NIDN = เลขประจำตัวประชาชน) และหมายเลขในรูปแบบ AnyID ของ PromptPay: มือถือเป็น 0066 + 9 หลัก, บัตรประชาชน 13 หลัก
+func proxy(c Credit) (*painProxy, error) {
+	id, err := ValidatePromptPay(c.ProxyType, c.ProxyID)
+	if err != nil {
+		return nil, err
+	}
+	if c.ProxyType == models.PromptPayMobile {
+		return &painProxy{Tp: &painCode{Cd: "MBNO"}, ID: "0066" + id[1:]}, nil
+	}
+	return &painProxy{Tp: &painCode{Cd: "NIDN"}, ID: id}, nil
+}
+
+// PromptPay ไฟล์โอนเงินของพนักงานที่รับผ่านพร้อมเพย์ ใช้ pain.001.001.09 เดียวกับ Pain001
+// โดยบัญชีผู้รับเป็น CdtrAcct/Prxy แทนเลขบัญชี (ไม่ระบุ CdtrAgt: ระบบ PromptPay หาธนาคารปลายทางจากหมายเลข)
+// ไม่มี layout fixed-width สำหรับพร้อมเพย์: ใช้ได้เมื่อมีเอกสาร spec ของธนาคารพร้อมไฟล์ตัวอย่าง
+type PromptPay struct{}
+
+func (PromptPay) Bank() string      { return "" }
+func (PromptPay) Format() string    { return "ISO 20022 pain.001.001.09 (PromptPay)" }
+func (PromptPay) Extension() string { return "xml" }
+
+func (PromptPay) Write(w io.Writer, b Batch) error {
+	var probs []Problem
+	for _, c := range b.Credits {
+		if c.ProxyType == "" {
+			probs = append(probs, Problem{c.Ref, "promptPayId", "credit has no PromptPay ID"})
+		}
+	}
+	if len(probs) > 0 {
+		return &BatchError{Problems: probs}
+	}
+	return Pain001{}.Write(w, b)
+}
@@ -9,11 +9,23 @@ import (
 // Migrate applies the database schema required by the application.
 func Migrate(conn *gorm.DB) error {
 	return conn.AutoMigrate(
+		&models.PayGroup{},
 		&models.Employee{},
 		&models.Employment{},
 		&models.PayrollRun{},
+		&models.RunTransition{},
+		&models.RunInput{},
 		&models.PayrollItem{},
+		&models.PayrollLine{},
 		&models.Payslip{},
 		&models.Leave{},
+		&models.LeavePolicy{},
+		&models.StatutoryRate{},
+		&models.PayComponent{},
+		&models.SalaryRecord{},
+		&models.Loan{},
+		&models.LoanRepayment{},
+		&models.Garnishment{},
+		&models.GarnishmentRemittance{},
 	)
 }
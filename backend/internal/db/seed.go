@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"backend/internal/models"
+	"backend/internal/money"
 
 	"gorm.io/gorm"
 )
@@ -28,11 +29,13 @@ func Seed(conn *gorm.DB) error {
 			LastName:        "สุขใจ",
 			Department:      "ฝ่ายบุคคล",
 			Position:        "HR Manager",
-			BaseSalary:      50000,
-			BankAccount:     "123-456-7890",
+			BaseSalary:      money.FromBaht(50000),
+			BankAccount:     "1234567890",
+			BankCode:        "004",
 			PVDRate:         0.03, // ตาม default ก็ได้
 			WithholdingRate: 0.00,
 			SSOEnabled:      true,
+			NationalID:      "1101700123456",
 			Status:          "active",
 			HiredAt:         now.AddDate(-2, 0, 0),
 		},
@@ -42,11 +45,13 @@ func Seed(conn *gorm.DB) error {
 			LastName:        "ดีงาม",
 			Department:      "ฝ่ายบัญชี",
 			Position:        "Accountant",
-			BaseSalary:      40000,
-			BankAccount:     "987-654-3210",
+			BaseSalary:      money.FromBaht(40000),
+			BankAccount:     "9876543210",
+			BankCode:        "014",
 			PVDRate:         0.03,
 			WithholdingRate: 0.00,
 			SSOEnabled:      true,
+			NationalID:      "3100600234561",
 			Status:          "active",
 			HiredAt:         now.AddDate(-1, -3, 0),
 		},
@@ -56,11 +61,13 @@ func Seed(conn *gorm.DB) error {
 			LastName:        "มีชัย",
 			Department:      "ฝ่ายไอที",
 			Position:        "Developer",
-			BaseSalary:      60000,
-			BankAccount:     "111-222-3333",
+			BaseSalary:      money.FromBaht(60000),
+			BankAccount:     "1112223333",
+			BankCode:        "002",
 			PVDRate:         0.03,
 			WithholdingRate: 0.00,
 			SSOEnabled:      true,
+			NationalID:      "1509900345677",
 			Status:          "active",
 			HiredAt:         now.AddDate(-1, 0, 0),
 		},
@@ -70,11 +77,13 @@ func Seed(conn *gorm.DB) error {
 			LastName:        "ใจดี",
 			Department:      "ฝ่ายขาย",
 			Position:        "Sales Executive",
-			BaseSalary:      45000,
-			BankAccount:     "222-333-4444",
+			BaseSalary:      money.FromBaht(45000),
+			BankAccount:     "2223334444",
+			BankCode:        "006",
 			PVDRate:         0.03,
 			WithholdingRate: 0.00,
 			SSOEnabled:      true,
+			NationalID:      "1200800456781",
 			Status:          "active",
 			HiredAt:         now.AddDate(-2, -6, 0),
 		},
@@ -84,11 +93,13 @@ func Seed(conn *gorm.DB) error {
 			LastName:        "รุ่งเรือง",
 			Department:      "ฝ่ายการเงิน",
 			Position:        "Finance Officer",
-			BaseSalary:      48000,
-			BankAccount:     "555-666-7777",
+			BaseSalary:      money.FromBaht(48000),
+			BankAccount:     "5556667777",
+			BankCode:        "025",
 			PVDRate:         0.03,
 			WithholdingRate: 0.00,
 			SSOEnabled:      true,
+			NationalID:      "3401200567898",
 			Status:          "active",
 			HiredAt:         now.AddDate(-3, 0, 0),
 		},
@@ -0,0 +1,116 @@
+// Package fixedwidth สร้างไฟล์ข้อความแบบ fixed-width (TIS-620, CRLF) สำหรับระบบของธนาคารและหน่วยงานรัฐ
+package fixedwidth
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/internal/money"
+)
+
+// Record สร้างบรรทัดแบบ fixed-width: field ตัวอักษรชิดซ้ายเติมช่องว่าง, field ตัวเลขชิดขวาเติม 0
+// ความกว้างนับเป็นไบต์หลังแปลงเป็น TIS-620 (อักษรไทยหนึ่งตัว = หนึ่งไบต์ รวมสระบน/ล่างและวรรณยุกต์)
+type Record struct {
+	b      strings.Builder
+	length int
+	err    error
+}
+
+// New record ความยาว length ตัวอักษร
+func New(length int) *Record { return &Record{length: length} }
+
+// Alpha field ตัวอักษร (ตัดส่วนที่เกินความกว้าง)
+func (r *Record) Alpha(s string, width int) *Record {
+	rs := []rune(strings.TrimSpace(s))
+	if len(rs) > width {
+		rs = rs[:width]
+	}
+	r.b.WriteString(string(rs))
+	r.b.WriteString(strings.Repeat(" ", width-len(rs)))
+	return r
+}
+
+// Num field ตัวเลข (ค่าติดลบหรือยาวเกินความกว้างเป็นข้อผิดพลาดของ record)
+func (r *Record) Num(n int64, width int) *Record {
+	s := fmt.Sprintf("%0*d", width, n)
+	if n < 0 || len(s) > width {
+		r.fail(fmt.Errorf("fixedwidth: %d does not fit %d digits", n, width))
+		s = strings.Repeat("9", width)
+	}
+	r.b.WriteString(s)
+	return r
+}
+
+// Amount ยอดเงินเป็นสตางค์ไม่มีจุดทศนิยม (ทศนิยม 2 ตำแหน่งโดยนัย)
+func (r *Record) Amount(a money.Amount, width int) *Record {
+	return r.Num(a.Satang(), width)
+}
+
+// Date วันที่ตาม layout ของ Go เช่น 02012006 (DDMMYYYY)
+func (r *Record) Date(t time.Time, layout string) *Record {
+	r.b.WriteString(t.Format(layout))
+	return r
+}
+
+// Filler เติมช่องว่างจนครบความยาวของ record
+func (r *Record) Filler() *Record {
+	if n := r.length - len([]rune(r.b.String())); n > 0 {
+		r.b.WriteString(strings.Repeat(" ", n))
+	}
+	return r
+}
+
+func (r *Record) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// File บรรทัดของไฟล์ fixed-width เก็บข้อผิดพลาดแรกที่พบ
+type File struct {
+	lines []string
+	err   error
+}
+
+// Add เพิ่ม record ที่สมบูรณ์ (ตรวจความยาวให้ตรงกับ layout)
+func (f *File) Add(r *Record) {
+	s := r.b.String()
+	if n := len([]rune(s)); n != r.length && r.err == nil {
+		r.err = fmt.Errorf("fixedwidth: record is %d characters, layout expects %d", n, r.length)
+	}
+	if r.err != nil && f.err == nil {
+		f.err = r.err
+	}
+	f.lines = append(f.lines, s)
+}
+
+// Write เขียนไฟล์เป็น TIS-620 ขึ้นบรรทัดด้วย CRLF ตามที่ระบบของธนาคารและ สปส. รับ
+func (f *File) Write(w io.Writer) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, l := range f.lines {
+		if _, err := w.Write(append(TIS620(l), '\r', '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TIS620 แปลง UTF-8 เป็น TIS-620 (อักขระนอก ASCII และนอกช่วงอักษรไทยเป็น '?')
+func TIS620(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		case (r >= 0x0E01 && r <= 0x0E3A) || (r >= 0x0E3F && r <= 0x0E5B):
+			out = append(out, byte(r-0x0E00+0xA0))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ComponentHandler จัดการเงินได้/เงินหักประจำของพนักงาน
+type ComponentHandler struct {
+	Store storage.Port
+}
+
+func NewComponentHandler(store storage.Port) *ComponentHandler {
+	return &ComponentHandler{Store: store}
+}
+
+type componentRequest struct {
+	Code      string       `json:"code" binding:"required"`
+	Name      string       `json:"name" binding:"required"`
+	Kind      string       `json:"kind" binding:"required"`
+	Amount    money.Amount `json:"amount"`
+	StartDate string       `json:"startDate" binding:"required"`
+	EndDate   *string      `json:"endDate"`
+	Taxable   *bool        `json:"taxable"`
+	SSOable   *bool        `json:"ssoAble"`
+}
+
+// GET /api/v1/employees/:id/components
+func (h *ComponentHandler) List(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetEmployee(uint(empID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	out, err := h.Store.ListPayComponents(uint(empID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/employees/:id/components
+func (h *ComponentHandler) Create(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetEmployee(uint(empID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+
+	var req componentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+
+	pc := &models.PayComponent{EmployeeID: uint(empID), Taxable: true}
+	if msg := applyComponentRequest(pc, req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.Store.CreatePayComponent(pc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create component"})
+		return
+	}
+	c.JSON(http.StatusCreated, pc)
+}
+
+// PUT /api/v1/employees/:id/components/:componentId
+// ใช้ปรับยอดหรือใส่ endDate เพื่อหยุดรายการ
+func (h *ComponentHandler) Update(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	compID, _ := strconv.Atoi(c.Param("componentId"))
+
+	pc, err := h.Store.GetPayComponent(uint(compID))
+	if err != nil || pc.EmployeeID != uint(empID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "component not found"})
+		return
+	}
+
+	var req componentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if msg := applyComponentRequest(pc, req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.Store.UpdatePayComponent(pc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	c.JSON(http.StatusOK, pc)
+}
+
+// applyComponentRequest ตรวจสอบและคัดลอกค่าจาก request คืนข้อความ error ถ้าไม่ผ่าน
+func applyComponentRequest(pc *models.PayComponent, req componentRequest) string {
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if kind != models.ComponentEarning && kind != models.ComponentDeduction {
+		return "kind must be 'earning' or 'deduction'"
+	}
+	if req.Amount < 0 {
+		return "amount must be >= 0"
+	}
+	start, err := parseDate(req.StartDate)
+	if err != nil {
+		return "invalid startDate; use YYYY-MM-DD or RFC3339"
+	}
+	end, err := parseOptionalDate(req.EndDate)
+	if err != nil {
+		return "invalid endDate; use YYYY-MM-DD or RFC3339"
+	}
+	if end != nil && end.Before(start) {
+		return "endDate must be on or after startDate"
+	}
+
+	pc.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	pc.Name = strings.TrimSpace(req.Name)
+	pc.Kind = kind
+	pc.Amount = req.Amount
+	pc.StartDate = start
+	pc.EndDate = end
+	if req.Taxable != nil {
+		pc.Taxable = *req.Taxable
+	}
+	if req.SSOable != nil {
+		pc.SSOable = *req.SSOable
+	}
+	return ""
+}
@@ -6,7 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/bankfile"
 	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/ssofile"
 	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -74,18 +77,27 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 // POST /employees
 func (h *EmployeeHandler) Create(c *gin.Context) {
 	var req struct {
-		EmpCode         string   `json:"empCode" binding:"required"`
-		FirstName       string   `json:"firstName" binding:"required"`
-		LastName        string   `json:"lastName" binding:"required"`
-		Department      string   `json:"department"`
-		Position        string   `json:"position"`
-		BaseSalary      float64  `json:"baseSalary" binding:"required"`
-		BankAccount     string   `json:"bankAccount"`
-		PVDRate         *float64 `json:"pvdRate"`
-		WithholdingRate *float64 `json:"withholdingRate"`
-		SSOEnabled      *bool    `json:"ssoEnabled"`
-		Status          *string  `json:"status"`
-		HiredAt         *string  `json:"hiredAt"`
+		EmpCode         string       `json:"empCode" binding:"required"`
+		FirstName       string       `json:"firstName" binding:"required"`
+		LastName        string       `json:"lastName" binding:"required"`
+		Department      string       `json:"department"`
+		Position        string       `json:"position"`
+		BaseSalary      money.Amount `json:"baseSalary" binding:"required"`
+		BankAccount     string       `json:"bankAccount"`
+		BankCode        string       `json:"bankCode"`
+		BankBranch      string       `json:"bankBranch"`
+		BankAccountName string       `json:"bankAccountName"`
+		PaymentMethod   string       `json:"paymentMethod"`
+		PromptPayType   string       `json:"promptPayType"`
+		PromptPayID     string       `json:"promptPayId"`
+		PVDRate         *float64     `json:"pvdRate"`
+		WithholdingRate *float64     `json:"withholdingRate"`
+		SSOEnabled      *bool        `json:"ssoEnabled"`
+		NationalID      string       `json:"nationalId"`
+		SSOBranch       string       `json:"ssoBranch"`
+		Status          *string      `json:"status"`
+		HiredAt         *string      `json:"hiredAt"`
+		PayGroupID      *uint        `json:"payGroupId"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -113,10 +125,43 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "withholdingRate must be between 0 and 1"})
 		return
 	}
+	bank, msg := bankDetails(req.BankCode, req.BankBranch, req.BankAccount, req.BankAccountName)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	method := strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+	if method == "" {
+		method = models.PaymentBank
+	}
+	var ppType, ppID string
+	switch method {
+	case models.PaymentBank:
+		if req.PromptPayType != "" || req.PromptPayID != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "promptPayType/promptPayId require paymentMethod 'promptpay'"})
+			return
+		}
+	case models.PaymentPromptPay:
+		ppType = strings.ToLower(strings.TrimSpace(req.PromptPayType))
+		id, err := bankfile.ValidatePromptPay(ppType, req.PromptPayID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ppID = id
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "paymentMethod must be one of " + strings.Join(models.PaymentMethods, ", ")})
+		return
+	}
 	sso := true
 	if req.SSOEnabled != nil {
 		sso = *req.SSOEnabled
 	}
+	nationalID, ssoBranch, msg := ssoDetails(req.NationalID, req.SSOBranch)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
 
 	status := "active"
 	if req.Status != nil && *req.Status != "" {
@@ -127,6 +172,13 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		status = *req.Status
 	}
 
+	if req.PayGroupID != nil {
+		if _, err := h.Store.GetPayGroup(*req.PayGroupID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pay group not found"})
+			return
+		}
+	}
+
 	var hiredAt time.Time
 	if req.HiredAt != nil && *req.HiredAt != "" {
 		if t, err := time.Parse("2006-01-02", *req.HiredAt); err == nil {
@@ -148,17 +200,124 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		Department:      strings.TrimSpace(req.Department),
 		Position:        strings.TrimSpace(req.Position),
 		BaseSalary:      req.BaseSalary,
-		BankAccount:     strings.TrimSpace(req.BankAccount),
+		BankAccount:     bank.account,
+		BankCode:        bank.code,
+		BankBranch:      bank.branch,
+		BankAccountName: bank.name,
+		PaymentMethod:   method,
+		PromptPayType:   ppType,
+		PromptPayID:     ppID,
 		PVDRate:         pvd,
 		WithholdingRate: wh,
 		SSOEnabled:      sso,
+		NationalID:      nationalID,
+		SSOBranch:       ssoBranch,
 		Status:          status,
 		HiredAt:         hiredAt,
+		PayGroupID:      req.PayGroupID,
 	}
 
 	if err := h.Store.CreateEmployee(emp); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create employee"})
 		return
 	}
+	// เงินเดือนแรกเข้าเป็น record แรกของประวัติเงินเดือน
+	hire := &models.SalaryRecord{
+		EmployeeID:    emp.ID,
+		EffectiveFrom: dateOnly(emp.HiredAt),
+		BaseSalary:    emp.BaseSalary,
+		Reason:        models.SalaryHire,
+		CreatedBy:     c.GetString("email"),
+	}
+	if err := h.Store.CreateSalaryRecord(hire); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save salary history"})
+		return
+	}
 	c.JSON(http.StatusCreated, emp)
 }
+
+type bankInfo struct{ code, branch, account, name string }
+
+// bankDetails ตรวจข้อมูลบัญชีรับเงินเดือน (ไม่ระบุบัญชี = ยังไม่มีบัญชี)
+// รหัสธนาคารว่างใช้ธนาคารของบริษัท; เลขบัญชีต้องถูกต้องตามจำนวนหลักของธนาคาร
+func bankDetails(code, branch, account, name string) (bankInfo, string) {
+	out := bankInfo{
+		code:    strings.TrimSpace(code),
+		branch:  strings.TrimSpace(branch),
+		account: strings.TrimSpace(account),
+		name:    strings.TrimSpace(name),
+	}
+	if out.account == "" {
+		if out.code != "" || out.branch != "" || out.name != "" {
+			return out, "bankAccount is required with bank details"
+		}
+		return out, ""
+	}
+	if out.code == "" {
+		out.code = bankfile.CurrentCompany().BankCode
+	}
+	if out.code == "" {
+		return out, "bankCode is required"
+	}
+	acct, err := bankfile.ValidateAccount(out.code, out.account)
+	if err != nil {
+		return out, err.Error()
+	}
+	out.account = acct
+	if out.branch != "" && (len(out.branch) != 4 || bankfile.NormalizeAccount(out.branch) != out.branch) {
+		return out, "bankBranch must be a 4-digit branch code"
+	}
+	if len([]rune(out.name)) > 100 {
+		return out, "bankAccountName must be at most 100 characters"
+	}
+	return out, ""
+}
+
+// PUT /api/v1/employees/:id/sso
+// แก้เลขประจำตัวประชาชนและลำดับที่สาขาที่ใช้ยื่น สปส.1-10
+func (h *EmployeeHandler) UpdateSSO(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		NationalID string `json:"nationalId" binding:"required"`
+		SSOBranch  string `json:"ssoBranch"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	nationalID, branch, msg := ssoDetails(req.NationalID, req.SSOBranch)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	emp, err := h.Store.GetEmployee(uint(id))
+	if err != nil || emp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	emp.NationalID, emp.SSOBranch = nationalID, branch
+	if err := h.Store.UpdateEmployee(emp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update employee"})
+		return
+	}
+	c.JSON(http.StatusOK, emp)
+}
+
+// ssoDetails ตรวจเลขประจำตัวประชาชน (ตัดขีด/ช่องว่างออก) และลำดับที่สาขา 6 หลัก (ว่าง = สาขาตั้งต้นของนายจ้าง)
+func ssoDetails(nationalID, branch string) (string, string, string) {
+	id := strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(nationalID))
+	if id != "" && !models.ValidNationalID(id) {
+		return "", "", "nationalId must be 13 digits with a valid check digit"
+	}
+	branch = strings.TrimSpace(branch)
+	if branch != "" && !ssofile.ValidBranch(branch) {
+		return "", "", "ssoBranch must be a 6-digit branch number"
+	}
+	return id, branch, ""
+}
+
+// GET /api/v1/banks
+// รายชื่อธนาคารและจำนวนหลักของเลขบัญชี
+func (h *EmployeeHandler) Banks(c *gin.Context) {
+	c.JSON(http.StatusOK, bankfile.ThaiBanks)
+}
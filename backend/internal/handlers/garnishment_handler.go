@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GarnishmentHandler จัดการคำสั่งอายัดเงินเดือนของพนักงาน
+type GarnishmentHandler struct {
+	Store storage.Port
+}
+
+func NewGarnishmentHandler(store storage.Port) *GarnishmentHandler {
+	return &GarnishmentHandler{Store: store}
+}
+
+// GET /api/v1/employees/:id/garnishments
+func (h *GarnishmentHandler) List(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetEmployee(uint(empID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	out, err := h.Store.ListGarnishments(uint(empID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/employees/:id/garnishments
+// body: {"orderNo":"...","creditor":"...","creditorAccount":"...","priority":1,"rate":0.3,"cap":10000,"total":150000,"startDate":"2026-02-01"}
+// ระบุ rate (สัดส่วนของเงินได้หลังหักภาษี/SSO/PVD) หรือ amount (ยอดคงที่ต่องวด) อย่างใดอย่างหนึ่ง
+// protected = เงินเดือนขั้นต่ำต่อเดือนที่พนักงานต้องได้รับ (ไม่ระบุ = 20,000 บาท)
+func (h *GarnishmentHandler) Create(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	emp, err := h.Store.GetEmployee(uint(empID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+
+	var req struct {
+		OrderNo         string        `json:"orderNo" binding:"required"`
+		Creditor        string        `json:"creditor" binding:"required"`
+		CreditorAccount string        `json:"creditorAccount"`
+		Priority        int           `json:"priority"`
+		Rate            float64       `json:"rate"`
+		Amount          money.Amount  `json:"amount"`
+		Cap             money.Amount  `json:"cap"`
+		Protected       *money.Amount `json:"protected"`
+		Total           money.Amount  `json:"total"`
+		StartDate       string        `json:"startDate" binding:"required"`
+		Note            string        `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if (req.Rate > 0) == (req.Amount > 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of rate or amount is required"})
+		return
+	}
+	if req.Rate < 0 || req.Rate > 1 || req.Amount < 0 || req.Cap < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rate must be between 0 and 1; amount and cap must be >= 0"})
+		return
+	}
+	if req.Total <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "total must be > 0"})
+		return
+	}
+	protected := payroll.DefaultGarnishmentProtected
+	if req.Protected != nil {
+		if *req.Protected < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "protected must be >= 0"})
+			return
+		}
+		protected = *req.Protected
+	}
+	start, err := parseDate(req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate; use YYYY-MM-DD"})
+		return
+	}
+
+	g := &models.Garnishment{
+		EmployeeID:      emp.ID,
+		OrderNo:         strings.TrimSpace(req.OrderNo),
+		Creditor:        strings.TrimSpace(req.Creditor),
+		CreditorAccount: strings.TrimSpace(req.CreditorAccount),
+		Priority:        req.Priority,
+		Rate:            req.Rate,
+		Amount:          req.Amount,
+		Cap:             req.Cap,
+		Protected:       protected,
+		Total:           req.Total,
+		Outstanding:     req.Total,
+		StartDate:       dateOnly(start),
+		Status:          models.GarnishmentActive,
+		Note:            strings.TrimSpace(req.Note),
+		CreatedBy:       c.GetString("email"),
+	}
+	if err := h.Store.CreateGarnishment(g); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save garnishment"})
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// GET /api/v1/garnishments/:id
+// คำสั่งอายัดพร้อมยอดนำส่งของแต่ละ run
+func (h *GarnishmentHandler) Get(c *gin.Context) {
+	g, ok := h.load(c)
+	if !ok {
+		return
+	}
+	rems, err := h.Store.ListGarnishmentRemittances(g.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	var remitted money.Amount
+	for _, r := range rems {
+		remitted += r.Amount
+	}
+	c.JSON(http.StatusOK, gin.H{"garnishment": g, "remittances": rems, "remitted": remitted})
+}
+
+// POST /api/v1/garnishments/:id/status
+// body: {"status":"suspended"} (active, suspended, released; satisfied ตั้งโดยระบบเมื่อหักครบยอดหนี้)
+func (h *GarnishmentHandler) SetStatus(c *gin.Context) {
+	g, ok := h.load(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	to := strings.ToLower(strings.TrimSpace(req.Status))
+	if !models.ValidGarnishmentStatus(to) || to == models.GarnishmentSatisfied {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of active, suspended, released"})
+		return
+	}
+	if g.Status == models.GarnishmentSatisfied || g.Status == models.GarnishmentReleased {
+		c.JSON(http.StatusConflict, gin.H{"error": "garnishment is " + g.Status})
+		return
+	}
+	g.Status = to
+	if err := h.Store.UpdateGarnishment(g); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update garnishment"})
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+func (h *GarnishmentHandler) load(c *gin.Context) (*models.Garnishment, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	g, err := h.Store.GetGarnishment(uint(id))
+	if err != nil || g == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "garnishment not found"})
+		return nil, false
+	}
+	return g, true
+}
@@ -1,14 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"backend/internal/leave"
 	"backend/internal/models"
 	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
+// errLeaveOverlap ใบลาที่กำลังอนุมัติซ้อนกับใบลาที่อนุมัติแล้ว
+var errLeaveOverlap = errors.New("leave overlaps an approved leave")
+
 type LeaveHandler struct {
 	Store storage.Port
 }
@@ -17,26 +25,432 @@ func NewLeaveHandler(store storage.Port) *LeaveHandler {
 	return &LeaveHandler{Store: store}
 }
 
-// GET /api/v1/leave
+// GET /api/v1/leave?employeeId=&status=&from=&to=
+// from/to กรองใบลาที่มีช่วงวันซ้อนกับช่วงที่ระบุ
 func (h *LeaveHandler) List(c *gin.Context) {
-	out, err := h.Store.ListLeaves()
+	empID, _ := strconv.Atoi(c.Query("employeeId"))
+	status := c.Query("status")
+	if status != "" && !validLeaveStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of submitted, approved, rejected, cancelled"})
+		return
+	}
+	from, err := parseOptionalDate(strPtr(c.Query("from")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+		return
+	}
+	to, err := parseOptionalDate(strPtr(c.Query("to")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+		return
+	}
+
+	all, err := h.Store.ListLeaves()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
 		return
 	}
+	out := make([]models.Leave, 0, len(all))
+	for _, lv := range all {
+		if empID > 0 && lv.EmployeeID != uint(empID) {
+			continue
+		}
+		if status != "" && lv.Status != status {
+			continue
+		}
+		if from != nil && lv.EndDate.Before(*from) {
+			continue
+		}
+		if to != nil && lv.StartDate.After(*to) {
+			continue
+		}
+		out = append(out, lv)
+	}
 	c.JSON(http.StatusOK, out)
 }
 
+// GET /api/v1/leave/:id
+func (h *LeaveHandler) Get(c *gin.Context) {
+	lv, ok := h.loadLeave(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, lv)
+}
+
 // POST /api/v1/leave
+// บันทึกคำขอลาในสถานะ submitted หลังตรวจพนักงาน ช่วงวันที่ และวันลาซ้อน
+// นโยบาย reject ที่ลาเกินสิทธิคงเหลือจะถูกปฏิเสธตั้งแต่ตอนยื่น
 func (h *LeaveHandler) Create(c *gin.Context) {
 	var lv models.Leave
 	if err := c.ShouldBindJSON(&lv); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
+	if lv.Type == "" {
+		lv.Type = models.LeavePersonal
+	}
+	// ลาไม่รับค่าจ้างมาจากประเภทการลา หรือส่วนที่เกินสิทธิซึ่งแยกออกตอนอนุมัติเท่านั้น
+	lv.Unpaid = lv.Type == models.LeaveUnpaid
+	if !models.ValidLeaveType(lv.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of sick, personal, annual, unpaid, maternity"})
+		return
+	}
+	if lv.StartDate.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "startDate is required"})
+		return
+	}
+	if lv.EndDate.IsZero() {
+		lv.EndDate = lv.StartDate
+	}
+	if lv.EndDate.Before(lv.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endDate must be on or after startDate"})
+		return
+	}
+	if leave.WorkingDays(lv.StartDate, lv.EndDate) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leave covers no working days"})
+		return
+	}
+
+	emp, err := h.Store.GetEmployee(lv.EmployeeID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee not found"})
+		return
+	}
+	if lv.StartDate.Before(emp.HiredAt) || (emp.TerminatedAt != nil && lv.EndDate.After(*emp.TerminatedAt)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leave is outside the employment period"})
+		return
+	}
+
+	existing, err := h.Store.ListLeaves()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	for _, other := range existing {
+		if other.EmployeeID == lv.EmployeeID && other.Active() && other.Overlaps(lv.StartDate, lv.EndDate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "leave overlaps an existing request", "leaveId": other.ID})
+			return
+		}
+	}
+
+	policy, bal, ok, err := h.balanceFor(lv, *emp, existing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	if requested := leave.WorkingDays(lv.StartDate, lv.EndDate); ok && requested > bal.Remaining && policy.OverBalance != models.OverBalanceUnpaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leave exceeds remaining balance", "balance": bal, "requested": requested})
+		return
+	}
+
+	lv.ID = 0
+	lv.Status = models.LeaveSubmitted
+	lv.RequestedBy = c.GetString("email")
+	lv.DecidedBy, lv.DecidedAt, lv.DecisionNote = "", nil, ""
 	if err := h.Store.CreateLeave(&lv); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
 		return
 	}
 	c.JSON(http.StatusCreated, lv)
 }
+
+// POST /api/v1/leave/:id/approve  body: {"note":"..."} (optional)
+// ผู้ยื่นคำขออนุมัติใบลาของตนเองไม่ได้ (403), ซ้อนกับใบลาที่อนุมัติแล้ว → 409
+// ตรวจสิทธิคงเหลืออีกครั้ง ณ ตอนอนุมัติ: นโยบาย reject → 409,
+// นโยบาย unpaid → ส่วนที่เกินแยกเป็นใบลาไม่รับค่าจ้าง (บันทึกพร้อมกันใน transaction เดียว)
+func (h *LeaveHandler) Approve(c *gin.Context) {
+	lv, ok := h.loadLeave(c)
+	if !ok {
+		return
+	}
+	if lv.Status != models.LeaveSubmitted {
+		c.JSON(http.StatusConflict, gin.H{"error": "only submitted leave can be approved", "status": lv.Status})
+		return
+	}
+	if by := c.GetString("email"); by != "" && strings.EqualFold(by, lv.RequestedBy) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot approve your own leave request"})
+		return
+	}
+	emp, err := h.Store.GetEmployee(lv.EmployeeID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee not found"})
+		return
+	}
+	existing, err := h.Store.ListLeaves()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+
+	out := []models.Leave{*lv}
+	policy, bal, hasPolicy, err := h.balanceFor(*lv, *emp, existing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	if requested := leave.WorkingDays(lv.StartDate, lv.EndDate); hasPolicy && requested > bal.Remaining {
+		if policy.OverBalance != models.OverBalanceUnpaid {
+			c.JSON(http.StatusConflict, gin.H{"error": "leave exceeds remaining balance", "balance": bal, "requested": requested})
+			return
+		}
+		out = splitUnpaid(*lv, bal.Remaining)
+	}
+
+	note := decisionNote(c)
+	now := time.Now().UTC()
+	var overlap models.Leave
+	err = h.Store.WithTx(func(tx storage.Port) error {
+		// ตรวจวันลาซ้อนตอนยื่นเท่านั้นไม่พอ: คำขอที่ซ้อนกันอาจรออนุมัติพร้อมกัน จึงตรวจกับใบลาที่อนุมัติแล้วอีกครั้ง
+		all, err := tx.ListLeaves()
+		if err != nil {
+			return err
+		}
+		for _, other := range all {
+			if other.ID != lv.ID && other.EmployeeID == lv.EmployeeID && other.Status == models.LeaveApproved && other.Overlaps(lv.StartDate, lv.EndDate) {
+				overlap = other
+				return errLeaveOverlap
+			}
+		}
+		for i := range out {
+			out[i].Status = models.LeaveApproved
+			out[i].DecidedBy = c.GetString("email")
+			out[i].DecidedAt = &now
+			out[i].DecisionNote = note
+			if out[i].ID == 0 {
+				err = tx.CreateLeave(&out[i])
+			} else {
+				err = tx.UpdateLeave(&out[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errLeaveOverlap) {
+		c.JSON(http.StatusConflict, gin.H{"error": "leave overlaps an approved leave", "leaveId": overlap.ID})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	if len(out) == 1 {
+		c.JSON(http.StatusOK, out[0])
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/leave/:id/reject  body: {"note":"..."} (optional)
+func (h *LeaveHandler) Reject(c *gin.Context) {
+	h.decide(c, models.LeaveRejected, models.LeaveSubmitted)
+}
+
+// POST /api/v1/leave/:id/cancel  body: {"note":"..."} (optional)
+func (h *LeaveHandler) Cancel(c *gin.Context) {
+	h.decide(c, models.LeaveCancelled, models.LeaveSubmitted, models.LeaveApproved)
+}
+
+// decide เปลี่ยนสถานะใบลาเป็น to เมื่อสถานะปัจจุบันอยู่ใน from
+func (h *LeaveHandler) decide(c *gin.Context, to string, from ...string) {
+	lv, ok := h.loadLeave(c)
+	if !ok {
+		return
+	}
+	allowed := false
+	for _, st := range from {
+		if lv.Status == st {
+			allowed = true
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusConflict, gin.H{"error": "cannot change leave from " + lv.Status + " to " + to})
+		return
+	}
+
+	now := time.Now().UTC()
+	lv.Status = to
+	lv.DecidedBy = c.GetString("email")
+	lv.DecidedAt = &now
+	lv.DecisionNote = decisionNote(c)
+	if err := h.Store.UpdateLeave(lv); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, lv)
+}
+
+func (h *LeaveHandler) loadLeave(c *gin.Context) (*models.Leave, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+	lv, err := h.Store.GetLeave(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "leave not found"})
+		return nil, false
+	}
+	return lv, true
+}
+
+// balanceFor คืน policy และยอดสิทธิคงเหลือ ณ วันเริ่มลา (ok=false เมื่อประเภทนั้นไม่มีการจำกัดสิทธิ)
+func (h *LeaveHandler) balanceFor(lv models.Leave, emp models.Employee, leaves []models.Leave) (models.LeavePolicy, leave.Balance, bool, error) {
+	if lv.Type == models.LeaveUnpaid {
+		return models.LeavePolicy{}, leave.Balance{}, false, nil
+	}
+	stored, err := h.Store.ListLeavePolicies()
+	if err != nil {
+		return models.LeavePolicy{}, leave.Balance{}, false, err
+	}
+	policy, ok := leave.PolicyFor(stored, lv.Type)
+	if !ok {
+		return models.LeavePolicy{}, leave.Balance{}, false, nil
+	}
+	return policy, leave.ComputeBalance(policy, emp, leaves, lv.StartDate), true, nil
+}
+
+// decisionNote อ่าน {"note":"..."} จาก body (ไม่บังคับ)
+func decisionNote(c *gin.Context) string {
+	var body struct {
+		Note string `json:"note"`
+	}
+	_ = c.ShouldBindJSON(&body)
+	return body.Note
+}
+
+func validLeaveStatus(s string) bool {
+	switch s {
+	case models.LeaveSubmitted, models.LeaveApproved, models.LeaveRejected, models.LeaveCancelled:
+		return true
+	}
+	return false
+}
+
+func strPtr(s string) *string { return &s }
+
+// splitUnpaid แบ่งคำขอลาเป็นส่วนที่ใช้สิทธิ (paidDays วันทำงาน) และส่วนที่เกินเป็นลาไม่รับค่าจ้าง
+func splitUnpaid(lv models.Leave, paidDays int) []models.Leave {
+	lastPaid, ok := leave.SplitAt(lv.StartDate, lv.EndDate, paidDays)
+	if !ok {
+		lv.Unpaid = true
+		return []models.Leave{lv}
+	}
+	paid, unpaid := lv, lv
+	paid.EndDate = lastPaid
+	unpaid.ID = 0
+	unpaid.StartDate = lastPaid.AddDate(0, 0, 1)
+	unpaid.Unpaid = true
+	if unpaid.StartDate.After(unpaid.EndDate) {
+		return []models.Leave{paid}
+	}
+	return []models.Leave{paid, unpaid}
+}
+
+// GET /api/v1/leave/balances?employeeId=&year=
+// คืนยอดสิทธิคงเหลือทุกประเภทของพนักงาน (ไม่ระบุ employeeId = ทุกคน)
+func (h *LeaveHandler) Balances(c *gin.Context) {
+	asOf := time.Now().UTC()
+	if y, _ := strconv.Atoi(c.Query("year")); y > 0 && y != asOf.Year() {
+		asOf = time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	var emps []models.Employee
+	if id, _ := strconv.Atoi(c.Query("employeeId")); id > 0 {
+		e, err := h.Store.GetEmployee(uint(id))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+			return
+		}
+		emps = []models.Employee{*e}
+	} else {
+		all, err := h.Store.ListEmployees()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list employees"})
+			return
+		}
+		emps = all
+	}
+
+	stored, err := h.Store.ListLeavePolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	leaves, err := h.Store.ListLeaves()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+
+	policies := leave.Policies(stored)
+	out := make([]gin.H, 0, len(emps))
+	for _, e := range emps {
+		balances := make([]leave.Balance, 0, len(policies))
+		for _, p := range policies {
+			balances = append(balances, leave.ComputeBalance(p, e, leaves, asOf))
+		}
+		out = append(out, gin.H{"employeeId": e.ID, "empCode": e.EmpCode, "balances": balances})
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /api/v1/leave/policies
+func (h *LeaveHandler) Policies(c *gin.Context) {
+	stored, err := h.Store.ListLeavePolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, leave.Policies(stored))
+}
+
+// PUT /api/v1/leave/policies/:type
+func (h *LeaveHandler) SavePolicy(c *gin.Context) {
+	leaveType := c.Param("type")
+	if !models.ValidLeaveType(leaveType) || leaveType == models.LeaveUnpaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of sick, personal, annual, maternity"})
+		return
+	}
+
+	var req struct {
+		Tiers        []models.AccrualTier `json:"tiers" binding:"required"`
+		MaxCarryOver int                  `json:"maxCarryOver"`
+		OverBalance  string               `json:"overBalance"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if req.OverBalance == "" {
+		req.OverBalance = models.OverBalanceReject
+	}
+	if req.OverBalance != models.OverBalanceReject && req.OverBalance != models.OverBalanceUnpaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "overBalance must be 'reject' or 'unpaid'"})
+		return
+	}
+	if req.MaxCarryOver < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "maxCarryOver must be >= 0"})
+		return
+	}
+	for _, t := range req.Tiers {
+		if t.MinServiceMonths < 0 || t.Days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tiers must have non-negative minServiceMonths and days"})
+			return
+		}
+	}
+
+	p := &models.LeavePolicy{
+		Type:         leaveType,
+		Tiers:        req.Tiers,
+		MaxCarryOver: req.MaxCarryOver,
+		OverBalance:  req.OverBalance,
+	}
+	if err := h.Store.SaveLeavePolicy(p); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "save policy failed"})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// leaveRouter router ของใบลา โดยใช้ header X-Email แทน token
+func leaveRouter(st storage.Port) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewLeaveHandler(st)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("email", c.GetHeader("X-Email")) })
+	r.POST("/leave", h.Create)
+	r.POST("/leave/:id/approve", h.Approve)
+	return r
+}
+
+func leaveRequest(t *testing.T, r *gin.Engine, path, email, body string) (int, models.Leave) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Email", email)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var lv models.Leave
+	_ = json.Unmarshal(w.Body.Bytes(), &lv)
+	return w.Code, lv
+}
+
+func TestLeaveUnpaidIsDerivedAndSelfApprovalBlocked(t *testing.T) {
+	st := storage.New()
+	e := &models.Employee{EmpCode: "E1", FirstName: "A", LastName: "B", BaseSalary: money.FromBaht(30000), Status: "active",
+		HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	r := leaveRouter(st)
+
+	// unpaid ใน payload ถูกละเลย: ลาพักร้อนยังใช้สิทธิตามปกติ
+	code, lv := leaveRequest(t, r, "/leave", "staff@example.com",
+		`{"employeeId":1,"type":"annual","startDate":"2026-03-02T00:00:00Z","endDate":"2026-03-02T00:00:00Z","unpaid":true}`)
+	if code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	if lv.Unpaid || lv.RequestedBy != "staff@example.com" {
+		t.Fatalf("created leave unpaid=%v requestedBy=%q, want false and the submitter", lv.Unpaid, lv.RequestedBy)
+	}
+
+	path := "/leave/1/approve"
+	if code, _ := leaveRequest(t, r, path, "STAFF@example.com", ""); code != http.StatusForbidden {
+		t.Fatalf("self approval: status %d, want 403", code)
+	}
+	code, lv = leaveRequest(t, r, path, "manager@example.com", "")
+	if code != http.StatusOK || lv.Status != models.LeaveApproved || lv.DecidedBy != "manager@example.com" {
+		t.Fatalf("approve: status %d, leave %+v", code, lv)
+	}
+
+	// ประเภท unpaid เป็นลาไม่รับค่าจ้างเสมอ
+	_, lv = leaveRequest(t, r, "/leave", "staff@example.com",
+		`{"employeeId":1,"type":"unpaid","startDate":"2026-03-03T00:00:00Z","unpaid":false}`)
+	if !lv.Unpaid {
+		t.Fatal("unpaid leave type must be stored as unpaid")
+	}
+}
+
+// failingLeaves store ที่สร้างใบลาใหม่ไม่ได้ ใช้ทดสอบว่าการอนุมัติแบบแยกส่วนลาไม่รับค่าจ้างย้อนกลับทั้งหมด
+type failingLeaves struct{ storage.Port }
+
+func (f failingLeaves) CreateLeave(*models.Leave) error { return errors.New("injected") }
+
+func (f failingLeaves) WithTx(fn func(tx storage.Port) error) error {
+	return f.Port.WithTx(func(tx storage.Port) error { return fn(failingLeaves{tx}) })
+}
+
+// การอนุมัติที่ต้องแยกส่วนเกินสิทธิเป็นลาไม่รับค่าจ้าง: บันทึกไม่ครบต้องไม่ทิ้งใบลาไว้ครึ่งทาง
+func TestLeaveApproveSplitIsAtomic(t *testing.T) {
+	st := storage.New()
+	e := &models.Employee{EmpCode: "E1", FirstName: "A", LastName: "B", BaseSalary: money.FromBaht(30000), Status: "active",
+		HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	// สิทธิลากิจ 1 วันต่อปี ส่วนที่เกินเป็นลาไม่รับค่าจ้าง
+	if err := st.SaveLeavePolicy(&models.LeavePolicy{Type: models.LeavePersonal, Tiers: []models.AccrualTier{{MinServiceMonths: 0, Days: 1}}, OverBalance: models.OverBalanceUnpaid}); err != nil {
+		t.Fatal(err)
+	}
+	// ลา 2–4 มี.ค. 2026 (3 วันทำงาน)
+	code, lv := leaveRequest(t, leaveRouter(st), "/leave", "staff@example.com",
+		`{"employeeId":1,"type":"personal","startDate":"2026-03-02T00:00:00Z","endDate":"2026-03-04T00:00:00Z"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+
+	if code, _ := leaveRequest(t, leaveRouter(failingLeaves{st}), "/leave/1/approve", "manager@example.com", ""); code != http.StatusInternalServerError {
+		t.Fatalf("approve with a failing store: status %d, want 500", code)
+	}
+	got, err := st.GetLeave(lv.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.LeaveSubmitted || !got.EndDate.Equal(lv.EndDate) {
+		t.Fatalf("leave after failed approval = %s %s–%s, want submitted and unchanged", got.Status, got.StartDate, got.EndDate)
+	}
+	if all, _ := st.ListLeaves(); len(all) != 1 {
+		t.Fatalf("leaves = %d, want 1", len(all))
+	}
+}
+
+// คำขอลาที่ซ้อนกันซึ่งรออนุมัติพร้อมกันอนุมัติได้เพียงใบเดียว
+func TestLeaveApproveRejectsOverlapWithApproved(t *testing.T) {
+	st := storage.New()
+	e := &models.Employee{EmpCode: "E1", FirstName: "A", LastName: "B", BaseSalary: money.FromBaht(30000), Status: "active",
+		HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	// สองใบยื่นเข้ามาพร้อมกัน (ผ่านการตรวจซ้อนตอนยื่นทั้งคู่): 2–3 มี.ค. และ 3–4 มี.ค.
+	for _, d := range [][2]int{{2, 3}, {3, 4}} {
+		if err := st.CreateLeave(&models.Leave{EmployeeID: e.ID, Type: models.LeaveUnpaid, Unpaid: true, Status: models.LeaveSubmitted, RequestedBy: "staff@example.com",
+			StartDate: time.Date(2026, 3, d[0], 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 3, d[1], 0, 0, 0, 0, time.UTC)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := leaveRouter(st)
+	if code, _ := leaveRequest(t, r, "/leave/1/approve", "manager@example.com", ""); code != http.StatusOK {
+		t.Fatalf("approve first: status %d", code)
+	}
+	if code, _ := leaveRequest(t, r, "/leave/2/approve", "manager@example.com", ""); code != http.StatusConflict {
+		t.Fatalf("approve overlapping: status %d, want 409", code)
+	}
+	if got, _ := st.GetLeave(2); got.Status != models.LeaveSubmitted {
+		t.Fatalf("overlapping leave status = %s, want submitted", got.Status)
+	}
+}
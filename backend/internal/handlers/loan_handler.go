@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoanHandler จัดการเงินกู้/เงินเดือนล่วงหน้าที่ผ่อนชำระผ่าน payroll
+type LoanHandler struct {
+	Store   storage.Port
+	Payroll *payroll.Service
+}
+
+func NewLoanHandler(store storage.Port) *LoanHandler {
+	return &LoanHandler{Store: store, Payroll: payroll.NewService(store)}
+}
+
+// GET /api/v1/employees/:id/loans
+func (h *LoanHandler) List(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetEmployee(uint(empID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	out, err := h.Store.ListLoans(uint(empID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/employees/:id/loans
+// body: {"type":"welfare","description":"...","principal":30000,"interestRate":0.05,"installments":12,"startDate":"2026-01-01"}
+// ดอกเบี้ยคิดแบบคงที่ตามจำนวนงวดผ่อนของกลุ่มจ่ายเงินของพนักงาน
+func (h *LoanHandler) Create(c *gin.Context) {
+	empID, _ := strconv.Atoi(c.Param("id"))
+	emp, err := h.Store.GetEmployee(uint(empID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+
+	var req struct {
+		Type         string       `json:"type" binding:"required"`
+		Description  string       `json:"description"`
+		Principal    money.Amount `json:"principal"`
+		InterestRate float64      `json:"interestRate"`
+		Installments int          `json:"installments" binding:"required"`
+		StartDate    string       `json:"startDate" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	typ := strings.ToLower(strings.TrimSpace(req.Type))
+	if !models.ValidLoanType(typ) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of " + strings.Join(models.LoanTypes, ", ")})
+		return
+	}
+	if req.Principal <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "principal must be > 0"})
+		return
+	}
+	if req.InterestRate < 0 || req.InterestRate > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interestRate must be between 0 and 1"})
+		return
+	}
+	if req.Installments <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "installments must be > 0"})
+		return
+	}
+	start, err := parseDate(req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate; use YYYY-MM-DD"})
+		return
+	}
+	if emp.TerminatedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "employee is terminated"})
+		return
+	}
+
+	freq := models.FrequencyMonthly
+	if emp.PayGroupID != nil {
+		group, err := h.Store.GetPayGroup(*emp.PayGroupID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "pay group not found"})
+			return
+		}
+		freq = group.Frequency
+	}
+
+	loan := &models.Loan{
+		EmployeeID:   emp.ID,
+		Type:         typ,
+		Description:  strings.TrimSpace(req.Description),
+		Principal:    req.Principal,
+		InterestRate: req.InterestRate,
+		Installments: req.Installments,
+		StartDate:    dateOnly(start),
+		Status:       models.LoanActive,
+		CreatedBy:    c.GetString("email"),
+	}
+	if loan.Description == "" {
+		if typ == models.LoanAdvance {
+			loan.Description = "Salary advance"
+		} else {
+			loan.Description = "Welfare loan"
+		}
+	}
+	payroll.PlanLoan(loan, freq)
+	if err := h.Store.CreateLoan(loan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save loan"})
+		return
+	}
+	c.JSON(http.StatusCreated, loan)
+}
+
+// GET /api/v1/loans/:id
+// เงินกู้พร้อมประวัติการชำระและตารางผ่อนงวดที่เหลือ
+func (h *LoanHandler) Get(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	out, err := h.Payroll.LoanSchedule(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "loan not found"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/loans/:id/pause
+// พักชำระ: run ที่คำนวณหลังจากนี้จะไม่หักเงินกู้นี้
+func (h *LoanHandler) Pause(c *gin.Context) {
+	h.setStatus(c, models.LoanActive, models.LoanPaused)
+}
+
+// POST /api/v1/loans/:id/resume
+func (h *LoanHandler) Resume(c *gin.Context) {
+	h.setStatus(c, models.LoanPaused, models.LoanActive)
+}
+
+func (h *LoanHandler) setStatus(c *gin.Context, from, to string) {
+	loan, ok := h.loadLoan(c)
+	if !ok {
+		return
+	}
+	if loan.Status != from {
+		c.JSON(http.StatusConflict, gin.H{"error": "loan is " + loan.Status})
+		return
+	}
+	loan.Status = to
+	if err := h.Store.UpdateLoan(loan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update loan"})
+		return
+	}
+	c.JSON(http.StatusOK, loan)
+}
+
+// POST /api/v1/loans/:id/payoff
+// body: {"method":"payroll"} หักยอดคงเหลือทั้งหมดใน run ปกติถัดไป
+// หรือ {"method":"cash","note":"..."} บันทึกว่าชำระเงินสดครบแล้ว (ปิดบัญชีทันที)
+func (h *LoanHandler) Payoff(c *gin.Context) {
+	loan, ok := h.loadLoan(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Method string `json:"method" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if loan.Status == models.LoanPaidOff || loan.Outstanding <= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "loan is already paid off"})
+		return
+	}
+
+	switch strings.ToLower(strings.TrimSpace(req.Method)) {
+	case "payroll":
+		loan.PayoffInPayroll = true
+		loan.Status = models.LoanActive
+	case "cash":
+		rep := &models.LoanRepayment{LoanID: loan.ID, Amount: loan.Outstanding, Note: strings.TrimSpace(req.Note), CreatedBy: c.GetString("email")}
+		if err := h.Store.CreateLoanRepayment(rep); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save repayment"})
+			return
+		}
+		loan.Outstanding, loan.Status, loan.PayoffInPayroll = 0, models.LoanPaidOff, false
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be 'payroll' or 'cash'"})
+		return
+	}
+	if err := h.Store.UpdateLoan(loan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update loan"})
+		return
+	}
+	c.JSON(http.StatusOK, loan)
+}
+
+func (h *LoanHandler) loadLoan(c *gin.Context) (*models.Loan, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	loan, err := h.Store.GetLoan(uint(id))
+	if err != nil || loan == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "loan not found"})
+		return nil, false
+	}
+	return loan, true
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+)
+
+var errBadDate = errors.New("invalid date format; use YYYY-MM-DD or RFC3339")
+
+// parseDate รับวันที่แบบ YYYY-MM-DD หรือ RFC3339
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errBadDate
+}
+
+// parseOptionalDate คืน nil เมื่อไม่ได้ส่งค่ามา
+func parseOptionalDate(s *string) (*time.Time, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	t, err := parseDate(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// dateOnly ตัดเวลาออก (UTC) ใช้เทียบวันที่กับข้อมูลที่เก็บเป็น timestamp
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayGroupHandler จัดการกลุ่มจ่ายเงิน (ความถี่การจ่าย) และปฏิทินงวด
+type PayGroupHandler struct {
+	Store storage.Port
+}
+
+func NewPayGroupHandler(store storage.Port) *PayGroupHandler {
+	return &PayGroupHandler{Store: store}
+}
+
+// GET /api/v1/pay-groups
+func (h *PayGroupHandler) List(c *gin.Context) {
+	out, err := h.Store.ListPayGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/pay-groups
+// body: {"code":"FACTORY","name":"พนักงานรายวันโรงงาน","frequency":"semi_monthly","anchorDate":"2025-01-06"}
+func (h *PayGroupHandler) Create(c *gin.Context) {
+	var req struct {
+		Code       string  `json:"code" binding:"required"`
+		Name       string  `json:"name" binding:"required"`
+		Frequency  string  `json:"frequency"`
+		AnchorDate *string `json:"anchorDate"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if req.Frequency == "" {
+		req.Frequency = models.FrequencyMonthly
+	}
+	if !models.ValidFrequency(req.Frequency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "frequency must be one of monthly, semi_monthly, biweekly, weekly"})
+		return
+	}
+	anchor, err := parseOptionalDate(req.AnchorDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "anchorDate: " + err.Error()})
+		return
+	}
+
+	g := &models.PayGroup{
+		Code:       strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:       strings.TrimSpace(req.Name),
+		Frequency:  req.Frequency,
+		AnchorDate: anchor,
+	}
+	if err := h.Store.CreatePayGroup(g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "create pay group failed", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// GET /api/v1/pay-groups/:id/calendar?year=2025
+// id = 0 คือกลุ่มรายเดือนตั้งต้น
+func (h *PayGroupHandler) Calendar(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+	year, _ := strconv.Atoi(c.Query("year"))
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	cal, err := payroll.Calendar(group, year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cal)
+}
+
+// PUT /api/v1/pay-groups/:id/employees  body: {"employeeIds":[1,2,3]}
+// ย้ายพนักงานเข้ากลุ่ม (id = 0 คือกลับไปกลุ่มรายเดือนตั้งต้น)
+func (h *PayGroupHandler) AssignEmployees(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		EmployeeIDs []uint `json:"employeeIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employeeIds is required"})
+		return
+	}
+
+	var groupID *uint
+	if group != nil {
+		groupID = &group.ID
+	}
+	updated := make([]models.Employee, 0, len(req.EmployeeIDs))
+	for _, id := range req.EmployeeIDs {
+		e, err := h.Store.GetEmployee(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "employee not found", "employeeId": id})
+			return
+		}
+		e.PayGroupID = groupID
+		if err := h.Store.UpdateEmployee(e); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "update employee failed"})
+			return
+		}
+		updated = append(updated, *e)
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// loadGroup คืน nil (ไม่ error) เมื่อ id = 0 ซึ่งหมายถึงกลุ่มรายเดือนตั้งต้น
+func (h *PayGroupHandler) loadGroup(c *gin.Context) (*models.PayGroup, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+	if id == 0 {
+		return nil, true
+	}
+	g, err := h.Store.GetPayGroup(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pay group not found"})
+		return nil, false
+	}
+	return g, true
+}
@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"bytes"
-	"encoding/csv"
+	"errors"
 	"fmt"
-	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"backend/internal/bankfile"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/ssofile"
 	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -19,11 +23,12 @@ import (
 
 // PayrollHandler จัดการ endpoint เกี่ยวกับการจ่ายเงินเดือน
 type PayrollHandler struct {
-	Store storage.Port
+	Store   storage.Port
+	Payroll *payroll.Service
 }
 
 func NewPayrollHandler(store storage.Port) *PayrollHandler {
-	return &PayrollHandler{Store: store}
+	return &PayrollHandler{Store: store, Payroll: payroll.NewService(store)}
 }
 
 // POST /api/v1/auth/login
@@ -61,13 +66,44 @@ func (h *PayrollHandler) Login(c *gin.Context) {
 	})
 }
 
+// GET /api/v1/payroll/runs?year=&month=&type=
+func (h *PayrollHandler) ListRuns(c *gin.Context) {
+	year, _ := strconv.Atoi(c.Query("year"))
+	month, _ := strconv.Atoi(c.Query("month"))
+	runs, err := h.Store.ListPayrollRuns(year, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	if t := c.Query("type"); t != "" {
+		filtered := runs[:0]
+		for _, r := range runs {
+			if r.Type == t {
+				filtered = append(filtered, r)
+			}
+		}
+		runs = filtered
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
 // POST /api/v1/payroll/runs
 // body: {"year":2025,"month":10} หรือ {"payDate":"2025-10"} / "2025-10-31"
+// run นอกรอบ: {"year":2025,"month":10,"type":"bonus","description":"...","employeeIds":[..],"componentCodes":[..]}
+// กลุ่มจ่ายเงิน: {"payGroupId":2,"year":2025,"periodNo":19} หรือ {"payGroupId":2,"date":"2025-10-20"}
+// run ปกติมีได้หนึ่ง run ต่อกลุ่มต่องวด (ถ้ามีอยู่แล้วจะคืน run เดิม) ส่วน run นอกรอบสร้างใหม่ทุกครั้ง
 func (h *PayrollHandler) CreateRun(c *gin.Context) {
 	var body struct {
-		Year    int     `json:"year"`
-		Month   int     `json:"month"`
-		PayDate *string `json:"payDate"`
+		Year           int      `json:"year"`
+		Month          int      `json:"month"`
+		PayDate        *string  `json:"payDate"`
+		PayGroupID     *uint    `json:"payGroupId"`
+		PeriodNo       int      `json:"periodNo"`
+		Date           *string  `json:"date"`
+		Type           string   `json:"type"`
+		Description    string   `json:"description"`
+		EmployeeIDs    []uint   `json:"employeeIds"`
+		ComponentCodes []string `json:"componentCodes"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
@@ -83,28 +119,91 @@ func (h *PayrollHandler) CreateRun(c *gin.Context) {
 			body.Year, body.Month = t3.Year(), int(t3.Month())
 		}
 	}
+
+	// งวดของกลุ่มจ่ายเงิน: เลือกจากลำดับงวด (periodNo) หรือวันที่ในงวด (date)
+	var period *payroll.Period
+	if body.PayGroupID != nil {
+		group, err := h.Store.GetPayGroup(*body.PayGroupID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pay group not found"})
+			return
+		}
+		date, err := parseOptionalDate(body.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date: " + err.Error()})
+			return
+		}
+		var p payroll.Period
+		switch {
+		case date != nil:
+			p, err = payroll.PeriodOn(group, *date)
+		case body.PeriodNo > 0:
+			p, err = payroll.PeriodNo(group, body.Year, body.PeriodNo)
+		case group.Frequency == models.FrequencyMonthly:
+			p, err = payroll.PeriodNo(group, body.Year, body.Month)
+		default:
+			err = payroll.ErrInvalidPeriod
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "periodNo or date inside a pay period is required for this pay group"})
+			return
+		}
+		period = &p
+		body.Year, body.Month = p.End.Year(), int(p.End.Month())
+	}
+
 	if body.Year <= 0 || body.Month < 1 || body.Month > 12 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "year/month is required and must be valid"})
 		return
 	}
-
-	// Check if run already exists
-	existingRun, err := h.Store.GetPayrollRunByPeriod(body.Year, body.Month)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
-		return
+	if body.Type == "" {
+		body.Type = models.RunRegular
 	}
-	if existingRun != nil {
-		// Return existing run
-		c.JSON(http.StatusOK, existingRun)
+	if !models.ValidRunType(body.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of regular, bonus, correction, termination"})
 		return
 	}
+	for _, id := range body.EmployeeIDs {
+		if _, err := h.Store.GetEmployee(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("employee %d not found", id)})
+			return
+		}
+	}
+
+	if body.Type == models.RunRegular {
+		// Check if run already exists
+		var existingRun *models.PayrollRun
+		var err error
+		if period != nil {
+			existingRun, err = h.Payroll.RegularRun(body.PayGroupID, *period)
+		} else {
+			existingRun, err = h.Store.GetPayrollRunByPeriod(body.Year, body.Month)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+			return
+		}
+		if existingRun != nil {
+			// Return existing run
+			c.JSON(http.StatusOK, existingRun)
+			return
+		}
+	}
 
 	// Create new run
 	run := models.PayrollRun{
-		PeriodYear:  body.Year,
-		PeriodMonth: body.Month,
-		Locked:      false,
+		PeriodYear:     body.Year,
+		PeriodMonth:    body.Month,
+		Type:           body.Type,
+		Description:    body.Description,
+		EmployeeIDs:    body.EmployeeIDs,
+		ComponentCodes: body.ComponentCodes,
+		PayGroupID:     body.PayGroupID,
+		Status:         models.RunDraft,
+		Locked:         false,
+	}
+	if period != nil {
+		run.PeriodStart, run.PeriodEnd = &period.Start, &period.End
 	}
 	if err := h.Store.CreatePayrollRun(&run); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "create run failed"})
@@ -113,69 +212,229 @@ func (h *PayrollHandler) CreateRun(c *gin.Context) {
 	c.JSON(http.StatusCreated, run)
 }
 
-// POST /api/v1/payroll/runs/:id/calculate
-func (h *PayrollHandler) CalculateRun(c *gin.Context) {
+// GET /api/v1/payroll/runs/:id/inputs
+func (h *PayrollHandler) ListRunInputs(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetPayrollRun(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	out, err := h.Store.ListRunInputs(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
 
+// POST /api/v1/payroll/runs/:id/inputs
+// body: {"employeeId":1,"code":"BONUS","description":"...","kind":"earning","amount":10000,"taxable":true,"ssoAble":false}
+func (h *PayrollHandler) CreateRunInput(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
 	run, err := h.Store.GetPayrollRun(uint(id))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "run not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	if !run.Editable() {
+		c.JSON(http.StatusConflict, gin.H{"error": "run is " + run.Status + "; inputs can no longer be changed"})
 		return
 	}
 
-	if err := h.Store.ClearPayrollItems(run.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "clear items failed"})
+	var body struct {
+		EmployeeID  uint         `json:"employeeId" binding:"required"`
+		Code        string       `json:"code" binding:"required"`
+		Description string       `json:"description"`
+		Kind        string       `json:"kind"`
+		Amount      money.Amount `json:"amount"`
+		Taxable     *bool        `json:"taxable"`
+		SSOable     *bool        `json:"ssoAble"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body", "detail": err.Error()})
+		return
+	}
+	if body.Kind == "" {
+		body.Kind = models.ComponentEarning
+	}
+	if body.Kind != models.ComponentEarning && body.Kind != models.ComponentDeduction {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be 'earning' or 'deduction'"})
+		return
+	}
+	if body.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+	if _, err := h.Store.GetEmployee(body.EmployeeID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee not found"})
 		return
 	}
 
-	ps, pe := monthStartEnd(run.PeriodYear, run.PeriodMonth)
+	ri := &models.RunInput{
+		RunID:       run.ID,
+		EmployeeID:  body.EmployeeID,
+		Code:        strings.ToUpper(strings.TrimSpace(body.Code)),
+		Description: body.Description,
+		Kind:        body.Kind,
+		Amount:      body.Amount,
+		Taxable:     body.Taxable == nil || *body.Taxable,
+		SSOable:     body.SSOable != nil && *body.SSOable,
+	}
+	if ri.Description == "" {
+		ri.Description = ri.Code
+	}
+	if err := h.Store.CreateRunInput(ri); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "create input failed"})
+		return
+	}
+	c.JSON(http.StatusCreated, ri)
+}
 
-	emps, err := h.Store.ListActiveEmployees()
+// DELETE /api/v1/payroll/runs/:id/inputs/:inputId
+func (h *PayrollHandler) DeleteRunInput(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	inputID, _ := strconv.Atoi(c.Param("inputId"))
+	run, err := h.Store.GetPayrollRun(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	if !run.Editable() {
+		c.JSON(http.StatusConflict, gin.H{"error": "run is " + run.Status + "; inputs can no longer be changed"})
+		return
+	}
+	inputs, err := h.Store.ListRunInputs(run.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "list employees failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
 		return
 	}
+	for _, ri := range inputs {
+		if ri.ID == uint(inputID) {
+			if err := h.Store.DeleteRunInput(ri.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+				return
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "input not found"})
+}
 
-	count := 0
-	for _, e := range emps {
-		worked, total := overlapDays(e.HiredAt, e.TerminatedAt, ps, pe)
-		if total <= 0 || worked <= 0 {
-			continue
+// POST /api/v1/payroll/runs/:id/calculate
+func (h *PayrollHandler) CalculateRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	count, err := h.Payroll.CalculateRun(uint(id), c.GetString("email"))
+	if err != nil {
+		if errors.Is(err, payroll.ErrRunNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "run not found"})
+			return
 		}
+		if errors.Is(err, payroll.ErrRunLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": "run is approved; reopen it before recalculating"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "calculate failed"})
+		return
+	}
 
-		// เงินเดือนตามสัดส่วนวันทำงาน
-		gross := e.BaseSalary * (float64(worked) / float64(total))
+	c.JSON(http.StatusOK, gin.H{"calculated": count})
+}
 
-		// คำนวณประกันสังคม (SSO): สูงสุด 750 บาท (เงินเดือน 15,000 บาท x 5%)
-		sso := calculateSSO(gross)
+// GET /api/v1/payroll/runs/:id/preview
+// คำนวณ run แบบ dry run (ไม่ล้าง/บันทึก items และไม่เปลี่ยนสถานะ) พร้อมความต่างจาก run ก่อนหน้า
+func (h *PayrollHandler) PreviewRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	preview, err := h.Payroll.Preview(uint(id))
+	if err != nil {
+		if errors.Is(err, payroll.ErrRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "preview failed"})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
 
-		// คำนวณกองทุนสำรองเลี้ยงชีพ (PVD): 3% ของเงินเดือน (ตัวอย่าง)
-		pvd := gross * 0.03
+// GET /api/v1/payroll/runs/:id
+func (h *PayrollHandler) GetRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	run, err := h.Store.GetPayrollRun(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
 
-		// คำนวณภาษี: 5% ของเงินเดือนหลังหัก SSO และ PVD
-		taxableIncome := gross - sso - pvd
-		tax := calculateTax(taxableIncome)
+// POST /api/v1/payroll/runs/:id/status
+// body: {"status":"approved","note":"..."} — calculated→approved, approved→paid, paid→closed,
+// approved→calculated (ยกเลิกอนุมัติ), calculated→draft (ล้างผลคำนวณ)
+func (h *PayrollHandler) TransitionRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
 
-		net := gross - tax - sso - pvd
+	var body struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
+		return
+	}
+	if !payroll.ValidRunStatus(body.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of draft, calculated, approved, paid, closed"})
+		return
+	}
 
-		item := &models.PayrollItem{
-			RunID:       run.ID,
-			EmployeeID:  e.ID,
-			BaseSalary:  round2(gross), // ใส่ยอดหลัง prorate ลงคอลัมน์ base_salary
-			TaxWithheld: round2(tax),
-			SSO:         round2(sso),
-			PVD:         round2(pvd),
-			NetPay:      round2(net),
-			// GeneratedAt: autoCreateTime โดย GORM
-		}
-		if err := h.Store.SavePayrollItem(item); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "save item failed"})
-			return
+	run, err := h.Payroll.Transition(uint(id), body.Status, c.GetString("email"), body.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrRunNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		case errors.Is(err, payroll.ErrInvalidTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": "status change not allowed", "to": body.Status})
+		case errors.Is(err, payroll.ErrStaleDeductions):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
 		}
-		count++
+		return
 	}
+	c.JSON(http.StatusOK, run)
+}
 
-	c.JSON(http.StatusOK, gin.H{"calculated": count})
+// GET /api/v1/payroll/runs/:id/transitions
+func (h *PayrollHandler) ListRunTransitions(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetPayrollRun(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	out, err := h.Store.ListRunTransitions(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /api/v1/payroll/runs/:id
+func (h *PayrollHandler) DeleteRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.Payroll.DeleteRun(uint(id)); err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrRunNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		case errors.Is(err, payroll.ErrRunLocked):
+			c.JSON(http.StatusConflict, gin.H{"error": "approved runs cannot be deleted"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
 
 // GET /api/v1/payroll/runs/:id/items
@@ -189,45 +448,265 @@ func (h *PayrollHandler) ListRunItems(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
-// POST /api/v1/payroll/runs/:id/export-bank-csv
-func (h *PayrollHandler) ExportBankCSV(c *gin.Context) {
+// GET /api/v1/payroll/runs/:id/totals
+func (h *PayrollHandler) RunTotals(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if _, err := h.Store.GetPayrollRun(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
 	items, err := h.Store.ListPayrollItems(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
 		return
 	}
+	c.JSON(http.StatusOK, payroll.Totals(items))
+}
+
+// GET /api/v1/payroll/runs/:id/retro
+// ส่วนต่างเงินเดือนย้อนหลังที่จ่ายใน run นี้ แยกตามงวดเดิม
+func (h *PayrollHandler) RetroReport(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	rep, err := h.Payroll.RetroReport(uint(id))
+	if err != nil {
+		if errors.Is(err, payroll.ErrRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// GET /api/v1/payroll/runs/:id/garnishments
+// ยอดอายัดเงินเดือนที่ต้องนำส่งของ run แยกตามเจ้าหนี้
+func (h *PayrollHandler) RemittanceReport(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	rep, err := h.Payroll.RemittanceReport(uint(id))
+	if err != nil {
+		if errors.Is(err, payroll.ErrRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// GET /api/v1/payroll/runs/:id/bank-file?date=YYYY-MM-DD
+// ไฟล์โอนเงินเดือน CSV กลางสำหรับนำเข้าบริการ payroll ของธนาคาร ไม่รวมพนักงานที่รับผ่านพร้อมเพย์
+func (h *PayrollHandler) ExportBankFile(c *gin.Context) {
+	w, ok := h.bankWriter(c)
+	if !ok {
+		return
+	}
+	h.exportPayments(c, w, func(p payroll.Payments) bankfile.Batch { return p.Bank })
+}
 
+// GET /api/v1/payroll/runs/:id/pain001?date=YYYY-MM-DD
+// ไฟล์ ISO 20022 pain.001.001.09 สำหรับ corporate banking portal (รายการเดียวกับไฟล์ของธนาคาร)
+func (h *PayrollHandler) ExportPain001(c *gin.Context) {
+	h.exportPayments(c, bankfile.Pain001{}, func(p payroll.Payments) bankfile.Batch { return p.Bank })
+}
+
+// GET /api/v1/payroll/runs/:id/promptpay-file?date=YYYY-MM-DD
+// ไฟล์ pain.001 ของพนักงานที่รับเงินเดือนผ่านพร้อมเพย์ (ผู้รับระบุด้วยหมายเลขพร้อมเพย์ใน CdtrAcct/Prxy)
+func (h *PayrollHandler) ExportPromptPay(c *gin.Context) {
+	h.exportPayments(c, bankfile.PromptPay{}, func(p payroll.Payments) bankfile.Batch { return p.PromptPay })
+}
+
+// GET /api/v1/payroll/runs/:id/payments
+// สรุปจำนวนรายการ/ยอดรวมของไฟล์ธนาคารและไฟล์พร้อมเพย์ เทียบกับเงินสุทธิรวมของ run
+func (h *PayrollHandler) PaymentSummary(c *gin.Context) {
+	w, ok := h.bankWriter(c)
+	if !ok {
+		return
+	}
+	p, ok := h.payments(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, p.Summary(w.Format()))
+}
+
+// GET /api/v1/payroll/runs/:id/sso-file
+// ไฟล์ข้อความ สปส.1-10 ของเดือนของ run สำหรับอัปโหลดผ่าน e-Service ของสำนักงานประกันสังคม
+// ไม่สร้างไฟล์ถ้าข้อมูลผู้ประกันตน/ทะเบียนนายจ้างไม่ครบ (422 พร้อมรายการปัญหา)
+func (h *PayrollHandler) ExportSSOFile(c *gin.Context) {
+	r, ok := h.ssoReport(c)
+	if !ok {
+		return
+	}
 	var buf bytes.Buffer
-	w := csv.NewWriter(&buf)
-	_ = w.Write([]string{"employee_code", "name", "bank_code", "amount", "ref"})
+	if err := ssofile.Write(&buf, r); err != nil {
+		var re *ssofile.ReportError
+		if errors.As(err, &re) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "problems": re.Problems})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write SSO file"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=sso%04d%02d.txt", r.Year, r.Month))
+	c.Header("X-Control-Count", strconv.Itoa(r.Count))
+	c.Header("X-Control-Total", r.Total.String())
+	c.Data(http.StatusOK, "text/plain; charset=tis-620", buf.Bytes())
+}
 
-	for _, it := range items {
-		row := []string{
-			fmt.Sprintf("%d", it.EmployeeID),
-			fmt.Sprintf("Employee-%d", it.EmployeeID),
-			"XXX",
-			fmt.Sprintf("%.2f", it.NetPay),
-			fmt.Sprintf("RUN-%d", it.RunID),
+// GET /api/v1/payroll/runs/:id/sso
+// สรุปเงินสมทบประกันสังคมของเดือนแยกตามบัญชีนายจ้าง/สาขา (format=html = ใบสรุปสำหรับพิมพ์)
+func (h *PayrollHandler) SSOSummary(c *gin.Context) {
+	r, ok := h.ssoReport(c)
+	if !ok {
+		return
+	}
+	if c.Query("format") != "html" {
+		c.JSON(http.StatusOK, r)
+		return
+	}
+	var buf bytes.Buffer
+	if err := ssofile.WriteSummary(&buf, r); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render SSO summary"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (h *PayrollHandler) ssoReport(c *gin.Context) (ssofile.Report, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	r, err := h.Payroll.SSOReport(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrRunNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		case errors.Is(err, payroll.ErrRunNotCalculated):
+			c.JSON(http.StatusConflict, gin.H{"error": "run must be calculated before exporting SSO contributions"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
 		}
-		_ = w.Write(row)
+		return r, false
 	}
-	w.Flush()
+	return r, true
+}
 
-	c.Header("Content-Type", "text/csv")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=payroll%d.csv", id))
-	c.String(http.StatusOK, buf.String())
+// bankWriter Writer ของไฟล์ธนาคารตามธนาคารของบริษัท (รหัสธนาคารที่ตั้งผิดตอบ 500 แทนการใช้ Writer ว่าง)
+func (h *PayrollHandler) bankWriter(c *gin.Context) (bankfile.Writer, bool) {
+	w, err := bankfile.For(bankfile.CurrentCompany().BankCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return w, true
+}
+
+// payments รายการโอนเงินของ run (date = วันที่โอนเข้าบัญชี ไม่ระบุ = วันจ่ายเงินของ run)
+func (h *PayrollHandler) payments(c *gin.Context) (payroll.Payments, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var effective *time.Time
+	if s := c.Query("date"); s != "" {
+		d, err := parseDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date; use YYYY-MM-DD"})
+			return payroll.Payments{}, false
+		}
+		effective = &d
+	}
+	p, err := h.Payroll.Payments(uint(id), effective)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrRunNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		case errors.Is(err, payroll.ErrRunNotApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": "run must be approved before exporting payment files"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		}
+		return p, false
+	}
+	return p, true
+}
+
+// exportPayments เขียนไฟล์โอนเงินของ run ด้วย w จากชุดรายการที่ pick เลือก
+// ไม่สร้างไฟล์ถ้ายอดของไฟล์ทั้งหมดไม่ตรงกับเงินสุทธิรวม (409) หรือมีรายการที่ข้อมูลบัญชีไม่ครบ (422 พร้อมรายการปัญหา)
+func (h *PayrollHandler) exportPayments(c *gin.Context, w bankfile.Writer, pick func(payroll.Payments) bankfile.Batch) {
+	p, ok := h.payments(c)
+	if !ok {
+		return
+	}
+	if !p.Reconciled() {
+		bw, ok := h.bankWriter(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "payment files do not reconcile to the run net pay", "summary": p.Summary(bw.Format())})
+		return
+	}
+	batch := pick(p)
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf, batch); err != nil {
+		var be *bankfile.BatchError
+		if errors.As(err, &be) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "problems": be.Problems})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write payment file"})
+		return
+	}
+
+	name := fmt.Sprintf("payroll%d", p.RunID)
+	if _, ok := w.(bankfile.PromptPay); ok {
+		name += "-promptpay"
+	}
+	contentType := "text/plain; charset=tis-620"
+	switch w.Extension() {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "xml":
+		contentType = "application/xml; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", name, w.Extension()))
+	c.Header("X-Bank-File-Format", w.Format())
+	c.Header("X-Control-Count", strconv.Itoa(len(batch.Credits)))
+	c.Header("X-Control-Total", batch.Total().String())
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// GET /api/v1/payroll/items/:id/trace
+// ขั้นตอนการคำนวณที่บันทึกไว้ตอนคำนวณ run (การแก้ item ภายหลังไม่ถูกบันทึกใน trace)
+func (h *PayrollHandler) ItemTrace(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	item, err := h.Store.GetPayrollItem(uint(id))
+	if err != nil || item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+	if len(item.Trace) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no trace for this item; recalculate the run"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"itemId":     item.ID,
+		"runId":      item.RunID,
+		"employeeId": item.EmployeeID,
+		"trace":      item.Trace,
+	})
 }
 
 // POST /api/v1/payroll/items/:id
+// body: {"lines":[...]} แทนที่บรรทัดทั้งชุด หรือ {"taxWithheld":..,"sso":..,"pvd":..} แบบเดิม
+// netPay และยอดรวมอื่น ๆ คำนวณจาก lines เสมอ ยอดของแต่ละบรรทัดต้องมากกว่าศูนย์ (ภาษี/SSO/PVD เป็นศูนย์ได้)
+// และต้องส่งบรรทัด LOAN/GARNISH กลับมาตามเดิม (409 ถ้าเพิ่ม ลบ หรือแก้)
 func (h *PayrollHandler) UpdatePayrollItem(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
 	var body struct {
-		TaxWithheld float64 `json:"taxWithheld"`
-		SSO         float64 `json:"sso"`
-		PVD         float64 `json:"pvd"`
-		NetPay      float64 `json:"netPay"`
+		TaxWithheld *money.Amount        `json:"taxWithheld"`
+		SSO         *money.Amount        `json:"sso"`
+		PVD         *money.Amount        `json:"pvd"`
+		Lines       []models.PayrollLine `json:"lines"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
@@ -240,100 +719,87 @@ func (h *PayrollHandler) UpdatePayrollItem(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
 		return
 	}
-
-	// Update fields
-	item.TaxWithheld = round2(body.TaxWithheld)
-	item.SSO = round2(body.SSO)
-	item.PVD = round2(body.PVD)
-	item.NetPay = round2(body.NetPay)
-
-	// Save
-	if err := h.Store.UpdatePayrollItem(item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+	// แก้ได้เฉพาะเมื่อโหลด run ได้และ run ยังแก้ไขได้ (หา run ไม่พบ = ไม่อนุญาต)
+	run, err := h.Store.GetPayrollRun(item.RunID)
+	if err != nil || run == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
 		return
 	}
-
-	c.JSON(http.StatusOK, item)
-}
-
-// ------------------ helpers ------------------
-
-// monthStartEnd คืนวันที่เริ่มและสิ้นสุดของเดือนนั้น ๆ
-func monthStartEnd(year, month int) (time.Time, time.Time) {
-	loc := time.UTC
-	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
-	end := start.AddDate(0, 1, -1)
-	return start, end
-}
-
-// overlapDays: คำนวณจำนวนวันทำงานที่ซ้อนกับช่วง ps..pe (นับแบบรวมปลายทั้งสองด้าน)
-func overlapDays(hire time.Time, end *time.Time, ps, pe time.Time) (worked, total int) {
-	total = int(pe.Sub(ps).Hours()/24) + 1
-	if total < 0 {
-		total = 0
+	if !run.Editable() {
+		c.JSON(http.StatusConflict, gin.H{"error": "run is " + run.Status + "; items can no longer be changed"})
+		return
 	}
 
-	start := maxTime(ps, hire)
-	var last time.Time
-	if end != nil {
-		if end.Before(ps) {
-			return 0, total
+	if body.Lines != nil {
+		for _, l := range body.Lines {
+			if strings.TrimSpace(l.Code) == "" || !validLineCategory(l.Category) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "each line needs a code and category earning|deduction|employer|info"})
+				return
+			}
+			if l.Amount < 0 || (l.Amount == 0 && !statutoryLine(l.Code)) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "line amounts must be greater than zero", "code": l.Code})
+				return
+			}
 		}
-		if end.Before(pe) {
-			last = *end
-		} else {
-			last = pe
+		// บรรทัดผ่อนเงินกู้/อายัดถูกบันทึกเข้าเงินกู้และคำสั่งอายัดตอนจ่าย แก้ด้วยมือไม่ได้ (คำนวณ run ใหม่แทน)
+		if !slices.Equal(postedLines(item.Lines), postedLines(body.Lines)) {
+			c.JSON(http.StatusConflict, gin.H{"error": "LOAN and GARNISH lines cannot be added, removed or changed; recalculate the run instead"})
+			return
 		}
+		item.Lines = body.Lines
 	} else {
-		last = pe
+		// แก้ยอดภาษีแบบเดิม: ตั้งยอดไว้ที่บรรทัด TAX และยกเลิกบรรทัดหักเพิ่ม
+		if body.TaxWithheld != nil {
+			item.SetLine(models.CodeTax, "Tax Withheld", models.LineDeduction, *body.TaxWithheld)
+			item.RemoveLine(models.CodeTaxExtra)
+		}
+		if body.SSO != nil {
+			item.SetLine(models.CodeSSO, "Social Security (SSO)", models.LineDeduction, *body.SSO)
+		}
+		if body.PVD != nil {
+			item.SetLine(models.CodePVD, "Provident Fund (PVD)", models.LineDeduction, *body.PVD)
+		}
 	}
+	item.SyncTotals()
 
-	w := int(last.Sub(start).Hours()/24) + 1
-	if w < 0 {
-		w = 0
+	// Save
+	if err := h.Store.UpdatePayrollItem(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
 	}
-	return w, total
-}
 
-func maxTime(a, b time.Time) time.Time {
-	if a.After(b) {
-		return a
-	}
-	return b
+	c.JSON(http.StatusOK, item)
 }
 
-func round2(n float64) float64 {
-	return math.Round(n*100) / 100
+// statutoryLine บรรทัดภาษี/SSO/PVD ที่ engine เขียนไว้เสมอแม้ยอดเป็นศูนย์
+func statutoryLine(code string) bool {
+	return code == models.CodeTax || code == models.CodeSSO || code == models.CodePVD
 }
 
-// calculateSSO คำนวณประกันสังคม
-// อัตรา 5% ของเงินเดือน แต่สูงสุดไม่เกิน 750 บาท (ฐาน 15,000 บาท)
-func calculateSSO(grossSalary float64) float64 {
-	maxBase := 15000.0
-	rate := 0.05
-
-	base := grossSalary
-	if base > maxBase {
-		base = maxBase
+// postedLines บรรทัดผ่อนเงินกู้/อายัด (รวมบรรทัดที่อ้างถึงเงินกู้หรือคำสั่งอายัด) เรียงเพื่อเทียบกันได้
+func postedLines(lines []models.PayrollLine) []string {
+	var keys []string
+	for _, l := range lines {
+		if l.Code != models.CodeLoan && l.Code != models.CodeGarnish && l.RefLoanID == nil && l.RefGarnishmentID == nil {
+			continue
+		}
+		var loan, garnish uint
+		if l.RefLoanID != nil {
+			loan = *l.RefLoanID
+		}
+		if l.RefGarnishmentID != nil {
+			garnish = *l.RefGarnishmentID
+		}
+		keys = append(keys, fmt.Sprintf("%s/%d/%d/%s/%d", l.Code, loan, garnish, l.Category, l.Amount))
 	}
-
-	return base * rate
+	slices.Sort(keys)
+	return keys
 }
 
-// calculateTax คำนวณภาษีหัก ณ ที่จ่าย
-// ใช้อัตราแบบง่าย: 5% ของรายได้หลังหักค่าลดหย่อน
-func calculateTax(taxableIncome float64) float64 {
-	if taxableIncome <= 0 {
-		return 0
+func validLineCategory(cat string) bool {
+	switch cat {
+	case models.LineEarning, models.LineDeduction, models.LineEmployer, models.LineInfo:
+		return true
 	}
-
-	// อัตราภาษีแบบง่าย
-	// รายได้ถึง 150,000 บาท/ปี (12,500/เดือน) = ยกเว้น
-	monthlyExemption := 12500.0
-	if taxableIncome <= monthlyExemption {
-		return 0
-	}
-
-	// เกิน 12,500 บาท คิด 5%
-	return (taxableIncome - monthlyExemption) * 0.05
+	return false
 }
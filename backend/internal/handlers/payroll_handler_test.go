@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// แก้บรรทัดของ item: ยอดต้องมากกว่าศูนย์ และบรรทัดผ่อนเงินกู้/อายัดที่อ้างถึงเงินกู้หรือคำสั่งอายัดแก้ด้วยมือไม่ได้
+func TestUpdatePayrollItemLines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	loanID, garnishID := uint(7), uint(9)
+	salary := `{"code":"SALARY","category":"earning","amount":"30000.00"}`
+	tax := `{"code":"TAX","category":"deduction","amount":"0.00"}`
+	loan := `{"code":"LOAN","category":"deduction","amount":"1000.00","refLoanId":7}`
+	garnish := `{"code":"GARNISH","category":"deduction","amount":"2000.00","refGarnishmentId":9}`
+	cases := []struct {
+		name  string
+		lines []string
+		want  int
+		net   string
+	}{
+		{"unchanged posted lines", []string{salary, tax, loan, garnish, `{"code":"OT","category":"earning","amount":"500.00"}`}, http.StatusOK, "27500.00"},
+		{"zero earning", []string{salary, tax, loan, garnish, `{"code":"OT","category":"earning","amount":"0"}`}, http.StatusBadRequest, ""},
+		{"negative deduction", []string{salary, tax, loan, garnish, `{"code":"ADV","category":"deduction","amount":"-100.00"}`}, http.StatusBadRequest, ""},
+		{"loan removed", []string{salary, tax, garnish}, http.StatusConflict, ""},
+		{"loan amount changed", []string{salary, tax, garnish, `{"code":"LOAN","category":"deduction","amount":"500.00","refLoanId":7}`}, http.StatusConflict, ""},
+		{"garnishment added", []string{salary, tax, loan, garnish, `{"code":"GARNISH","category":"deduction","amount":"100.00","refGarnishmentId":10}`}, http.StatusConflict, ""},
+		{"loan reference moved", []string{salary, tax, garnish, `{"code":"ADV","category":"deduction","amount":"1000.00","refLoanId":7}`}, http.StatusConflict, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := storage.New()
+			run := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 3, Type: models.RunRegular, Status: models.RunCalculated}
+			if err := st.CreatePayrollRun(run); err != nil {
+				t.Fatal(err)
+			}
+			item := &models.PayrollItem{RunID: run.ID, EmployeeID: 1, Lines: []models.PayrollLine{
+				{Code: "SALARY", Category: models.LineEarning, Amount: money.FromBaht(30000)},
+				{Code: models.CodeTax, Category: models.LineDeduction},
+				{Code: models.CodeLoan, Category: models.LineDeduction, Amount: money.FromBaht(1000), RefLoanID: &loanID},
+				{Code: models.CodeGarnish, Category: models.LineDeduction, Amount: money.FromBaht(2000), RefGarnishmentID: &garnishID},
+			}}
+			item.SyncTotals()
+			if err := st.SavePayrollItem(item); err != nil {
+				t.Fatal(err)
+			}
+
+			h := NewPayrollHandler(st)
+			r := gin.New()
+			r.POST("/payroll/items/:id", h.UpdatePayrollItem)
+			body := `{"lines":[` + strings.Join(tc.lines, ",") + `]}`
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/payroll/items/%d", item.ID), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tc.want, w.Body)
+			}
+			saved, err := st.GetPayrollItem(item.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want != http.StatusOK {
+				if len(saved.Lines) != 4 {
+					t.Fatalf("rejected edit changed the item: %d lines", len(saved.Lines))
+				}
+				return
+			}
+			var got models.PayrollItem
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.NetPay.String() != tc.net || saved.NetPay != got.NetPay {
+				t.Fatalf("net pay = %s (saved %s), want %s", got.NetPay, saved.NetPay, tc.net)
+			}
+		})
+	}
+}
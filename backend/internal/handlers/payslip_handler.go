@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"time"
 
+	"backend/internal/models"
+	"backend/internal/payroll"
 	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -70,7 +72,7 @@ func (h *PayslipHandler) ListByRun(c *gin.Context) {
 		// Calculate period dates
 		periodStart := time.Date(run.PeriodYear, time.Month(run.PeriodMonth), 1, 0, 0, 0, 0, time.UTC)
 		periodEnd := periodStart.AddDate(0, 1, -1)
-		payDate := periodEnd.AddDate(0, 0, 5) // Pay 5 days after period end
+		payDate := payroll.PayDate(*run)
 
 		payslip := map[string]interface{}{
 			"id":         item.ID,
@@ -96,16 +98,11 @@ func (h *PayslipHandler) ListByRun(c *gin.Context) {
 				"end":     periodEnd.Format("2006-01-02"),
 				"payDate": payDate.Format("2006-01-02"),
 			},
-			"earnings": []map[string]interface{}{
-				{"name": "Base Salary", "amount": item.BaseSalary},
-			},
-			"deductions": []map[string]interface{}{
-				{"name": "Tax Withheld", "amount": item.TaxWithheld},
-				{"name": "Social Security (SSO)", "amount": item.SSO},
-				{"name": "Provident Fund (PVD)", "amount": item.PVD},
-			},
-			"netPay": item.NetPay,
-			"notes":  fmt.Sprintf("Payslip for period %d/%d", run.PeriodMonth, run.PeriodYear),
+			"earnings":   payslipLines(item, models.LineEarning),
+			"deductions": payslipLines(item, models.LineDeduction),
+			"employer":   payslipLines(item, models.LineEmployer),
+			"netPay":     item.NetPay,
+			"notes":      fmt.Sprintf("Payslip for period %d/%d", run.PeriodMonth, run.PeriodYear),
 		}
 		payslips = append(payslips, payslip)
 	}
@@ -134,31 +131,10 @@ func (h *PayslipHandler) GetByEmployee(c *gin.Context) {
 	}
 
 	// Find the specific employee's item
-	var targetItem *struct {
-		ID          uint
-		BaseSalary  float64
-		TaxWithheld float64
-		SSO         float64
-		PVD         float64
-		NetPay      float64
-	}
-	for _, item := range items {
-		if item.EmployeeID == uint(empID) {
-			targetItem = &struct {
-				ID          uint
-				BaseSalary  float64
-				TaxWithheld float64
-				SSO         float64
-				PVD         float64
-				NetPay      float64
-			}{
-				ID:          item.ID,
-				BaseSalary:  item.BaseSalary,
-				TaxWithheld: item.TaxWithheld,
-				SSO:         item.SSO,
-				PVD:         item.PVD,
-				NetPay:      item.NetPay,
-			}
+	var targetItem *models.PayrollItem
+	for i := range items {
+		if items[i].EmployeeID == uint(empID) {
+			targetItem = &items[i]
 			break
 		}
 	}
@@ -212,7 +188,7 @@ func (h *PayslipHandler) GetByEmployee(c *gin.Context) {
 	// Calculate period dates
 	periodStart := time.Date(run.PeriodYear, time.Month(run.PeriodMonth), 1, 0, 0, 0, 0, time.UTC)
 	periodEnd := periodStart.AddDate(0, 1, -1)
-	payDate := periodEnd.AddDate(0, 0, 5)
+	payDate := payroll.PayDate(*run)
 
 	payslip := map[string]interface{}{
 		"id":         targetItem.ID,
@@ -238,16 +214,11 @@ func (h *PayslipHandler) GetByEmployee(c *gin.Context) {
 			"end":     periodEnd.Format("2006-01-02"),
 			"payDate": payDate.Format("2006-01-02"),
 		},
-		"earnings": []map[string]interface{}{
-			{"name": "Base Salary", "amount": targetItem.BaseSalary},
-		},
-		"deductions": []map[string]interface{}{
-			{"name": "Tax Withheld", "amount": targetItem.TaxWithheld},
-			{"name": "Social Security (SSO)", "amount": targetItem.SSO},
-			{"name": "Provident Fund (PVD)", "amount": targetItem.PVD},
-		},
-		"netPay": targetItem.NetPay,
-		"notes":  fmt.Sprintf("Payslip for period %d/%d", run.PeriodMonth, run.PeriodYear),
+		"earnings":   payslipLines(*targetItem, models.LineEarning),
+		"deductions": payslipLines(*targetItem, models.LineDeduction),
+		"employer":   payslipLines(*targetItem, models.LineEmployer),
+		"netPay":     targetItem.NetPay,
+		"notes":      fmt.Sprintf("Payslip for period %d/%d", run.PeriodMonth, run.PeriodYear),
 		"ytd": map[string]interface{}{
 			"earnings":   0,
 			"deductions": 0,
@@ -256,3 +227,21 @@ func (h *PayslipHandler) GetByEmployee(c *gin.Context) {
 
 	c.JSON(http.StatusOK, payslip)
 }
+
+// payslipLines บรรทัดรายการของสลิปตามหมวด (earning / deduction / employer)
+func payslipLines(item models.PayrollItem, category string) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(item.Lines))
+	for _, l := range item.Lines {
+		if l.Category != category {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"code":     l.Code,
+			"name":     l.Description,
+			"amount":   l.Amount,
+			"quantity": l.Quantity,
+			"rate":     l.Rate,
+		})
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateHandler จัดการตารางอัตราตามกฎหมาย (SSO, ภาษี) ที่มีวันที่มีผล
+type RateHandler struct {
+	Store storage.Port
+}
+
+func NewRateHandler(store storage.Port) *RateHandler {
+	return &RateHandler{Store: store}
+}
+
+// GET /api/v1/rates
+// คืนตารางที่บันทึกไว้ พร้อมตารางที่ติดมากับระบบ
+func (h *RateHandler) List(c *gin.Context) {
+	stored, err := h.Store.ListStatutoryRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"stored":  stored,
+		"builtin": payroll.BuiltinRates,
+	})
+}
+
+// GET /api/v1/rates/effective?date=2025-10-01
+// คืนกติกาที่จะถูกใช้กับงวดที่เริ่มวันที่กำหนด
+func (h *RateHandler) Effective(c *gin.Context) {
+	at := time.Now().UTC()
+	if d := c.Query("date"); d != "" {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date; use YYYY-MM-DD"})
+			return
+		}
+		at = t
+	}
+
+	stored, err := h.Store.ListStatutoryRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, payroll.RulesFor(stored, at))
+}
+
+// POST /api/v1/rates
+func (h *RateHandler) Create(c *gin.Context) {
+	var req struct {
+		EffectiveFrom     string              `json:"effectiveFrom" binding:"required"`
+		SSOMinBase        money.Amount        `json:"ssoMinBase"`
+		SSOMaxBase        money.Amount        `json:"ssoMaxBase" binding:"required"`
+		SSOEmployeeRate   float64             `json:"ssoEmployeeRate"`
+		SSOEmployerRate   float64             `json:"ssoEmployerRate"`
+		PersonalAllowance money.Amount        `json:"personalAllowance"`
+		ExpenseRate       float64             `json:"expenseRate"`
+		ExpenseCap        money.Amount        `json:"expenseCap"`
+		TaxBrackets       []models.TaxBracket `json:"taxBrackets"`
+		Note              string              `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effectiveFrom; use YYYY-MM-DD"})
+		return
+	}
+	if req.SSOMinBase < 0 || req.SSOMaxBase < req.SSOMinBase {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ssoMaxBase must be >= ssoMinBase >= 0"})
+		return
+	}
+	for _, r := range []float64{req.SSOEmployeeRate, req.SSOEmployerRate, req.ExpenseRate} {
+		if r < 0 || r > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rates must be between 0 and 1"})
+			return
+		}
+	}
+	if err := validateBrackets(req.TaxBrackets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate := &models.StatutoryRate{
+		EffectiveFrom:     from,
+		SSOMinBase:        req.SSOMinBase,
+		SSOMaxBase:        req.SSOMaxBase,
+		SSOEmployeeRate:   req.SSOEmployeeRate,
+		SSOEmployerRate:   req.SSOEmployerRate,
+		PersonalAllowance: req.PersonalAllowance,
+		ExpenseRate:       req.ExpenseRate,
+		ExpenseCap:        req.ExpenseCap,
+		TaxBrackets:       req.TaxBrackets,
+		Note:              req.Note,
+	}
+	if err := h.Store.CreateStatutoryRate(rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "create rate table failed", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rate)
+}
+
+// validateBrackets ขั้นบันไดต้องเรียงเพดานจากน้อยไปมาก และขั้นสุดท้ายไม่มีเพดาน (0)
+// ถ้าไม่ส่งมาเลยจะใช้ขั้นบันไดมาตรฐาน
+func validateBrackets(bs []models.TaxBracket) error {
+	if len(bs) == 0 {
+		return nil
+	}
+	prev := money.Zero
+	for i, b := range bs {
+		if b.Rate < 0 || b.Rate > 1 {
+			return errBadBrackets
+		}
+		last := i == len(bs)-1
+		if last != (b.Upper == 0) {
+			return errBadBrackets
+		}
+		if !last && b.Upper <= prev {
+			return errBadBrackets
+		}
+		prev = b.Upper
+	}
+	return nil
+}
+
+var errBadBrackets = errors.New("taxBrackets must have ascending limits and an open-ended last bracket (upper = 0)")
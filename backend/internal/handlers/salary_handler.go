@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SalaryHandler จัดการประวัติเงินเดือนตามวันที่มีผลของพนักงาน
+type SalaryHandler struct {
+	Store storage.Port
+}
+
+func NewSalaryHandler(store storage.Port) *SalaryHandler {
+	return &SalaryHandler{Store: store}
+}
+
+// GET /api/v1/employees/:id/salary
+// timeline ของเงินเดือนแต่ละอัตราพร้อมช่วงที่มีผล และเงินเดือน ณ วันนี้
+func (h *SalaryHandler) Timeline(c *gin.Context) {
+	emp, ok := h.loadEmployee(c)
+	if !ok {
+		return
+	}
+	records, err := h.Store.ListSalaryRecords(emp.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"employeeId": emp.ID,
+		"current":    payroll.SalaryAt(records, emp.BaseSalary, time.Now()),
+		"timeline":   payroll.SalaryTimeline(records),
+	})
+}
+
+// POST /api/v1/employees/:id/salary
+// บันทึกเงินเดือนใหม่ที่มีผลตั้งแต่ effectiveFrom (ย้อนหลังหรือล่วงหน้าได้) โดยไม่แก้ประวัติเดิม
+// การแก้อัตราที่บันทึกผิดให้ใช้ reason = correction ที่วันมีผลเดิม
+func (h *SalaryHandler) Create(c *gin.Context) {
+	emp, ok := h.loadEmployee(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		BaseSalary    *money.Amount `json:"baseSalary" binding:"required"`
+		EffectiveFrom string        `json:"effectiveFrom" binding:"required"`
+		Reason        string        `json:"reason" binding:"required"`
+		Note          string        `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	if *req.BaseSalary < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "baseSalary must be >= 0"})
+		return
+	}
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if !models.ValidSalaryReason(reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be one of " + strings.Join(models.SalaryReasons, ", ")})
+		return
+	}
+	from, err := parseDate(req.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effectiveFrom; use YYYY-MM-DD"})
+		return
+	}
+	from = dateOnly(from)
+	hired := dateOnly(emp.HiredAt)
+	if from.Before(hired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effectiveFrom must not be before hiredAt"})
+		return
+	}
+
+	records, err := h.Store.ListSalaryRecords(emp.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "storage error"})
+		return
+	}
+	by := c.GetString("email")
+
+	// พนักงานที่ยังไม่มีประวัติ (ข้อมูลก่อนมีระบบประวัติเงินเดือน) บันทึกเงินเดือนเดิมเป็นอัตราแรกเข้าก่อน
+	// เพื่อไม่ให้อัตราใหม่มีผลย้อนไปถึงวันเริ่มงาน
+	if len(records) == 0 && from.After(hired) {
+		hire := &models.SalaryRecord{EmployeeID: emp.ID, EffectiveFrom: hired, BaseSalary: emp.BaseSalary, Reason: models.SalaryHire, CreatedBy: by}
+		if err := h.Store.CreateSalaryRecord(hire); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save salary"})
+			return
+		}
+		records = append(records, *hire)
+	}
+
+	rec := &models.SalaryRecord{
+		EmployeeID:    emp.ID,
+		EffectiveFrom: from,
+		BaseSalary:    *req.BaseSalary,
+		Reason:        reason,
+		Note:          strings.TrimSpace(req.Note),
+		CreatedBy:     by,
+	}
+	if err := h.Store.CreateSalaryRecord(rec); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save salary"})
+		return
+	}
+	records = append(records, *rec)
+
+	// Employee.BaseSalary เก็บเงินเดือน ณ วันนี้ (อัตราที่มีผลล่วงหน้าจะ sync เมื่อบันทึกครั้งถัดไป
+	// ส่วนการคำนวณเงินเดือนอ่านจากประวัติโดยตรงเสมอ)
+	if current := payroll.SalaryAt(records, emp.BaseSalary, time.Now()); current != emp.BaseSalary {
+		emp.BaseSalary = current
+		if err := h.Store.UpdateEmployee(emp); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update employee"})
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+func (h *SalaryHandler) loadEmployee(c *gin.Context) (*models.Employee, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	emp, err := h.Store.GetEmployee(uint(id))
+	if err != nil || emp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return nil, false
+	}
+	return emp, true
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/payroll"
+	"backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TerminationHandler จัดการการพ้นสภาพและเงินที่ต้องจ่ายเมื่อพ้นสภาพ
+type TerminationHandler struct {
+	Store   storage.Port
+	Payroll *payroll.Service
+}
+
+func NewTerminationHandler(store storage.Port) *TerminationHandler {
+	return &TerminationHandler{Store: store, Payroll: payroll.NewService(store)}
+}
+
+// GET /api/v1/employees/:id/settlement?date=YYYY-MM-DD&reason=layoff&noticeDate=YYYY-MM-DD
+// ประมาณการเงินที่ต้องจ่ายหากพ้นสภาพ ณ วันที่ date (ไม่บันทึกอะไร; date ว่าง = วันนี้)
+func (h *TerminationHandler) Settlement(c *gin.Context) {
+	emp, ok := h.loadEmployee(c)
+	if !ok {
+		return
+	}
+	date := time.Now()
+	if s := c.Query("date"); s != "" {
+		d, err := parseDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date; use YYYY-MM-DD"})
+			return
+		}
+		date = d
+	} else if emp.TerminatedAt != nil {
+		date = *emp.TerminatedAt
+	}
+	reason := strings.ToLower(strings.TrimSpace(c.Query("reason")))
+	if reason == "" {
+		reason = emp.TerminationReason
+	}
+	var notice *time.Time
+	if s := c.Query("noticeDate"); s != "" {
+		d, err := parseDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid noticeDate; use YYYY-MM-DD"})
+			return
+		}
+		notice = &d
+	} else if emp.NoticeDate != nil {
+		notice = emp.NoticeDate
+	}
+
+	st, err := h.Payroll.Settlement(*emp, date, reason, notice)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// POST /api/v1/employees/:id/terminate
+// body: {"date":"2026-03-20","reason":"layoff","noticeDate":"2026-03-10","note":"..."}
+// บันทึกวันที่/เหตุที่พ้นสภาพ แล้วสร้าง run ประเภท termination ที่คำนวณเงินชดเชยไว้แล้ว (สถานะ calculated)
+func (h *TerminationHandler) Terminate(c *gin.Context) {
+	emp, ok := h.loadEmployee(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Date       string  `json:"date" binding:"required"`
+		Reason     string  `json:"reason" binding:"required"`
+		NoticeDate *string `json:"noticeDate"`
+		Note       string  `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+	date, err := parseDate(req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date; use YYYY-MM-DD"})
+		return
+	}
+	notice, err := parseOptionalDate(req.NoticeDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "noticeDate: " + err.Error()})
+		return
+	}
+
+	st, run, err := h.Payroll.Terminate(emp.ID, payroll.TerminateRequest{
+		Date:       date,
+		Reason:     strings.ToLower(strings.TrimSpace(req.Reason)),
+		NoticeDate: notice,
+		Note:       strings.TrimSpace(req.Note),
+	}, c.GetString("email"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"settlement": st, "run": run})
+}
+
+func (h *TerminationHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, payroll.ErrEmployeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+	case errors.Is(err, payroll.ErrAlreadyTerminated):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee is already terminated"})
+	case errors.Is(err, payroll.ErrInvalidTermination):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be one of " + strings.Join(models.TerminationReasons, ", ") +
+			"; date must not be before hiredAt and noticeDate must not be after date"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "settlement failed"})
+	}
+}
+
+func (h *TerminationHandler) loadEmployee(c *gin.Context) (*models.Employee, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	emp, err := h.Store.GetEmployee(uint(id))
+	if err != nil || emp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return nil, false
+	}
+	return emp, true
+}
@@ -0,0 +1,164 @@
+// Package leave คำนวณสิทธิวันลา ยอดใช้ และยอดคงเหลือตามนโยบายของแต่ละประเภทการลา
+package leave
+
+import (
+	"time"
+
+	"backend/internal/models"
+)
+
+// DefaultPolicies นโยบายที่ติดมากับระบบ ใช้เมื่อยังไม่ได้บันทึกนโยบายของประเภทนั้น
+// (ลาพักร้อน 6 วันเมื่อทำงานครบ 1 ปี, ลาป่วยได้รับค่าจ้าง 30 วัน, ลากิจ 3 วัน, ลาคลอด 98 วัน)
+var DefaultPolicies = []models.LeavePolicy{
+	{Type: models.LeaveAnnual, Tiers: []models.AccrualTier{{MinServiceMonths: 12, Days: 6}}, MaxCarryOver: 6, OverBalance: models.OverBalanceReject},
+	{Type: models.LeaveSick, Tiers: []models.AccrualTier{{MinServiceMonths: 0, Days: 30}}, OverBalance: models.OverBalanceUnpaid},
+	{Type: models.LeavePersonal, Tiers: []models.AccrualTier{{MinServiceMonths: 0, Days: 3}}, OverBalance: models.OverBalanceUnpaid},
+	{Type: models.LeaveMaternity, Tiers: []models.AccrualTier{{MinServiceMonths: 0, Days: 98}}, OverBalance: models.OverBalanceReject},
+}
+
+// Policies รวมนโยบายที่บันทึกไว้เข้ากับค่าเริ่มต้น (ที่บันทึกไว้ชนะ) เรียงตาม models.LeaveTypes
+func Policies(stored []models.LeavePolicy) []models.LeavePolicy {
+	byType := make(map[string]models.LeavePolicy)
+	for _, p := range DefaultPolicies {
+		byType[p.Type] = p
+	}
+	for _, p := range stored {
+		byType[p.Type] = p
+	}
+	out := make([]models.LeavePolicy, 0, len(byType))
+	for _, t := range models.LeaveTypes {
+		if p, ok := byType[t]; ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// PolicyFor หานโยบายของประเภทการลา (ลาไม่รับค่าจ้างไม่มีนโยบาย/ไม่จำกัดสิทธิ)
+func PolicyFor(stored []models.LeavePolicy, leaveType string) (models.LeavePolicy, bool) {
+	for _, p := range Policies(stored) {
+		if p.Type == leaveType {
+			return p, true
+		}
+	}
+	return models.LeavePolicy{}, false
+}
+
+// Balance ยอดสิทธิการลาของพนักงานหนึ่งประเภทในหนึ่งปี
+type Balance struct {
+	Type        string `json:"type"`
+	Year        int    `json:"year"`
+	Entitled    int    `json:"entitled"`
+	CarriedOver int    `json:"carriedOver"`
+	Used        int    `json:"used"`
+	Remaining   int    `json:"remaining"`
+}
+
+// ComputeBalance คำนวณยอดคงเหลือ ณ วันที่ asOf ของปี asOf.Year()
+// ยอดยกมาคำนวณไล่ตั้งแต่ปีที่เริ่มงาน: คงเหลือสิ้นปี (ไม่ติดลบ) ยกไปได้ไม่เกิน MaxCarryOver
+func ComputeBalance(p models.LeavePolicy, e models.Employee, leaves []models.Leave, asOf time.Time) Balance {
+	year := asOf.Year()
+	carry := 0
+	for y := e.HiredAt.Year(); y < year; y++ {
+		yearEnd := time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC)
+		rem := p.EntitledDays(ServiceMonths(e.HiredAt, yearEnd)) + carry - UsedDays(leaves, e.ID, p.Type, y)
+		carry = clamp(rem, 0, p.MaxCarryOver)
+	}
+
+	b := Balance{
+		Type:        p.Type,
+		Year:        year,
+		Entitled:    p.EntitledDays(ServiceMonths(e.HiredAt, asOf)),
+		CarriedOver: carry,
+		Used:        UsedDays(leaves, e.ID, p.Type, year),
+	}
+	b.Remaining = b.Entitled + b.CarriedOver - b.Used
+	return b
+}
+
+// UsedDays จำนวนวันทำงานที่ใช้สิทธิไปแล้วในปี (นับเฉพาะใบลาที่อนุมัติ ไม่นับส่วนที่เป็นลาไม่รับค่าจ้าง)
+func UsedDays(leaves []models.Leave, empID uint, leaveType string, year int) int {
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	n := 0
+	for _, lv := range leaves {
+		if lv.EmployeeID != empID || lv.Type != leaveType || !lv.Approved() || lv.IsUnpaid() {
+			continue
+		}
+		n += WorkingDays(maxTime(from, dateOnly(lv.StartDate)), minTime(to, dateOnly(lv.EndDate)))
+	}
+	return n
+}
+
+// ServiceMonths อายุงานเป็นเดือนเต็มจากวันเริ่มงานถึง asOf
+func ServiceMonths(hired, asOf time.Time) int {
+	if asOf.Before(hired) {
+		return 0
+	}
+	months := (asOf.Year()-hired.Year())*12 + int(asOf.Month()) - int(hired.Month())
+	if asOf.Day() < hired.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// WorkingDays จำนวนวันจันทร์–ศุกร์ในช่วง from..to (inclusive)
+func WorkingDays(from, to time.Time) int {
+	n := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			n++
+		}
+	}
+	return n
+}
+
+// SplitAt แบ่งช่วงลาเมื่อใช้สิทธิได้เพียง paidDays วันทำงาน
+// คืนวันสุดท้ายของส่วนที่ได้รับค่าจ้าง (ok = false ถ้าไม่มีส่วนที่ได้รับค่าจ้าง)
+func SplitAt(start, end time.Time, paidDays int) (lastPaid time.Time, ok bool) {
+	if paidDays <= 0 {
+		return time.Time{}, false
+	}
+	n := 0
+	for d := dateOnly(start); !d.After(dateOnly(end)); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			n++
+			if n == paidDays {
+				return d, true
+			}
+		}
+	}
+	return dateOnly(end), true
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
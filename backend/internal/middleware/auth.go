@@ -102,3 +102,17 @@ func parseToken(tokenString string) (*Claims, error) {
 	}
 	return claims, nil
 }
+
+// RequireRole อนุญาตเฉพาะ role ที่กำหนด (ต้องใช้ต่อจาก AuthRequired)
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if strings.EqualFold(r, role) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+	}
+}
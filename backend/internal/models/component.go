@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// ประเภทของรายการเงินได้/เงินหัก
+const (
+	ComponentEarning   = "earning"
+	ComponentDeduction = "deduction"
+)
+
+// PayComponent รายการเงินได้/เงินหักประจำของพนักงาน (ค่าตำแหน่ง, ค่าที่พัก, ค่าเดินทาง,
+// ค่าโทรศัพท์, ค่าสมาชิกสหภาพ, ค่าชุดฟอร์ม ฯลฯ) มีผลระหว่าง StartDate ถึง EndDate
+type PayComponent struct {
+	ID         uint         `gorm:"primaryKey;column:id" json:"id"`
+	EmployeeID uint         `gorm:"column:employee_id;index;not null" json:"employeeId"`
+	Code       string       `gorm:"column:code;not null" json:"code"`
+	Name       string       `gorm:"column:name;not null" json:"name"`
+	Kind       string       `gorm:"column:kind;not null" json:"kind"` // earning | deduction
+	Amount     money.Amount `gorm:"column:amount;not null" json:"amount"`
+	StartDate  time.Time    `gorm:"column:start_date;not null" json:"startDate"`
+	EndDate    *time.Time   `gorm:"column:end_date" json:"endDate"`
+	Taxable    bool         `gorm:"column:taxable;default:true" json:"taxable"`
+	SSOable    bool         `gorm:"column:sso_able;default:false" json:"ssoAble"`
+	CreatedAt  time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (PayComponent) TableName() string { return "pay_components" }
+
+// ActiveBetween รายการมีผลอย่างน้อยหนึ่งวันในช่วง from..to หรือไม่
+func (p PayComponent) ActiveBetween(from, to time.Time) bool {
+	if p.StartDate.After(to) {
+		return false
+	}
+	return p.EndDate == nil || !p.EndDate.Before(from)
+}
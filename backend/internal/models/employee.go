@@ -1,22 +1,100 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"backend/internal/money"
+)
 
 type Employee struct {
-	ID              uint       `gorm:"primaryKey;column:id" json:"id"`
-	EmpCode         string     `gorm:"column:emp_code;uniqueIndex;not null" json:"empCode"`
-	FirstName       string     `gorm:"column:first_name;not null" json:"firstName"`
-	LastName        string     `gorm:"column:last_name;not null" json:"lastName"`
-	Department      string     `gorm:"column:department" json:"department"`
-	Position        string     `gorm:"column:position" json:"position"`
-	BaseSalary      float64    `gorm:"column:base_salary;not null" json:"baseSalary"`
-	BankAccount     string     `gorm:"column:bank_account" json:"bankAccount"`
-	PVDRate         float64    `gorm:"column:pvd_rate;default:0.03" json:"pvdRate"`
-	WithholdingRate float64    `gorm:"column:withholding_rate;default:0" json:"withholdingRate"`
-	SSOEnabled      bool       `gorm:"column:sso_enabled;default:true" json:"ssoEnabled"`
-	Status          string     `gorm:"column:status;default:active" json:"status"`
-	HiredAt         time.Time  `gorm:"column:hired_at;default:current_date" json:"hiredAt"`
-	TerminatedAt    *time.Time `gorm:"column:terminated_at" json:"terminatedAt"`
+	ID              uint         `gorm:"primaryKey;column:id" json:"id"`
+	EmpCode         string       `gorm:"column:emp_code;uniqueIndex;not null" json:"empCode"`
+	FirstName       string       `gorm:"column:first_name;not null" json:"firstName"`
+	LastName        string       `gorm:"column:last_name;not null" json:"lastName"`
+	Department      string       `gorm:"column:department" json:"department"`
+	Position        string       `gorm:"column:position" json:"position"`
+	BaseSalary      money.Amount `gorm:"column:base_salary;not null" json:"baseSalary"`
+	BankAccount     string       `gorm:"column:bank_account" json:"bankAccount"`                    // เลขบัญชี (ตัวเลขเท่านั้น)
+	BankCode        string       `gorm:"column:bank_code" json:"bankCode,omitempty"`                // รหัสธนาคาร 3 หลักของบัญชี (ว่าง = ธนาคารเดียวกับบริษัท)
+	BankBranch      string       `gorm:"column:bank_branch" json:"bankBranch,omitempty"`            // รหัสสาขา 4 หลัก
+	BankAccountName string       `gorm:"column:bank_account_name" json:"bankAccountName,omitempty"` // ชื่อบัญชี (ว่าง = ชื่อ-นามสกุลพนักงาน)
+	PaymentMethod   string       `gorm:"column:payment_method;default:bank" json:"paymentMethod"`
+	PromptPayType   string       `gorm:"column:promptpay_type" json:"promptPayType,omitempty"`
+	PromptPayID     string       `gorm:"column:promptpay_id" json:"promptPayId,omitempty"` // เบอร์มือถือ 10 หลัก หรือเลขประจำตัวประชาชน 13 หลัก
+	PVDRate         float64      `gorm:"column:pvd_rate;default:0.03" json:"pvdRate"`
+	WithholdingRate float64      `gorm:"column:withholding_rate;default:0" json:"withholdingRate"`
+	SSOEnabled      bool         `gorm:"column:sso_enabled;default:true" json:"ssoEnabled"`
+	NationalID      string       `gorm:"column:national_id" json:"nationalId,omitempty"` // เลขประจำตัวประชาชน 13 หลัก (ใช้เป็นเลขประกันสังคม)
+	SSOBranch       string       `gorm:"column:sso_branch" json:"ssoBranch,omitempty"`   // ลำดับที่สาขาตามทะเบียนนายจ้าง 6 หลัก (ว่าง = สาขาตั้งต้น)
+	PayGroupID      *uint        `gorm:"column:pay_group_id;index" json:"payGroupId"`    // nil = กลุ่มรายเดือนตั้งต้น
+	Status          string       `gorm:"column:status;default:active" json:"status"`
+	HiredAt         time.Time    `gorm:"column:hired_at;default:current_date" json:"hiredAt"`
+	TerminatedAt    *time.Time   `gorm:"column:terminated_at" json:"terminatedAt"`
+
+	// ข้อมูลการพ้นสภาพ (บันทึกผ่าน POST /employees/:id/terminate)
+	TerminationReason string     `gorm:"column:termination_reason" json:"terminationReason,omitempty"`
+	NoticeDate        *time.Time `gorm:"column:notice_date;type:date" json:"noticeDate,omitempty"` // วันที่บอกกล่าวล่วงหน้า
+	TerminationNote   string     `gorm:"column:termination_note" json:"terminationNote,omitempty"`
+}
+
+// วิธีรับเงินเดือน
+const (
+	PaymentBank      = "bank"      // โอนเข้าบัญชีธนาคาร (ไฟล์ของธนาคารบริษัท)
+	PaymentPromptPay = "promptpay" // โอนผ่านพร้อมเพย์ (ไฟล์ PromptPay bulk)
+)
+
+// PaymentMethods วิธีรับเงินเดือนทั้งหมดที่ระบบรองรับ
+var PaymentMethods = []string{PaymentBank, PaymentPromptPay}
+
+// ประเภทหมายเลขพร้อมเพย์
+const (
+	PromptPayMobile     = "mobile"
+	PromptPayNationalID = "national_id"
+)
+
+// PromptPayTypes ประเภทหมายเลขพร้อมเพย์ที่รองรับ
+var PromptPayTypes = []string{PromptPayMobile, PromptPayNationalID}
+
+// PaidByPromptPay พนักงานรับเงินเดือนผ่านพร้อมเพย์
+func (e Employee) PaidByPromptPay() bool { return e.PaymentMethod == PaymentPromptPay }
+
+// ValidNationalID ตรวจเลขประจำตัวประชาชน 13 หลัก (ตัวเลขล้วน) และหลักตรวจสอบ
+// หลักที่ 13 = (11 - (ผลรวมหลักที่ 1-12 คูณน้ำหนัก 13..2) mod 11) mod 10
+func ValidNationalID(id string) bool {
+	if len(id) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		if i < 12 {
+			sum += int(id[i]-'0') * (13 - i)
+		}
+	}
+	return int(id[12]-'0') == (11-sum%11)%10
+}
+
+// เหตุที่พ้นสภาพ (กำหนดสิทธิค่าชดเชย ค่าบอกกล่าวล่วงหน้า และค่าพักร้อนคงเหลือ)
+const (
+	TerminationResignation = "resignation" // ลาออกเอง: ได้เฉพาะค่าพักร้อนคงเหลือ
+	TerminationLayoff      = "layoff"      // นายจ้างเลิกจ้าง: ค่าชดเชย ม.118, ค่าบอกกล่าวล่วงหน้า ม.17/1, ค่าพักร้อน ม.67
+	TerminationRetirement  = "retirement"  // เกษียณอายุ: ถือเป็นการเลิกจ้าง ม.118/1 (ค่าชดเชย + ค่าพักร้อน)
+	TerminationMisconduct  = "misconduct"  // เลิกจ้างเพราะกระทำผิดตาม ม.119: ไม่ได้รับค่าชดเชย/ค่าบอกกล่าว/ค่าพักร้อน
+)
+
+// TerminationReasons เหตุที่พ้นสภาพทั้งหมดที่ระบบรองรับ
+var TerminationReasons = []string{TerminationResignation, TerminationLayoff, TerminationRetirement, TerminationMisconduct}
+
+// ValidTerminationReason ตรวจว่าเป็นเหตุที่รองรับ
+func ValidTerminationReason(r string) bool {
+	for _, t := range TerminationReasons {
+		if t == r {
+			return true
+		}
+	}
+	return false
 }
 
 // บังคับชื่อ table ให้ตรงกับ DDL (ถ้าโปรเจ็กต์ไม่ได้ตั้ง naming strategy เป็นพหูพจน์)
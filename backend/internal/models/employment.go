@@ -1,21 +1,25 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"backend/internal/money"
+)
 
 type Employment struct {
 	ID           uint       `gorm:"primaryKey" json:"id"`
 	EmployeeID   uint       `gorm:"uniqueIndex;not null" json:"employeeId"` // FK -> employees.id (1:1)
-	HireDate     time.Time  `json:"hireDate"`   // ใช้งานจริง
-	StartDate    time.Time  `json:"startDate"`  // คงไว้เพื่อ backward compatibility
+	HireDate     time.Time  `json:"hireDate"`                               // ใช้งานจริง
+	StartDate    time.Time  `json:"startDate"`                              // คงไว้เพื่อ backward compatibility
 	EndDate      *time.Time `json:"endDate"`
 	ContractType string     `json:"contractType"`
 
-	BaseSalary   float64    `json:"baseSalary"` // ใช้งานจริง
-	Salary       float64    `json:"salary"`     // คงไว้เพื่อ backward compatibility
-	Allowance    float64    `json:"allowance"`
-	TaxRate      float64    `json:"taxRate"`
-	SSOPercent   float64    `json:"ssoPercent"`
-	PVDPercent   float64    `json:"pvdPercent"`
+	BaseSalary money.Amount `json:"baseSalary"` // ใช้งานจริง
+	Salary     money.Amount `json:"salary"`     // คงไว้เพื่อ backward compatibility
+	Allowance  money.Amount `json:"allowance"`
+	TaxRate    float64      `json:"taxRate"`
+	SSOPercent float64      `json:"ssoPercent"`
+	PVDPercent float64      `json:"pvdPercent"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// สถานะคำสั่งอายัด
+const (
+	GarnishmentActive    = "active"    // หักทุกงวดตามลำดับความสำคัญ
+	GarnishmentSuspended = "suspended" // ระงับชั่วคราว
+	GarnishmentSatisfied = "satisfied" // หักครบยอดหนี้แล้ว
+	GarnishmentReleased  = "released"  // ถอนการอายัด
+)
+
+// GarnishmentStatuses สถานะคำสั่งอายัดทั้งหมดที่ระบบรองรับ
+var GarnishmentStatuses = []string{GarnishmentActive, GarnishmentSuspended, GarnishmentSatisfied, GarnishmentReleased}
+
+// ValidGarnishmentStatus ตรวจว่าเป็นสถานะที่รองรับ
+func ValidGarnishmentStatus(s string) bool {
+	for _, st := range GarnishmentStatuses {
+		if st == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Garnishment คำสั่งอายัดเงินเดือนของกรมบังคับคดีสำหรับเจ้าหนี้ตามคำพิพากษา
+// หักตามอัตรา (Rate ของเงินได้หลังหักภาษี/SSO/PVD) หรือยอดคงที่ต่องวด (Amount) ไม่เกิน Cap ต่องวด
+// และไม่ทำให้เงินสุทธิต่ำกว่า Protected (ยอดรายเดือนที่พนักงานต้องได้รับ แปลงเป็นยอดต่องวดตอนคำนวณ)
+// Outstanding ลดลงเมื่ออนุมัติ run; Priority น้อยหักก่อน (เท่ากันหักตามลำดับที่รับคำสั่ง)
+type Garnishment struct {
+	ID              uint         `gorm:"primaryKey;column:id" json:"id"`
+	EmployeeID      uint         `gorm:"column:employee_id;index;not null" json:"employeeId"`
+	OrderNo         string       `gorm:"column:order_no;not null" json:"orderNo"`
+	Creditor        string       `gorm:"column:creditor;not null" json:"creditor"`
+	CreditorAccount string       `gorm:"column:creditor_account" json:"creditorAccount,omitempty"` // บัญชีที่นำส่งเงิน
+	Priority        int          `gorm:"column:priority;default:0" json:"priority"`
+	Rate            float64      `gorm:"column:rate;default:0" json:"rate"`
+	Amount          money.Amount `gorm:"column:amount;default:0" json:"amount"`
+	Cap             money.Amount `gorm:"column:cap;default:0" json:"cap"` // 0 = ไม่จำกัดต่องวด
+	Protected       money.Amount `gorm:"column:protected_amount;not null" json:"protected"`
+	Total           money.Amount `gorm:"column:total;not null" json:"total"` // ยอดหนี้ตามคำสั่ง
+	Outstanding     money.Amount `gorm:"column:outstanding;not null" json:"outstanding"`
+	StartDate       time.Time    `gorm:"column:start_date;type:date;not null" json:"startDate"`
+	Status          string       `gorm:"column:status;not null;default:active" json:"status"`
+	Note            string       `gorm:"column:note" json:"note,omitempty"`
+	CreatedBy       string       `gorm:"column:created_by" json:"createdBy,omitempty"`
+	CreatedAt       time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (Garnishment) TableName() string { return "garnishments" }
+
+// GarnishmentRemittance ยอดที่หักตามคำสั่งอายัดใน run ที่อนุมัติแล้ว (นำส่งเจ้าหนี้/กรมบังคับคดี)
+type GarnishmentRemittance struct {
+	ID            uint         `gorm:"primaryKey;column:id" json:"id"`
+	GarnishmentID uint         `gorm:"column:garnishment_id;index;not null" json:"garnishmentId"`
+	RunID         uint         `gorm:"column:payroll_run_id;index;not null" json:"runId"`
+	Amount        money.Amount `gorm:"column:amount;not null" json:"amount"`
+	CreatedBy     string       `gorm:"column:created_by" json:"createdBy,omitempty"`
+	CreatedAt     time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (GarnishmentRemittance) TableName() string { return "garnishment_remittances" }
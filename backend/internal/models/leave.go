@@ -2,12 +2,74 @@ package models
 
 import "time"
 
+// ประเภทการลา
+const (
+	LeaveSick      = "sick"
+	LeavePersonal  = "personal"
+	LeaveAnnual    = "annual"
+	LeaveUnpaid    = "unpaid"
+	LeaveMaternity = "maternity"
+)
+
+// สถานะคำขอลา: submitted → approved / rejected, และ submitted/approved → cancelled
+const (
+	LeaveSubmitted = "submitted"
+	LeaveApproved  = "approved"
+	LeaveRejected  = "rejected"
+	LeaveCancelled = "cancelled"
+)
+
+// LeaveTypes ประเภทการลาทั้งหมดที่ระบบรองรับ
+var LeaveTypes = []string{LeaveSick, LeavePersonal, LeaveAnnual, LeaveUnpaid, LeaveMaternity}
+
 type Leave struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	EmployeeID uint      `gorm:"index" json:"employeeId"`
+	Type       string    `gorm:"column:leave_type;default:personal" json:"type"`
 	StartDate  time.Time `json:"startDate"`
 	EndDate    time.Time `json:"endDate"`
 	Reason     string    `json:"reason"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	Unpaid     bool      `gorm:"column:unpaid;default:false" json:"unpaid"` // ลาเกินสิทธิ → ไม่รับค่าจ้าง (ระบบกำหนดเอง ไม่รับจาก payload)
+	Status     string    `gorm:"default:submitted;index" json:"status"`
+
+	// ผู้ยื่นคำขอ (email ของผู้ login) ใช้กันไม่ให้อนุมัติใบลาของตนเอง
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// ผู้อนุมัติ/ไม่อนุมัติ/ยกเลิก และเวลาที่ตัดสิน
+	DecidedBy    string     `json:"decidedBy,omitempty"`
+	DecidedAt    *time.Time `json:"decidedAt,omitempty"`
+	DecisionNote string     `json:"decisionNote,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Approved เฉพาะใบลาที่อนุมัติแล้วเท่านั้นที่นับในยอดสิทธิและการหักเงินเดือน
+func (l Leave) Approved() bool {
+	return l.Status == LeaveApproved
+}
+
+// Active ใบลาที่ยังมีผล (รออนุมัติหรืออนุมัติแล้ว) ใช้ตรวจวันลาซ้อนกัน
+func (l Leave) Active() bool {
+	return l.Status == LeaveSubmitted || l.Status == LeaveApproved
+}
+
+// Overlaps ช่วงวันที่ลาซ้อนกับ from..to (inclusive)
+func (l Leave) Overlaps(from, to time.Time) bool {
+	return !l.StartDate.After(to) && !l.EndDate.Before(from)
+}
+
+// IsUnpaid การลาที่ไม่ได้รับค่าจ้าง (ต้องหักเงินเดือนตามจำนวนวัน)
+func (l Leave) IsUnpaid() bool {
+	return l.Type == LeaveUnpaid || l.Unpaid
+}
+
+// ValidLeaveType ตรวจว่าเป็นประเภทการลาที่รองรับ
+func ValidLeaveType(t string) bool {
+	for _, lt := range LeaveTypes {
+		if lt == t {
+			return true
+		}
+	}
+	return false
 }
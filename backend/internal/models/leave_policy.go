@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// วิธีจัดการคำขอลาที่เกินสิทธิคงเหลือ
+const (
+	OverBalanceReject = "reject" // ปฏิเสธคำขอ
+	OverBalanceUnpaid = "unpaid" // ส่วนที่เกินสิทธิเป็นลาไม่รับค่าจ้าง
+)
+
+// AccrualTier สิทธิวันลาต่อปีเมื่ออายุงานครบ MinServiceMonths เดือน
+type AccrualTier struct {
+	MinServiceMonths int `json:"minServiceMonths"`
+	Days             int `json:"days"`
+}
+
+// LeavePolicy นโยบายสิทธิการลาของแต่ละประเภท
+type LeavePolicy struct {
+	ID           uint          `gorm:"primaryKey;column:id" json:"id"`
+	Type         string        `gorm:"column:leave_type;uniqueIndex;not null" json:"type"`
+	Tiers        []AccrualTier `gorm:"column:tiers;serializer:json;type:jsonb" json:"tiers"`
+	MaxCarryOver int           `gorm:"column:max_carry_over;default:0" json:"maxCarryOver"`
+	OverBalance  string        `gorm:"column:over_balance;default:reject" json:"overBalance"`
+	UpdatedAt    time.Time     `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+}
+
+func (LeavePolicy) TableName() string { return "leave_policies" }
+
+// EntitledDays สิทธิวันลาต่อปีตามอายุงาน (เดือน) ใช้ขั้นสูงสุดที่อายุงานถึง
+func (p LeavePolicy) EntitledDays(serviceMonths int) int {
+	days := 0
+	best := -1
+	for _, t := range p.Tiers {
+		if serviceMonths >= t.MinServiceMonths && t.MinServiceMonths > best {
+			best = t.MinServiceMonths
+			days = t.Days
+		}
+	}
+	return days
+}
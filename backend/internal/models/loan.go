@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// ประเภทเงินกู้
+const (
+	LoanAdvance = "advance" // เงินเดือนล่วงหน้า
+	LoanWelfare = "welfare" // เงินกู้สวัสดิการ
+)
+
+// LoanTypes ประเภทเงินกู้ทั้งหมดที่ระบบรองรับ
+var LoanTypes = []string{LoanAdvance, LoanWelfare}
+
+// สถานะเงินกู้
+const (
+	LoanActive  = "active"   // หักผ่อนชำระทุกงวดตั้งแต่งวดที่เริ่ม
+	LoanPaused  = "paused"   // พักชำระ (ไม่หักจนกว่าจะกลับเป็น active)
+	LoanPaidOff = "paid_off" // ชำระครบแล้ว
+)
+
+// ValidLoanType ตรวจว่าเป็นประเภทเงินกู้ที่รองรับ
+func ValidLoanType(t string) bool {
+	for _, lt := range LoanTypes {
+		if lt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Loan เงินกู้/เงินเดือนล่วงหน้าของพนักงานที่ผ่อนชำระโดยหักจากเงินเดือน
+// Total = Principal + Interest (ดอกเบี้ยคงที่คิดครั้งเดียวตอนสร้าง) และ Outstanding ลดลงเมื่ออนุมัติ run
+// PayoffInPayroll = หักยอดคงเหลือทั้งหมดใน run ปกติถัดไป (ปิดบัญชีก่อนกำหนด)
+type Loan struct {
+	ID              uint         `gorm:"primaryKey;column:id" json:"id"`
+	EmployeeID      uint         `gorm:"column:employee_id;index;not null" json:"employeeId"`
+	Type            string       `gorm:"column:loan_type;not null" json:"type"`
+	Description     string       `gorm:"column:description" json:"description"`
+	Principal       money.Amount `gorm:"column:principal;not null" json:"principal"`
+	InterestRate    float64      `gorm:"column:interest_rate;default:0" json:"interestRate"` // อัตราต่อปี (flat rate)
+	Interest        money.Amount `gorm:"column:interest;default:0" json:"interest"`
+	Installments    int          `gorm:"column:installments;not null" json:"installments"`      // จำนวนงวดผ่อน
+	Installment     money.Amount `gorm:"column:installment;not null" json:"installment"`        // ยอดหักต่องวด
+	StartDate       time.Time    `gorm:"column:start_date;type:date;not null" json:"startDate"` // เริ่มหักในงวดที่ครอบคลุมวันนี้
+	Outstanding     money.Amount `gorm:"column:outstanding;not null" json:"outstanding"`
+	Status          string       `gorm:"column:status;not null;default:active" json:"status"`
+	PayoffInPayroll bool         `gorm:"column:payoff_in_payroll;default:false" json:"payoffInPayroll"`
+	CreatedBy       string       `gorm:"column:created_by" json:"createdBy,omitempty"`
+	CreatedAt       time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (Loan) TableName() string { return "loans" }
+
+// Total ยอดที่ต้องชำระทั้งหมด (เงินต้น + ดอกเบี้ย)
+func (l Loan) Total() money.Amount { return l.Principal + l.Interest }
+
+// LoanRepayment การชำระเงินกู้หนึ่งครั้ง: หักผ่าน run (RunID) หรือชำระเป็นเงินสด (RunID = nil)
+type LoanRepayment struct {
+	ID        uint         `gorm:"primaryKey;column:id" json:"id"`
+	LoanID    uint         `gorm:"column:loan_id;index;not null" json:"loanId"`
+	RunID     *uint        `gorm:"column:payroll_run_id;index" json:"runId,omitempty"`
+	Amount    money.Amount `gorm:"column:amount;not null" json:"amount"`
+	Note      string       `gorm:"column:note" json:"note,omitempty"`
+	CreatedBy string       `gorm:"column:created_by" json:"createdBy,omitempty"`
+	CreatedAt time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (LoanRepayment) TableName() string { return "loan_repayments" }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ความถี่การจ่ายเงินเดือน
+const (
+	FrequencyMonthly     = "monthly"
+	FrequencySemiMonthly = "semi_monthly" // 1–15 และ 16–สิ้นเดือน
+	FrequencyBiweekly    = "biweekly"     // ทุก 14 วันนับจาก AnchorDate
+	FrequencyWeekly      = "weekly"       // ทุก 7 วันนับจาก AnchorDate
+)
+
+// Frequencies ความถี่ทั้งหมดที่ระบบรองรับ
+var Frequencies = []string{FrequencyMonthly, FrequencySemiMonthly, FrequencyBiweekly, FrequencyWeekly}
+
+// PayGroup กลุ่มพนักงานที่จ่ายเงินเดือนด้วยความถี่เดียวกัน
+// พนักงานที่ไม่มี PayGroupID อยู่ในกลุ่มรายเดือนตั้งต้น
+type PayGroup struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Code       string     `gorm:"uniqueIndex;not null" json:"code"`
+	Name       string     `gorm:"not null" json:"name"`
+	Frequency  string     `gorm:"not null;default:monthly" json:"frequency"`
+	AnchorDate *time.Time `gorm:"type:date" json:"anchorDate,omitempty"` // วันเริ่มงวดแรก (biweekly/weekly)
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (PayGroup) TableName() string { return "pay_groups" }
+
+// ValidFrequency ตรวจว่าเป็นความถี่ที่รองรับ
+func ValidFrequency(f string) bool {
+	for _, fr := range Frequencies {
+		if fr == f {
+			return true
+		}
+	}
+	return false
+}
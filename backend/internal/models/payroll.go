@@ -1,30 +1,311 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"backend/internal/money"
+)
+
+// สถานะของ payroll run: draft → calculated → approved → paid → closed
+const (
+	RunDraft      = "draft"
+	RunCalculated = "calculated"
+	RunApproved   = "approved"
+	RunPaid       = "paid"
+	RunClosed     = "closed"
+)
+
+// ประเภทของ run: regular มีได้หนึ่ง run ต่องวด, ประเภทอื่นเป็น run นอกรอบ (off-cycle) มีได้หลาย run
+const (
+	RunRegular     = "regular"
+	RunBonus       = "bonus"
+	RunCorrection  = "correction"
+	RunTermination = "termination"
+)
+
+// RunTypes ประเภท run ทั้งหมดที่ระบบรองรับ
+var RunTypes = []string{RunRegular, RunBonus, RunCorrection, RunTermination}
 
 // PayrollRun ตามตาราง payroll_runs
+// Locked derive จาก Status (approved ขึ้นไป) เก็บไว้ให้ frontend เดิมใช้งานได้
 type PayrollRun struct {
-	ID          uint          `gorm:"primaryKey;column:id" json:"id"`
-	PeriodYear  int           `gorm:"column:period_year;not null" json:"periodYear"`
-	PeriodMonth int           `gorm:"column:period_month;not null" json:"periodMonth"`
-	Locked      bool          `gorm:"column:locked;default:false" json:"locked"`
-	CreatedAt   time.Time     `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
-	Items       []PayrollItem `gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE" json:"items"`
+	ID          uint   `gorm:"primaryKey;column:id" json:"id"`
+	PeriodYear  int    `gorm:"column:period_year;not null" json:"periodYear"`
+	PeriodMonth int    `gorm:"column:period_month;not null" json:"periodMonth"`
+	Type        string `gorm:"column:run_type;default:regular" json:"type"`
+	Description string `gorm:"column:description" json:"description,omitempty"`
+
+	// PayGroupID / PeriodStart / PeriodEnd งวดของกลุ่มจ่ายเงิน (nil = งวดเต็มเดือนของกลุ่มรายเดือนตั้งต้น)
+	// PeriodYear/PeriodMonth คือปี/เดือนของวันสิ้นงวด ใช้เป็นเดือนภาษีและเดือน SSO
+	PayGroupID  *uint      `gorm:"column:pay_group_id;index" json:"payGroupId,omitempty"`
+	PeriodStart *time.Time `gorm:"column:period_start;type:date" json:"periodStart,omitempty"`
+	PeriodEnd   *time.Time `gorm:"column:period_end;type:date" json:"periodEnd,omitempty"`
+
+	// EmployeeIDs / ComponentCodes กำหนดพนักงานและรายการประจำที่รวมใน run
+	// ว่าง = ค่าเริ่มต้นตามประเภท (regular: พนักงาน active ทุกคนและรายการประจำทั้งหมด,
+	// นอกรอบ: เฉพาะพนักงานที่มี RunInput และไม่รวมรายการประจำ)
+	EmployeeIDs    []uint   `gorm:"column:employee_ids;serializer:json;type:jsonb" json:"employeeIds,omitempty"`
+	ComponentCodes []string `gorm:"column:component_codes;serializer:json;type:jsonb" json:"componentCodes,omitempty"`
+
+	Status    string        `gorm:"column:status;default:draft" json:"status"`
+	Locked    bool          `gorm:"column:locked;default:false" json:"locked"`
+	CreatedAt time.Time     `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	Items     []PayrollItem `gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE" json:"items"`
 }
 
 func (PayrollRun) TableName() string { return "payroll_runs" }
 
+// Editable คำนวณใหม่/แก้ item/ลบ run ได้เฉพาะก่อนอนุมัติ
+func (r PayrollRun) Editable() bool {
+	return r.Status == "" || r.Status == RunDraft || r.Status == RunCalculated
+}
+
+// RunTransition ประวัติการเปลี่ยนสถานะของ run (ใคร เมื่อไร จากสถานะไหน)
+type RunTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RunID      uint      `gorm:"column:payroll_run_id;index;not null" json:"runId"`
+	FromStatus string    `gorm:"not null" json:"from"`
+	ToStatus   string    `gorm:"not null" json:"to"`
+	By         string    `gorm:"column:changed_by" json:"by"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"at"`
+}
+
+func (RunTransition) TableName() string { return "payroll_run_transitions" }
+
+// SameGroup run อยู่ในกลุ่มจ่ายเงินเดียวกับ id (nil = กลุ่มรายเดือนตั้งต้น)
+func (r PayrollRun) SameGroup(id *uint) bool {
+	if r.PayGroupID == nil || id == nil {
+		return r.PayGroupID == nil && id == nil
+	}
+	return *r.PayGroupID == *id
+}
+
+// OffCycle run นอกรอบ (โบนัส/ปรับปรุง/เลิกจ้าง) ใช้วิธีหักภาษีเงินได้ครั้งเดียว
+func (r PayrollRun) OffCycle() bool {
+	return r.Type != "" && r.Type != RunRegular
+}
+
+// ValidRunType ตรวจว่าเป็นประเภท run ที่รองรับ
+func ValidRunType(t string) bool {
+	for _, rt := range RunTypes {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RunInput รายการจ่ายครั้งเดียวของพนักงานใน run (เช่นโบนัส ค่าปรับปรุงย้อนหลัง)
+// Kind ใช้ค่าเดียวกับ PayComponent (earning/deduction); เงินหักที่ Taxable = หักก่อนภาษี
+type RunInput struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	RunID       uint         `gorm:"column:payroll_run_id;index;not null" json:"runId"`
+	EmployeeID  uint         `gorm:"index;not null" json:"employeeId"`
+	Code        string       `gorm:"not null" json:"code"`
+	Description string       `json:"description"`
+	Kind        string       `gorm:"not null;default:earning" json:"kind"`
+	Amount      money.Amount `gorm:"not null" json:"amount"`
+	Taxable     bool         `gorm:"not null" json:"taxable"`
+	SSOable     bool         `gorm:"column:sso_able;not null" json:"ssoAble"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+func (RunInput) TableName() string { return "payroll_run_inputs" }
+
 // ⚠️ สำคัญ: ให้ตรงกับตาราง payslips
+// ยอด BaseSalary/TaxWithheld/SSO/PVD/NetPay เป็นค่าที่ derive จาก Lines (ดู SyncTotals)
+// เก็บไว้เป็นคอลัมน์เพื่อให้ frontend เดิมใช้งานได้
 type PayrollItem struct {
-	ID          uint      `gorm:"primaryKey;column:id" json:"id"`
-	RunID       uint      `gorm:"column:payroll_run_id;index;not null" json:"runId"`
-	EmployeeID  uint      `gorm:"column:employee_id;index;not null" json:"employeeId"`
-	BaseSalary  float64   `gorm:"column:base_salary;not null" json:"baseSalary"`
-	TaxWithheld float64   `gorm:"column:tax_withheld;not null" json:"taxWithheld"`
-	SSO         float64   `gorm:"column:sso;not null" json:"sso"`
-	PVD         float64   `gorm:"column:pvd;not null" json:"pvd"`
-	NetPay      float64   `gorm:"column:net_pay;not null" json:"netPay"`
-	GeneratedAt time.Time `gorm:"column:generated_at;autoCreateTime" json:"generatedAt"`
+	ID          uint         `gorm:"primaryKey;column:id" json:"id"`
+	RunID       uint         `gorm:"column:payroll_run_id;index;not null" json:"runId"`
+	EmployeeID  uint         `gorm:"column:employee_id;index;not null" json:"employeeId"`
+	BaseSalary  money.Amount `gorm:"column:base_salary;not null" json:"baseSalary"`
+	TaxWithheld money.Amount `gorm:"column:tax_withheld;not null" json:"taxWithheld"`
+	SSO         money.Amount `gorm:"column:sso;not null" json:"sso"`
+	PVD         money.Amount `gorm:"column:pvd;not null" json:"pvd"`
+	NetPay      money.Amount `gorm:"column:net_pay;not null" json:"netPay"`
+	GeneratedAt time.Time    `gorm:"column:generated_at;autoCreateTime" json:"generatedAt"`
+
+	// Trace คำอธิบายขั้นตอนการคำนวณ (JSON ของ payroll.Trace) ดูผ่าน GET /payroll/items/:id/trace
+	Trace json.RawMessage `gorm:"column:trace;type:jsonb" json:"-"`
+
+	Lines []PayrollLine `gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE" json:"lines"`
 }
 
 func (PayrollItem) TableName() string { return "payslips" }
+
+// หมวดของบรรทัดรายการ
+const (
+	LineEarning   = "earning"   // เงินได้ (บวกเข้าเงินสุทธิ)
+	LineDeduction = "deduction" // เงินหัก (ลบออกจากเงินสุทธิ)
+	LineEmployer  = "employer"  // ต้นทุนฝั่งนายจ้าง (ไม่กระทบเงินสุทธิ)
+	LineInfo      = "info"      // ข้อมูลประกอบ เช่น จำนวนวันทำงาน
+)
+
+// รหัสบรรทัดมาตรฐานที่ engine สร้าง
+const (
+	CodeBaseSalary  = "BASE"
+	CodeTax         = "TAX"
+	CodeTaxExtra    = "TAX_EXTRA"
+	CodeSSO         = "SSO"
+	CodePVD         = "PVD"
+	CodeSSOEmployer = "SSO_ER"
+	CodeWorkedDays  = "DAYS"
+	CodeUnpaidLeave = "UNPAID_LEAVE"
+	CodeRetroPay    = "RETRO" // ส่วนต่างเงินเดือนย้อนหลัง (RefRunID = run เดิมของงวดนั้น)
+
+	// เงินได้จากการพ้นสภาพ (run ประเภท termination)
+	CodeSeverance       = "SEVERANCE"        // ค่าชดเชยส่วนที่ต้องเสียภาษี
+	CodeSeveranceExempt = "SEVERANCE_EXEMPT" // ค่าชดเชยส่วนที่ได้รับยกเว้นภาษี
+	CodeNoticePay       = "NOTICE_PAY"       // ค่าจ้างแทนการบอกกล่าวล่วงหน้า
+	CodeLeavePayout     = "LEAVE_PAYOUT"     // ค่าจ้างสำหรับวันหยุดพักผ่อนประจำปีที่ยังไม่ได้ใช้
+
+	CodeLoan    = "LOAN"    // ผ่อนชำระเงินกู้/เงินเดือนล่วงหน้า (RefLoanID = เงินกู้)
+	CodeGarnish = "GARNISH" // อายัดเงินเดือนตามคำสั่งกรมบังคับคดี (RefGarnishmentID = คำสั่งอายัด)
+)
+
+// PayrollLine บรรทัดรายการของ PayrollItem (ตาราง payslip_lines)
+// Taxable/SSOable บนบรรทัดเงินได้ = นับเป็นเงินได้/ค่าจ้าง, บนบรรทัดเงินหัก = หักก่อนภาษี/ลดค่าจ้าง
+// (เช่น หักลาไม่รับค่าจ้าง)
+type PayrollLine struct {
+	ID          uint         `gorm:"primaryKey;column:id" json:"id"`
+	ItemID      uint         `gorm:"column:payslip_id;index;not null" json:"itemId"`
+	Seq         int          `gorm:"column:seq;not null" json:"seq"`
+	Code        string       `gorm:"column:code;not null" json:"code"`
+	Description string       `gorm:"column:description" json:"description"`
+	Category    string       `gorm:"column:category;not null" json:"category"`
+	Amount      money.Amount `gorm:"column:amount;not null" json:"amount"`
+	Quantity    float64      `gorm:"column:quantity;default:0" json:"quantity"`
+	Rate        float64      `gorm:"column:rate;default:0" json:"rate"`
+	Taxable     bool         `gorm:"column:taxable;default:false" json:"taxable"`
+	SSOable     bool         `gorm:"column:sso_able;default:false" json:"ssoAble"`
+	RefRunID    *uint        `gorm:"column:ref_run_id" json:"refRunId,omitempty"`
+	RefLoanID   *uint        `gorm:"column:ref_loan_id" json:"refLoanId,omitempty"`
+
+	RefGarnishmentID *uint `gorm:"column:ref_garnishment_id" json:"refGarnishmentId,omitempty"`
+}
+
+func (PayrollLine) TableName() string { return "payslip_lines" }
+
+// SignedAmount ยอดของบรรทัดที่มีผลต่อเงินสุทธิ (เงินได้เป็นบวก เงินหักเป็นลบ)
+func (l PayrollLine) SignedAmount() money.Amount {
+	switch l.Category {
+	case LineEarning:
+		return l.Amount
+	case LineDeduction:
+		return -l.Amount
+	}
+	return 0
+}
+
+// Sum รวมยอดของบรรทัดที่อยู่ในหมวดที่กำหนด
+func (it PayrollItem) Sum(category string) money.Amount {
+	var t money.Amount
+	for _, l := range it.Lines {
+		if l.Category == category {
+			t += l.Amount
+		}
+	}
+	return t
+}
+
+// SumCodes รวมยอดของบรรทัดตามรหัส
+func (it PayrollItem) SumCodes(codes ...string) money.Amount {
+	var t money.Amount
+	for _, l := range it.Lines {
+		for _, c := range codes {
+			if l.Code == c {
+				t += l.Amount
+				break
+			}
+		}
+	}
+	return t
+}
+
+// SSOWage ค่าจ้างที่ใช้คิด SSO ก่อนบีบฐาน (เงินได้ SSOable หักด้วยเงินหัก SSOable)
+func (it PayrollItem) SSOWage() money.Amount {
+	var w money.Amount
+	for _, l := range it.Lines {
+		if !l.SSOable {
+			continue
+		}
+		switch l.Category {
+		case LineEarning:
+			w += l.Amount
+		case LineDeduction:
+			w -= l.Amount
+		}
+	}
+	return w
+}
+
+// TaxableIncome รวมเงินได้ที่ต้องเสียภาษี (หักด้วยเงินหักก่อนภาษี)
+func (it PayrollItem) TaxableIncome() money.Amount {
+	var t money.Amount
+	for _, l := range it.Lines {
+		if !l.Taxable {
+			continue
+		}
+		switch l.Category {
+		case LineEarning:
+			t += l.Amount
+		case LineDeduction:
+			t -= l.Amount
+		}
+	}
+	return t
+}
+
+// GrossPay เงินได้รวมทุกบรรทัด
+func (it PayrollItem) GrossPay() money.Amount { return it.Sum(LineEarning) }
+
+// OtherDeductions เงินหักอื่นนอกจากภาษี/SSO/PVD (รวมหักลาไม่รับค่าจ้าง)
+func (it PayrollItem) OtherDeductions() money.Amount {
+	return it.Sum(LineDeduction) - it.SumCodes(CodeTax, CodeTaxExtra, CodeSSO, CodePVD)
+}
+
+// SetLine แก้ยอดบรรทัดแรกที่มีรหัสตรงกันและลบบรรทัดซ้ำ ถ้าไม่มีจะเพิ่มบรรทัดใหม่
+func (it *PayrollItem) SetLine(code, description, category string, amount money.Amount) {
+	out := it.Lines[:0]
+	found := false
+	for _, l := range it.Lines {
+		if l.Code == code {
+			if found {
+				continue
+			}
+			l.Amount = amount
+			found = true
+		}
+		out = append(out, l)
+	}
+	it.Lines = out
+	if !found {
+		it.Lines = append(it.Lines, PayrollLine{Code: code, Description: description, Category: category, Amount: amount})
+	}
+}
+
+// RemoveLine ลบทุกบรรทัดที่มีรหัสตรงกัน
+func (it *PayrollItem) RemoveLine(code string) {
+	out := it.Lines[:0]
+	for _, l := range it.Lines {
+		if l.Code != code {
+			out = append(out, l)
+		}
+	}
+	it.Lines = out
+}
+
+// SyncTotals คำนวณคอลัมน์ยอดรวมเดิมจาก Lines และเรียงลำดับ Seq ใหม่
+func (it *PayrollItem) SyncTotals() {
+	for i := range it.Lines {
+		it.Lines[i].Seq = i + 1
+	}
+	it.BaseSalary = it.SumCodes(CodeBaseSalary)
+	it.TaxWithheld = it.SumCodes(CodeTax, CodeTaxExtra)
+	it.SSO = it.SumCodes(CodeSSO)
+	it.PVD = it.SumCodes(CodePVD)
+	it.NetPay = it.Sum(LineEarning) - it.Sum(LineDeduction)
+}
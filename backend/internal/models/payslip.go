@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"backend/internal/money"
+)
 
 type Payslip struct {
 	ID         uint `gorm:"primaryKey" json:"id"`
@@ -8,10 +12,10 @@ type Payslip struct {
 	EmployeeID uint `gorm:"index" json:"employeeId"`
 
 	// รายละเอียดเงินเดือน
-	BaseSalary float64 `json:"baseSalary"`
-	Allowance  float64 `json:"allowance"`
-	Deductions float64 `json:"deductions"`
-	NetPay     float64 `json:"netPay"`
+	BaseSalary money.Amount `json:"baseSalary"`
+	Allowance  money.Amount `json:"allowance"`
+	Deductions money.Amount `json:"deductions"`
+	NetPay     money.Amount `json:"netPay"`
 
 	// วันที่ออกสลิป
 	PayDate   time.Time `json:"payDate"`
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// TaxBracket ขั้นบันไดภาษีเงินได้บุคคลธรรมดา
+// Upper คือเพดานเงินได้สุทธิของขั้น (0 = ไม่มีเพดาน), Rate คืออัตราภาษีของขั้นนั้น
+type TaxBracket struct {
+	Upper money.Amount `json:"upper"`
+	Rate  float64      `json:"rate"`
+}
+
+// StatutoryRate ตารางอัตราตามกฎหมายที่มีผลตั้งแต่ EffectiveFrom จนกว่าจะมีตารางใหม่
+type StatutoryRate struct {
+	ID            uint      `gorm:"primaryKey;column:id" json:"id"`
+	EffectiveFrom time.Time `gorm:"column:effective_from;uniqueIndex;not null" json:"effectiveFrom"`
+
+	SSOMinBase      money.Amount `gorm:"column:sso_min_base;not null" json:"ssoMinBase"`
+	SSOMaxBase      money.Amount `gorm:"column:sso_max_base;not null" json:"ssoMaxBase"`
+	SSOEmployeeRate float64      `gorm:"column:sso_employee_rate;not null" json:"ssoEmployeeRate"`
+	SSOEmployerRate float64      `gorm:"column:sso_employer_rate;not null" json:"ssoEmployerRate"`
+
+	PersonalAllowance money.Amount `gorm:"column:personal_allowance;not null" json:"personalAllowance"`
+	ExpenseRate       float64      `gorm:"column:expense_rate;not null" json:"expenseRate"`
+	ExpenseCap        money.Amount `gorm:"column:expense_cap;not null" json:"expenseCap"`
+	TaxBrackets       []TaxBracket `gorm:"column:tax_brackets;serializer:json;type:jsonb" json:"taxBrackets"`
+
+	Note      string    `gorm:"column:note" json:"note"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (StatutoryRate) TableName() string { return "statutory_rates" }
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// เหตุผลของการเปลี่ยนเงินเดือน
+const (
+	SalaryHire       = "hire"       // เงินเดือนแรกเข้า
+	SalaryPromotion  = "promotion"  // เลื่อนตำแหน่ง
+	SalaryMerit      = "merit"      // ขึ้นเงินเดือนประจำปี/ตามผลงาน
+	SalaryAdjustment = "adjustment" // ปรับโครงสร้าง/ปรับตามตลาด
+	SalaryCorrection = "correction" // แก้ไขเงินเดือนที่บันทึกผิด
+)
+
+// SalaryReasons เหตุผลทั้งหมดที่ระบบรองรับ
+var SalaryReasons = []string{SalaryHire, SalaryPromotion, SalaryMerit, SalaryAdjustment, SalaryCorrection}
+
+// SalaryRecord ประวัติเงินเดือนรายเดือนของพนักงาน มีผลตั้งแต่ EffectiveFrom จนถึงวันก่อน record ถัดไป
+// ถ้ามีหลาย record ที่มีผลวันเดียวกัน record ที่บันทึกทีหลังมีผลแทน (เช่น correction)
+// Employee.BaseSalary คือเงินเดือน ณ ปัจจุบันที่ sync จากประวัตินี้
+type SalaryRecord struct {
+	ID            uint         `gorm:"primaryKey;column:id" json:"id"`
+	EmployeeID    uint         `gorm:"column:employee_id;index;not null" json:"employeeId"`
+	EffectiveFrom time.Time    `gorm:"column:effective_from;type:date;not null" json:"effectiveFrom"`
+	BaseSalary    money.Amount `gorm:"column:base_salary;not null" json:"baseSalary"`
+	Reason        string       `gorm:"column:reason;not null" json:"reason"`
+	Note          string       `gorm:"column:note" json:"note,omitempty"`
+	CreatedBy     string       `gorm:"column:created_by" json:"createdBy,omitempty"`
+	CreatedAt     time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (SalaryRecord) TableName() string { return "salary_records" }
+
+// ValidSalaryReason ตรวจว่าเป็นเหตุผลที่รองรับ
+func ValidSalaryReason(r string) bool {
+	for _, s := range SalaryReasons {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+// Package money เก็บจำนวนเงินเป็นจำนวนเต็มหน่วยสตางค์ เพื่อไม่ให้เกิดเศษทศนิยมคลาดเคลื่อน
+// เมื่อรวมยอดหลายรายการ การปัดเศษทุกจุดใช้นโยบายเดียวกันที่ตั้งด้วย SetRounding
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount จำนวนเงินหน่วยสตางค์ (1 บาท = 100)
+type Amount int64
+
+// Zero ค่าศูนย์บาท
+const Zero Amount = 0
+
+// Rounding นโยบายการปัดเศษสตางค์
+type Rounding int
+
+const (
+	HalfUp   Rounding = iota // ปัดครึ่งขึ้น (ค่าเริ่มต้น)
+	HalfEven                 // ปัดแบบธนาคาร
+	Down                     // ตัดเศษทิ้ง
+)
+
+var rounding = HalfUp
+
+// SetRounding ตั้งนโยบายการปัดเศษที่ใช้ทั้งระบบ
+func SetRounding(r Rounding) { rounding = r }
+
+// ParseRounding แปลงชื่อนโยบาย ("half_up", "half_even", "down")
+func ParseRounding(s string) (Rounding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "half_up":
+		return HalfUp, nil
+	case "half_even":
+		return HalfEven, nil
+	case "down":
+		return Down, nil
+	}
+	return HalfUp, fmt.Errorf("unknown rounding policy %q", s)
+}
+
+// FromBaht แปลงจำนวนบาทแบบทศนิยมเป็น Amount (ปัดตามนโยบาย)
+func FromBaht(baht float64) Amount {
+	return roundRat(decimalRat(baht, 100))
+}
+
+// Satang สร้าง Amount จากจำนวนสตางค์
+func Satang(n int64) Amount { return Amount(n) }
+
+// Parse แปลงข้อความ เช่น "1234.50" หรือ "1,234.5" เป็น Amount
+func Parse(s string) (Amount, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	q := roundInt(r.Mul(r, big.NewRat(100, 1)))
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+	return Amount(q.Int64()), nil
+}
+
+// Baht คืนค่าเป็นบาทแบบทศนิยม (ใช้สำหรับแสดงผล/อัตราส่วนเท่านั้น)
+func (a Amount) Baht() float64 { return float64(a) / 100 }
+
+// Satang คืนจำนวนสตางค์
+func (a Amount) Satang() int64 { return int64(a) }
+
+// String รูปแบบ "1234.50"
+func (a Amount) String() string {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// MulRate คูณด้วยอัตรา (เช่น 0.05) แล้วปัดตามนโยบาย
+func (a Amount) MulRate(rate float64) Amount {
+	r := decimalRat(rate, 1)
+	return roundRat(r.Mul(r, big.NewRat(int64(a), 1)))
+}
+
+// Mul คูณด้วยจำนวนเต็ม
+func (a Amount) Mul(n int64) Amount { return a * Amount(n) }
+
+// MulDiv คูณแล้วหาร (เช่น prorate ตามวันทำงาน/วันทั้งหมด) ปัดครั้งเดียวตามนโยบาย
+func (a Amount) MulDiv(num, den int64) Amount {
+	if den == 0 {
+		return 0
+	}
+	return roundRat(big.NewRat(int64(a)*num, den))
+}
+
+// Div หารด้วยจำนวนเต็ม ปัดตามนโยบาย
+func (a Amount) Div(n int64) Amount { return a.MulDiv(1, n) }
+
+// Ratio อัตราส่วน a/b (ใช้กับการกระจายยอด)
+func (a Amount) Ratio(b Amount) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Amount) Amount {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Sum รวมยอด
+func Sum(as ...Amount) Amount {
+	var t Amount
+	for _, a := range as {
+		t += a
+	}
+	return t
+}
+
+// decimalRat แปลง float เป็นเลขฐานสิบแบบตรงตัวก่อน (0.05 → 5/100) เพื่อไม่ให้เศษเลขฐานสองทำให้ปัดผิด
+func decimalRat(f float64, scale int64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return r.Mul(r, big.NewRat(scale, 1))
+}
+
+func roundRat(r *big.Rat) Amount {
+	return Amount(roundInt(r).Int64())
+}
+
+// roundInt ปัดเศษตามนโยบายเป็นจำนวนเต็ม (ผู้เรียกที่รับค่าจากภายนอกต้องตรวจ IsInt64 เอง)
+func roundInt(r *big.Rat) *big.Int {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Sign() == 0 || rounding == Down {
+		return q
+	}
+	// เปรียบเทียบเศษ*2 กับตัวหาร
+	twice := new(big.Int).Abs(m)
+	twice.Mul(twice, big.NewInt(2))
+	cmp := twice.Cmp(den)
+	away := cmp > 0 || (cmp == 0 && (rounding == HalfUp || q.Bit(0) == 1))
+	if away {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// MarshalJSON เขียนเป็นตัวเลขทศนิยม 2 ตำแหน่ง เช่น 1234.50
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON รับได้ทั้งตัวเลขและข้อความ
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" || s == "" {
+		*a = 0
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value เก็บลงคอลัมน์ NUMERIC(12,2) เป็นข้อความ
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan อ่านจากคอลัมน์ NUMERIC
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case int64:
+		*a = Amount(v * 100)
+		return nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("invalid numeric value")
+		}
+		*a = FromBaht(v)
+		return nil
+	case []byte:
+		return a.parseInto(string(v))
+	case string:
+		return a.parseInto(v)
+	}
+	return fmt.Errorf("cannot scan %T into money.Amount", src)
+}
+
+func (a *Amount) parseInto(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// GormDataType ให้ AutoMigrate สร้างคอลัมน์ตรงกับ db/schema.sql
+func (Amount) GormDataType() string { return "numeric(12,2)" }
@@ -0,0 +1,141 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// การปัดเศษสตางค์ที่ค่ากึ่งกลาง (x.xx5) ทั้งบวกและลบ ตามแต่ละนโยบาย
+func TestRounding(t *testing.T) {
+	defer SetRounding(HalfUp)
+	cases := []struct {
+		in                     string
+		halfUp, halfEven, down Amount
+	}{
+		{"1.005", 101, 100, 100},
+		{"1.015", 102, 102, 101},
+		{"1.0051", 101, 101, 100},
+		{"1.0049", 100, 100, 100},
+		{"-1.005", -101, -100, -100},
+		{"-1.015", -102, -102, -101},
+		{"-1.0051", -101, -101, -100},
+		{"0.005", 1, 0, 0},
+		{"-0.005", -1, 0, 0},
+	}
+	for _, tc := range cases {
+		for _, p := range []struct {
+			r    Rounding
+			want Amount
+		}{{HalfUp, tc.halfUp}, {HalfEven, tc.halfEven}, {Down, tc.down}} {
+			SetRounding(p.r)
+			got, err := Parse(tc.in)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.in, err)
+			}
+			if got != p.want {
+				t.Errorf("%s rounding %d: got %d satang, want %d", tc.in, p.r, got, p.want)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{"1234.50", 123450, false},
+		{"1,234.5", 123450, false},
+		{" 1,234,567.89 ", 123456789, false},
+		{"+15", 1500, false},
+		{"-0.75", -75, false},
+		{"-1,000", -100000, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"   ", 0, true},
+		{"abc", 0, true},
+		{"1.2.3", 0, true},
+		{"12฿", 0, true},
+		{"92233720368547758.08", 0, true},
+		{"-92233720368547758.09", 0, true},
+		{"1e30", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Parse(%q): err %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Parse(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	cases := []struct {
+		a    Amount
+		json string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{123450, "1234.50"},
+		{-75, "-0.75"},
+		{-123456789, "-1234567.89"},
+	}
+	for _, tc := range cases {
+		b, err := json.Marshal(tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.json {
+			t.Errorf("Marshal(%d) = %s, want %s", tc.a, b, tc.json)
+		}
+		var back Amount
+		if err := json.Unmarshal(b, &back); err != nil || back != tc.a {
+			t.Errorf("Unmarshal(%s) = %d, %v; want %d", b, back, err, tc.a)
+		}
+	}
+	// รับค่าที่เป็นข้อความและ null ได้ด้วย
+	var v struct{ A, B Amount }
+	if err := json.Unmarshal([]byte(`{"A":"1,500.25","B":null}`), &v); err != nil || v.A != 150025 || v.B != 0 {
+		t.Errorf("Unmarshal string/null = %+v, %v", v, err)
+	}
+	if err := json.Unmarshal([]byte(`{"A":"x"}`), &v); err == nil {
+		t.Error("Unmarshal invalid amount: want error")
+	}
+}
+
+func TestScanValueRoundTrip(t *testing.T) {
+	for _, a := range []Amount{0, 1, 99, 123450, -75, -123456789} {
+		v, err := a.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, src := range []interface{}{v, []byte(v.(string))} {
+			var back Amount
+			if err := back.Scan(src); err != nil || back != a {
+				t.Errorf("Scan(%#v) = %d, %v; want %d", src, back, err, a)
+			}
+		}
+	}
+	cases := []struct {
+		src     interface{}
+		want    Amount
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{int64(12), 1200, false},
+		{float64(12.34), 1234, false},
+		{"bad", 0, true},
+		{true, 0, true},
+	}
+	for _, tc := range cases {
+		var got Amount
+		err := got.Scan(tc.src)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("Scan(%#v) = %d, %v; want %d (err %v)", tc.src, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package payroll
+
+import (
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// defaultAnchor วันเริ่มงวดแรกของกลุ่ม biweekly/weekly ที่ไม่ได้กำหนด AnchorDate (วันจันทร์)
+var defaultAnchor = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// PeriodsPerYear จำนวนงวดมาตรฐานต่อปีของความถี่ (จำนวนงวดจริงของแต่ละปีดู Period.PerYear)
+func PeriodsPerYear(frequency string) int {
+	switch frequency {
+	case models.FrequencySemiMonthly:
+		return 24
+	case models.FrequencyBiweekly:
+		return 26
+	case models.FrequencyWeekly:
+		return 52
+	}
+	return 12
+}
+
+// PeriodAmount แปลงยอดรายเดือนเป็นยอดต่องวดของ period (monthly x 12 / จำนวนงวดจริงในปีนั้น)
+// ปีที่มี 27/53 งวดได้ยอดต่องวดน้อยลง ยอดทั้งปีจึงเท่ากับ monthly x 12 เสมอ
+func PeriodAmount(monthly money.Amount, period Period) money.Amount {
+	n := period.PerYear()
+	if n == 12 {
+		return monthly
+	}
+	return monthly.MulDiv(12, int64(n))
+}
+
+// Calendar ปฏิทินงวดของกลุ่มจ่ายเงินในปีภาษี year
+// งวดที่ "สิ้นสุด" ในปีใดนับเป็นงวดของปีนั้น (biweekly/weekly อาจมี 27/53 งวด)
+// group = nil คือกลุ่มรายเดือนตั้งต้น
+func Calendar(group *models.PayGroup, year int) ([]Period, error) {
+	if year < 1 {
+		return nil, ErrInvalidPeriod
+	}
+	freq := models.FrequencyMonthly
+	if group != nil && group.Frequency != "" {
+		freq = group.Frequency
+	}
+
+	var out []Period
+	switch freq {
+	case models.FrequencyMonthly:
+		for m := 1; m <= 12; m++ {
+			p, _ := MonthPeriod(year, m)
+			out = append(out, p)
+		}
+		return out, nil
+
+	case models.FrequencySemiMonthly:
+		for m := 1; m <= 12; m++ {
+			first := time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+			mid := first.AddDate(0, 0, 14)
+			out = append(out,
+				Period{Start: first, End: mid},
+				Period{Start: mid.AddDate(0, 0, 1), End: first.AddDate(0, 1, -1)},
+			)
+		}
+
+	case models.FrequencyBiweekly, models.FrequencyWeekly:
+		step := 14
+		if freq == models.FrequencyWeekly {
+			step = 7
+		}
+		anchor := defaultAnchor
+		if group.AnchorDate != nil {
+			anchor = dateOnly(*group.AnchorDate)
+		}
+		// งวดแรกของปีคืองวดที่ครอบคลุม 1 ม.ค. (เลื่อน anchor เป็นจำนวนเท่าของ step)
+		jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		k := daysBetween(anchor, jan1)
+		k = (k - ((k%step)+step)%step) / step
+		start := anchor.AddDate(0, 0, k*step)
+		for end := start.AddDate(0, 0, step-1); end.Year() == year; end = start.AddDate(0, 0, step-1) {
+			out = append(out, Period{Start: start, End: end})
+			start = start.AddDate(0, 0, step)
+		}
+
+	default:
+		return nil, ErrInvalidPeriod
+	}
+
+	for i := range out {
+		out[i].Index = i + 1
+		out[i].Count = len(out)
+		out[i].Frequency = freq
+	}
+	return out, nil
+}
+
+// PeriodOn งวดในปฏิทินที่ครอบคลุมวันที่ date
+func PeriodOn(group *models.PayGroup, date time.Time) (Period, error) {
+	date = dateOnly(date)
+	for _, y := range []int{date.Year(), date.Year() + 1} {
+		cal, err := Calendar(group, y)
+		if err != nil {
+			return Period{}, err
+		}
+		for _, p := range cal {
+			if !date.Before(p.Start) && !date.After(p.End) {
+				return p, nil
+			}
+		}
+	}
+	return Period{}, ErrInvalidPeriod
+}
+
+// PeriodNo งวดที่ no (1-based) ในปีภาษี year
+func PeriodNo(group *models.PayGroup, year, no int) (Period, error) {
+	cal, err := Calendar(group, year)
+	if err != nil {
+		return Period{}, err
+	}
+	if no < 1 || no > len(cal) {
+		return Period{}, ErrInvalidPeriod
+	}
+	return cal[no-1], nil
+}
+
+// RunPeriod งวดของ run: run ที่ระบุ PeriodStart ใช้ปฏิทินของกลุ่ม ไม่เช่นนั้นเป็นงวดเต็มเดือน
+func RunPeriod(run *models.PayrollRun, group *models.PayGroup) (Period, error) {
+	if run.PeriodStart == nil {
+		return MonthPeriod(run.PeriodYear, run.PeriodMonth)
+	}
+	p, err := PeriodOn(group, *run.PeriodStart)
+	if err != nil {
+		return Period{}, err
+	}
+	if !p.Start.Equal(dateOnly(*run.PeriodStart)) {
+		return Period{}, ErrInvalidPeriod
+	}
+	return p, nil
+}
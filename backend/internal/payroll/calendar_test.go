@@ -0,0 +1,52 @@
+package payroll
+
+import (
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// ยอดต่องวดหารด้วยจำนวนงวดจริงของปี: ปีที่มี 27/53 งวด ยอดทั้งปียังเท่ากับเงินเดือน x 12 (ต่างได้ไม่เกินเศษปัดสตางค์ต่องวด)
+func TestPeriodAmountUsesActualPeriodsInYear(t *testing.T) {
+	monthly := baht(30000)
+	cases := []struct {
+		freq       string
+		year, want int
+	}{
+		{models.FrequencyMonthly, 2026, 12},
+		{models.FrequencySemiMonthly, 2026, 24},
+		{models.FrequencyBiweekly, 2025, 26},
+		{models.FrequencyBiweekly, 2034, 27},
+		{models.FrequencyWeekly, 2025, 52},
+		{models.FrequencyWeekly, 2028, 53},
+	}
+	for _, tc := range cases {
+		g := &models.PayGroup{Frequency: tc.freq}
+		cal, err := Calendar(g, tc.year)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cal) != tc.want {
+			t.Fatalf("%s %d: %d periods, want %d", tc.freq, tc.year, len(cal), tc.want)
+		}
+		var year money.Amount
+		for _, p := range cal {
+			year += PeriodAmount(monthly, p)
+		}
+		if diff := monthly.Mul(12) - year; diff <= -money.Amount(len(cal)) || diff >= money.Amount(len(cal)) {
+			t.Errorf("%s %d: %d periods pay %s, want %s", tc.freq, tc.year, len(cal), year, monthly.Mul(12))
+		}
+		if want := monthly.MulDiv(12, int64(tc.want)); PeriodAmount(monthly, cal[0]) != want {
+			t.Errorf("%s %d: per period %s, want %s", tc.freq, tc.year, PeriodAmount(monthly, cal[0]), want)
+		}
+	}
+}
+
+// งวดที่ไม่รู้จำนวนงวดในปี (สร้างเอง) ใช้จำนวนงวดมาตรฐานของความถี่
+func TestPeriodAmountFallsBackToStandardCount(t *testing.T) {
+	p := Period{Frequency: models.FrequencyBiweekly}
+	if got, want := PeriodAmount(baht(26000), p), baht(12000); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,403 @@
+package payroll
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// YTD ยอดสะสมของพนักงานในปีภาษีเดียวกันก่อน run ที่กำลังคำนวณ
+// ยอด Period* คือส่วนที่จ่ายไปแล้วในเดือนเดียวกัน (run/งวดก่อนหน้า) ใช้คุมเพดาน SSO รายเดือน
+type YTD struct {
+	Income money.Amount `json:"income"`
+	SSO    money.Amount `json:"sso"`
+	PVD    money.Amount `json:"pvd"`
+	Tax    money.Amount `json:"tax"`
+
+	PeriodSSOWage     money.Amount `json:"periodSsoWage"`
+	PeriodSSO         money.Amount `json:"periodSso"`
+	PeriodEmployerSSO money.Amount `json:"periodEmployerSso"`
+}
+
+// OffCycle ฐานเงินได้ปกติต่องวดที่ใช้ประมาณการทั้งปีสำหรับ run นอกรอบ
+// (ภาษีของ run นอกรอบใช้วิธีเงินได้ครั้งเดียว ดู OneOffTax)
+type OffCycle struct {
+	Income    money.Amount `json:"income"`
+	SSO       money.Amount `json:"sso"`
+	PVD       money.Amount `json:"pvd"`
+	Remaining int          `json:"remaining"` // จำนวนงวดที่ยังจะจ่ายเงินได้ปกติ
+}
+
+// Input ข้อมูลทั้งหมดที่ต้องใช้คำนวณเงินเดือนของพนักงานหนึ่งคนในหนึ่ง run
+type Input struct {
+	Employee   models.Employee
+	Components []models.PayComponent // รายการประจำของพนักงาน (กรองตามงวดภายใน Calculate)
+	Inputs     []models.RunInput     // รายการจ่ายครั้งเดียวของ run นี้
+	Leaves     []models.Leave        // การลาของพนักงาน (ใช้หักลาไม่รับค่าจ้าง)
+	Salaries   []models.SalaryRecord // ประวัติเงินเดือน (ว่าง = ใช้ Employee.BaseSalary ทั้งงวด)
+	Retro      []RetroPay            // ส่วนต่างเงินเดือนย้อนหลังของงวดที่ปิดแล้ว (เฉพาะ run ปกติ)
+	Garnish    []GarnishmentDue      // คำสั่งอายัดตามลำดับความสำคัญ (หักหลังเงินหักตามกฎหมาย ก่อนเงินกู้)
+	Loans      []LoanDue             // ยอดผ่อนเงินกู้ที่ถึงกำหนด (หักหลังภาษี ไม่เกินเงินสุทธิที่เหลือ)
+	Period     Period
+	Rules      Rules
+	YTD        YTD
+	OffCycle   *OffCycle // nil = run ปกติ (เงินเดือน + ลาไม่รับค่าจ้าง + PVD)
+}
+
+// Result ผลการคำนวณแบบแจกแจงรายการ ยอดรวมทั้งหมด derive จาก Lines
+type Result struct {
+	EmployeeID    uint                 `json:"employeeId"`
+	WorkedDays    int                  `json:"workedDays"`
+	TotalDays     int                  `json:"totalDays"`
+	Salary        money.Amount         `json:"salary"`
+	UnpaidDays    int                  `json:"unpaidDays"`
+	UnpaidLeave   money.Amount         `json:"unpaidLeave"`
+	Retro         money.Amount         `json:"retro"`
+	Gross         money.Amount         `json:"gross"`
+	TaxableIncome money.Amount         `json:"taxableIncome"`
+	SSOBase       money.Amount         `json:"ssoBase"`
+	SSO           money.Amount         `json:"sso"`
+	EmployerSSO   money.Amount         `json:"employerSso"`
+	PVD           money.Amount         `json:"pvd"`
+	Tax           money.Amount         `json:"tax"`
+	ExtraTax      money.Amount         `json:"extraTax"`
+	Deductions    money.Amount         `json:"deductions"`
+	NetPay        money.Amount         `json:"netPay"`
+	TaxDetail     TaxResult            `json:"taxDetail"`
+	RetroTax      TaxResult            `json:"retroTax"`
+	Lines         []models.PayrollLine `json:"lines"`
+	Trace         Trace                `json:"trace"`
+}
+
+// Calculate คำนวณเงินเดือนของพนักงานหนึ่งคน
+// run ปกติคืนค่า ok = false เมื่อพนักงานไม่มีวันทำงานในงวดนี้
+// run นอกรอบคืนค่า ok = false เมื่อไม่มีรายการใดให้จ่าย
+func Calculate(in Input) (Result, bool) {
+	e := in.Employee
+	worked, total := overlapDays(e.HiredAt, e.TerminatedAt, in.Period)
+	if in.OffCycle == nil && (total <= 0 || worked <= 0) {
+		return Result{}, false
+	}
+
+	res := Result{
+		EmployeeID: e.ID,
+		WorkedDays: worked,
+		TotalDays:  total,
+		Trace: Trace{
+			Rules:  ruleVersion(in.Rules),
+			Period: in.Period,
+			Days:   DaysTrace{HiredAt: e.HiredAt, TerminatedAt: e.TerminatedAt, Worked: worked, Total: total},
+			Retro:  in.Retro,
+		},
+	}
+
+	// เงินเดือนและรายการประจำเป็นยอดรายเดือน แปลงเป็นยอดต่องวดตามจำนวนงวดในปีของงวดนี้
+	var deductions []models.PayrollLine
+	var voluntary money.Amount // เงินหักตามความยินยอมของพนักงาน (ไม่รวมลาไม่รับค่าจ้าง/ส่วนต่างติดลบ)
+	if in.OffCycle == nil {
+		res.add(models.PayrollLine{
+			Code: models.CodeWorkedDays, Description: "Worked days", Category: models.LineInfo,
+			Quantity: float64(worked), Rate: float64(total),
+		})
+
+		// เงินเดือนตามสัดส่วนวันทำงาน แยกช่วงตามอัตราเงินเดือนที่มีผลเมื่อเงินเดือนเปลี่ยนกลางงวด
+		from := maxTime(in.Period.Start, dateOnly(e.HiredAt))
+		to := in.Period.End
+		if e.TerminatedAt != nil {
+			to = minTime(to, dateOnly(*e.TerminatedAt))
+		}
+		segs := salarySegments(in.Salaries, e.BaseSalary, from, to)
+		for _, seg := range segs {
+			periodPay := PeriodAmount(seg.Monthly, in.Period)
+			days := daysBetween(seg.From, seg.To) + 1
+			amount := periodPay.MulDiv(int64(days), int64(total))
+			res.Salary += amount
+			res.Trace.Salary = append(res.Trace.Salary, SalaryTrace{
+				From: seg.From, To: seg.To, Monthly: seg.Monthly, PeriodAmount: periodPay, Days: days, Amount: amount,
+			})
+			desc := "Base Salary"
+			if len(segs) > 1 {
+				desc = fmt.Sprintf("Base Salary %s – %s", seg.From.Format("2006-01-02"), seg.To.Format("2006-01-02"))
+			}
+			res.add(models.PayrollLine{
+				Code: models.CodeBaseSalary, Description: desc, Category: models.LineEarning,
+				Amount: amount, Quantity: float64(days), Rate: periodPay.Div(int64(total)).Baht(),
+				Taxable: true, SSOable: true,
+			})
+
+			// ลาไม่รับค่าจ้าง: หักตามจำนวนวัน x ค่าจ้างรายวันของอัตราที่มีผลในวันลา (ไม่เกินเงินเดือนของช่วง)
+			// เป็นเงินหักก่อนภาษีและลดฐานค่าจ้าง SSO/PVD
+			if leaveDays, divisor := unpaidLeave(e, in.Leaves, in.Period, seg.From, seg.To); leaveDays > 0 {
+				dailyBase := seg.Monthly
+				if dailyRateBasis == DailyRateWorkingDays {
+					dailyBase = periodPay
+				}
+				cut := money.Min(dailyBase.MulDiv(int64(leaveDays), int64(divisor)), amount)
+				res.UnpaidDays += leaveDays
+				res.UnpaidLeave += cut
+				res.Trace.UnpaidLeave = append(res.Trace.UnpaidLeave, LeaveTrace{
+					From: seg.From, To: seg.To, Basis: dailyRateBasis, DailyBase: dailyBase, Days: leaveDays, Divisor: divisor, Amount: cut,
+				})
+				deductions = append(deductions, models.PayrollLine{
+					Code: models.CodeUnpaidLeave, Description: "Unpaid Leave", Category: models.LineDeduction,
+					Amount: cut, Quantity: float64(leaveDays), Rate: dailyBase.Div(int64(divisor)).Baht(), Taxable: true, SSOable: true,
+				})
+			}
+		}
+		res.Gross = res.Salary
+		res.TaxableIncome = res.Salary - res.UnpaidLeave
+		res.SSOBase = res.Salary - res.UnpaidLeave
+		res.Deductions = res.UnpaidLeave
+
+		// ส่วนต่างย้อนหลัง: เป็นค่าจ้างของเดือนที่จ่าย (นับเป็นเงินได้และค่าจ้าง SSO ของงวดนี้)
+		// ยอดติดลบ (ปรับลดย้อนหลัง) เป็นเงินหักก่อนภาษี
+		for _, rp := range in.Retro {
+			if rp.Amount == 0 {
+				continue
+			}
+			ref := rp.RunID
+			line := models.PayrollLine{
+				Code:        models.CodeRetroPay,
+				Description: fmt.Sprintf("Retro Pay %s – %s", rp.Period.Start.Format("2006-01-02"), rp.Period.End.Format("2006-01-02")),
+				Amount:      rp.Amount, Taxable: true, SSOable: true, RefRunID: &ref,
+			}
+			res.Retro += rp.Amount
+			if rp.Amount > 0 {
+				line.Category = models.LineEarning
+				res.Gross += rp.Amount
+				res.add(line)
+			} else {
+				line.Category = models.LineDeduction
+				line.Amount = -rp.Amount
+				res.Deductions += line.Amount
+				deductions = append(deductions, line)
+			}
+		}
+		res.TaxableIncome += res.Retro
+		res.SSOBase += res.Retro
+	}
+
+	// รายการประจำ: เงินได้คิดตามสัดส่วนวันที่มีผลในงวด (ตัดช่วงที่ไม่ได้ทำงาน), เงินหักหักเต็มจำนวน
+	for _, pc := range in.Components {
+		if !pc.ActiveBetween(in.Period.Start, in.Period.End) {
+			continue
+		}
+		perPeriod := PeriodAmount(pc.Amount, in.Period)
+		ct := ComponentTrace{Code: pc.Code, Kind: pc.Kind, Monthly: pc.Amount, PeriodAmount: perPeriod, Amount: perPeriod}
+		if pc.Kind != models.ComponentEarning {
+			res.Trace.Components = append(res.Trace.Components, ct)
+			res.Deductions += perPeriod
+			voluntary += perPeriod
+			deductions = append(deductions, models.PayrollLine{
+				Code: pc.Code, Description: pc.Name, Category: models.LineDeduction, Amount: perPeriod,
+			})
+			continue
+		}
+		active, _ := overlapDays(maxTime(pc.StartDate, e.HiredAt), minEnd(pc.EndDate, e.TerminatedAt), in.Period)
+		if active <= 0 {
+			continue
+		}
+		amount := perPeriod.MulDiv(int64(active), int64(total))
+		ct.ActiveDays, ct.Amount = active, amount
+		res.Trace.Components = append(res.Trace.Components, ct)
+		res.Gross += amount
+		if pc.Taxable {
+			res.TaxableIncome += amount
+		}
+		if pc.SSOable {
+			res.SSOBase += amount
+		}
+		res.add(models.PayrollLine{
+			Code: pc.Code, Description: pc.Name, Category: models.LineEarning,
+			Amount: amount, Quantity: float64(active), Taxable: pc.Taxable, SSOable: pc.SSOable,
+		})
+	}
+
+	// รายการจ่ายครั้งเดียว: จ่าย/หักเต็มจำนวน เงินหักที่ Taxable/SSOable ลดฐานภาษี/SSO (หักก่อนภาษี)
+	for _, ri := range in.Inputs {
+		if ri.EmployeeID != e.ID || ri.Amount == 0 {
+			continue
+		}
+		line := models.PayrollLine{
+			Code: ri.Code, Description: ri.Description, Amount: ri.Amount, Taxable: ri.Taxable, SSOable: ri.SSOable,
+		}
+		sign := money.Amount(1)
+		if ri.Kind == models.ComponentDeduction {
+			line.Category = models.LineDeduction
+			res.Deductions += ri.Amount
+			voluntary += ri.Amount
+			deductions = append(deductions, line)
+			sign = -1
+		} else {
+			line.Category = models.LineEarning
+			res.Gross += ri.Amount
+			res.add(line)
+		}
+		if ri.Taxable {
+			res.TaxableIncome += sign * ri.Amount
+		}
+		if ri.SSOable {
+			res.SSOBase += sign * ri.Amount
+		}
+	}
+
+	if in.OffCycle != nil && len(res.Lines) == 0 && len(deductions) == 0 {
+		return Result{}, false
+	}
+
+	// ใช้การตั้งค่ารายบุคคล: พนักงานที่ไม่อยู่ในระบบประกันสังคมไม่ต้องหัก SSO
+	// SSO เป็นยอดรายเดือน: คิดจากค่าจ้างรวมของเดือนแล้วหักส่วนที่ run/งวดก่อนหน้าในเดือนเดียวกันหักไปแล้ว
+	st := SSOTrace{
+		Enabled: e.SSOEnabled, Wage: res.SSOBase, PriorWage: in.YTD.PeriodSSOWage,
+		MinBase: in.Rules.SSOMinBase, MaxBase: in.Rules.SSOMaxBase,
+		Rate: in.Rules.SSORate, PriorDeducted: in.YTD.PeriodSSO,
+		EmployerRate: in.Rules.SSOEmployerRate, PriorEmployer: in.YTD.PeriodEmployerSSO,
+	}
+	if e.SSOEnabled && (in.OffCycle == nil || res.SSOBase > 0) {
+		base := ssoBase(in.YTD.PeriodSSOWage+res.SSOBase, in.Rules)
+		res.SSO = money.Max(base.MulRate(in.Rules.SSORate)-in.YTD.PeriodSSO, 0)
+		res.EmployerSSO = money.Max(base.MulRate(in.Rules.SSOEmployerRate)-in.YTD.PeriodEmployerSSO, 0)
+		st.Base, st.Capped = base, in.YTD.PeriodSSOWage+res.SSOBase > in.Rules.SSOMaxBase
+	}
+	st.Employee, st.Employer = res.SSO, res.EmployerSSO
+	res.Trace.SSO = st
+
+	// PVD คิดจากเงินเดือน (ค่าจ้างที่จ่ายจริง รวมส่วนต่างย้อนหลัง) เท่านั้น
+	pvdBase := money.Max(res.Salary-res.UnpaidLeave+res.Retro, 0)
+	res.PVD = pvdBase.MulRate(e.PVDRate)
+	res.Trace.PVD = PVDTrace{Base: pvdBase, Rate: e.PVDRate, Amount: res.PVD}
+
+	if oc := in.OffCycle; oc != nil {
+		regular := TaxInput{
+			Income:    oc.Income,
+			SSO:       oc.SSO,
+			PVD:       oc.PVD,
+			YTDIncome: in.YTD.Income,
+			YTDSSO:    in.YTD.SSO,
+			YTDPVD:    in.YTD.PVD,
+			YTDTax:    in.YTD.Tax,
+		}
+		res.TaxDetail = OneOffTax(regular, oc.Remaining, res.TaxableIncome, res.SSO, in.Rules)
+		res.Trace.Tax = taxTrace(TaxMethodOneOff, regular, res.TaxDetail, in.Rules)
+		res.Trace.Tax.OneOff = res.TaxableIncome
+	} else {
+		// ประมาณการทั้งปีจากเงินได้ปกติของงวด (ไม่รวมส่วนต่างย้อนหลังซึ่งไม่ได้เกิดทุกงวด)
+		regularSSO, regularPVD := res.SSO, res.PVD
+		if e.SSOEnabled && res.Retro != 0 {
+			base := ssoBase(in.YTD.PeriodSSOWage+res.SSOBase-res.Retro, in.Rules)
+			regularSSO = money.Max(base.MulRate(in.Rules.SSORate)-in.YTD.PeriodSSO, 0)
+		}
+		if res.Retro != 0 {
+			regularPVD = (res.Salary - res.UnpaidLeave).MulRate(e.PVDRate)
+		}
+		// งวดที่ไม่ใช่รายเดือน: SSO หักเต็มเพดานในงวดแรกของเดือน จึงประมาณการทั้งปีจาก SSO เฉลี่ยต่องวด
+		taxSSO := regularSSO
+		if n := in.Period.PerYear(); n != 12 && e.SSOEnabled {
+			monthly := ssoBase((res.SSOBase-res.Retro).MulDiv(int64(n), 12), in.Rules).MulRate(in.Rules.SSORate)
+			taxSSO = monthly.MulDiv(12, int64(n))
+		}
+		regular := TaxInput{
+			Month:     int(in.Period.End.Month()),
+			Remaining: in.Period.Remaining(),
+			Income:    res.TaxableIncome - res.Retro,
+			SSO:       taxSSO,
+			PVD:       regularPVD,
+			YTDIncome: in.YTD.Income,
+			YTDSSO:    in.YTD.SSO,
+			YTDPVD:    in.YTD.PVD,
+			YTDTax:    in.YTD.Tax,
+		}
+		res.TaxDetail = WithholdingTax(regular, in.Rules)
+		res.Trace.Tax = taxTrace(TaxMethodAnnualized, regular, res.TaxDetail, in.Rules)
+		// ภาษีของส่วนต่างย้อนหลังคิดแบบเงินได้ครั้งเดียว บวกเพิ่มจากภาษีของงวด
+		if res.Retro != 0 {
+			res.RetroTax = OneOffTax(regular, in.Period.Remaining(), res.Retro, res.SSO-regularSSO, in.Rules)
+			res.TaxDetail.Withholding += res.RetroTax.Withholding
+			rt := taxTrace(TaxMethodOneOff, regular, res.RetroTax, in.Rules)
+			rt.OneOff = res.Retro
+			res.Trace.RetroTax = &rt
+		}
+	}
+	// WithholdingRate คืออัตราหักเพิ่มแบบคงที่ตามที่พนักงานขอ บวกเพิ่มจากภาษีตามกฎหมาย
+	res.ExtraTax = money.Max(res.TaxableIncome, 0).MulRate(e.WithholdingRate)
+	res.Tax = res.TaxDetail.Withholding + res.ExtraTax
+
+	res.add(models.PayrollLine{Code: models.CodeTax, Description: "Tax Withheld", Category: models.LineDeduction, Amount: res.TaxDetail.Withholding})
+	if res.ExtraTax > 0 {
+		res.add(models.PayrollLine{Code: models.CodeTaxExtra, Description: "Additional Withholding", Category: models.LineDeduction, Amount: res.ExtraTax, Rate: e.WithholdingRate})
+	}
+	if in.OffCycle == nil || res.SSO > 0 {
+		res.add(models.PayrollLine{Code: models.CodeSSO, Description: "Social Security (SSO)", Category: models.LineDeduction, Amount: res.SSO, Rate: in.Rules.SSORate})
+	}
+	if in.OffCycle == nil {
+		res.add(models.PayrollLine{Code: models.CodePVD, Description: "Provident Fund (PVD)", Category: models.LineDeduction, Amount: res.PVD, Rate: e.PVDRate})
+	}
+	res.Lines = append(res.Lines, deductions...)
+	if res.EmployerSSO > 0 {
+		res.add(models.PayrollLine{Code: models.CodeSSOEmployer, Description: "Employer SSO Contribution", Category: models.LineEmployer, Amount: res.EmployerSSO, Rate: in.Rules.SSOEmployerRate})
+	}
+
+	res.NetPay = res.Gross - res.Tax - res.SSO - res.PVD - res.Deductions
+
+	// ลำดับการหัก: ภาษี/SSO/PVD และลาไม่รับค่าจ้าง → คำสั่งอายัด → เงินหักตามความยินยอม (รายการประจำ/รายการครั้งเดียว) → เงินกู้
+	// อายัดคิดจากเงินได้หลังหักตามกฎหมาย (statutoryNet) และยอดคุ้มครองเทียบกับยอดเดียวกันหลังหักอายัดลำดับก่อนหน้า
+	// เงินหักตามความยินยอมจึงไม่ลดยอดอายัด แม้จะอยู่ในบรรทัดก่อน (เงินหักเหล่านี้หักเต็มจำนวน เงินสุทธิอาจต่ำกว่ายอดคุ้มครองได้)
+	statutoryNet := res.NetPay + voluntary
+	avail := statutoryNet
+	for _, g := range in.Garnish {
+		g.apply(statutoryNet, avail)
+		res.Trace.Garnishments = append(res.Trace.Garnishments, g)
+		if g.Amount <= 0 {
+			continue
+		}
+		id := g.GarnishmentID
+		res.add(models.PayrollLine{Code: models.CodeGarnish, Description: g.description(), Category: models.LineDeduction, Amount: g.Amount, Rate: g.Rate, RefGarnishmentID: &id})
+		res.Deductions += g.Amount
+		res.NetPay -= g.Amount
+		avail -= g.Amount
+	}
+
+	// ผ่อนชำระเงินกู้: หักตามลำดับเงินกู้ได้ไม่เกินเงินสุทธิที่เหลือ ส่วนที่หักไม่ได้ยกไปงวดถัดไป
+	for _, ld := range in.Loans {
+		ld.Amount = money.Min(ld.Due, money.Max(res.NetPay, 0))
+		res.Trace.Loans = append(res.Trace.Loans, ld)
+		if ld.Amount <= 0 {
+			continue
+		}
+		id := ld.LoanID
+		res.add(models.PayrollLine{Code: models.CodeLoan, Description: ld.Description, Category: models.LineDeduction, Amount: ld.Amount, RefLoanID: &id})
+		res.Deductions += ld.Amount
+		res.NetPay -= ld.Amount
+	}
+	res.Trace.Gross, res.Trace.TaxableIncome = res.Gross, res.TaxableIncome
+	res.Trace.WithholdingRate, res.Trace.ExtraTax = e.WithholdingRate, res.ExtraTax
+	return res, true
+}
+
+func (r *Result) add(l models.PayrollLine) {
+	r.Lines = append(r.Lines, l)
+}
+
+// Item แปลงผลการคำนวณเป็น PayrollItem ของ run (ยอดรวมเดิม derive จาก Lines) พร้อม trace การคำนวณ
+func (r Result) Item(runID uint) *models.PayrollItem {
+	trace, _ := json.Marshal(r.Trace)
+	item := &models.PayrollItem{
+		RunID:      runID,
+		EmployeeID: r.EmployeeID,
+		Lines:      append([]models.PayrollLine(nil), r.Lines...),
+		Trace:      trace,
+	}
+	item.SyncTotals()
+	return item
+}
+
+// ssoBase ค่าจ้างที่ใช้คิด SSO (บีบให้อยู่ในช่วงฐานขั้นต่ำ–เพดาน)
+// ฐานขั้นต่ำใช้กับเดือนที่มีค่าจ้างเท่านั้น: เดือนที่ไม่มีค่าจ้างเลย (เช่น ลาไม่รับค่าจ้างทั้งเดือน) ไม่ต้องส่งเงินสมทบ
+func ssoBase(wage money.Amount, rules Rules) money.Amount {
+	if wage <= 0 {
+		return 0
+	}
+	return money.Min(money.Max(wage, rules.SSOMinBase), rules.SSOMaxBase)
+}
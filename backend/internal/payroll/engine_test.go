@@ -0,0 +1,201 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+// เงินเดือนตามสัดส่วนวันทำงาน SSO ตามฐานต่ำสุด/เพดานของปี และ PVD คิดเป็นสตางค์
+// เงินสุทธิต้องเท่ากับ gross − ภาษี − SSO − PVD − เงินหัก (ลาไม่รับค่าจ้าง) พอดี
+func TestCalculateSSOAndProration(t *testing.T) {
+	cases := []struct {
+		name    string
+		year    int
+		salary  float64
+		hired   time.Time
+		sso     bool
+		pvdRate float64
+		unpaid  bool    // ลาไม่รับค่าจ้างทั้งเดือน
+		allow   float64 // ค่าตอบแทนที่ไม่ใช่ค่าจ้าง (ไม่นำมาคิด SSO)
+		gross   float64
+		wantSSO float64
+		wantPVD float64
+	}{
+		{name: "below the cap", year: 2026, salary: 12000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, gross: 12000, wantSSO: 600},
+		{name: "capped at 15,000 before 2026", year: 2025, salary: 50000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, gross: 50000, wantSSO: 750},
+		{name: "capped at 17,500 from 2026", year: 2026, salary: 50000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, gross: 50000, wantSSO: 875},
+		{name: "minimum base 1,650", year: 2026, salary: 1000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, gross: 1000, wantSSO: 82.50},
+		// ไม่มีค่าจ้างในเดือน: ไม่ใช้ฐานขั้นต่ำ SSO = 0
+		{name: "full month of unpaid leave", year: 2026, salary: 30000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, unpaid: true, gross: 30000},
+		{name: "only non-wage items", year: 2026, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), sso: true, allow: 3000, gross: 3000},
+		{name: "not insured", year: 2026, salary: 50000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), gross: 50000},
+		{
+			// เข้างาน 16 มี.ค.: 31,000 x 16/31 = 16,000
+			name: "hired mid-month", year: 2026, salary: 31000, hired: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), sso: true,
+			gross: 16000, wantSSO: 800,
+		},
+		{
+			// 25,000 x 10/31 = 8,064.516 → 8,064.52; PVD 3% = 241.9356 → 241.94
+			name: "odd proration rounds once", year: 2026, salary: 25000, hired: time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC), sso: true, pvdRate: 0.03,
+			gross: 8064.52, wantSSO: 403.23, wantPVD: 241.94,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			period, err := MonthPeriod(tc.year, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := Input{
+				Employee: models.Employee{ID: 1, BaseSalary: money.FromBaht(tc.salary), SSOEnabled: tc.sso, PVDRate: tc.pvdRate, HiredAt: tc.hired},
+				Period:   period,
+				Rules:    RulesFor(nil, period.End),
+			}
+			if tc.unpaid {
+				in.Leaves = []models.Leave{{EmployeeID: 1, Type: models.LeaveUnpaid, StartDate: period.Start, EndDate: period.End, Status: models.LeaveApproved}}
+			}
+			if tc.allow > 0 {
+				in.Components = []models.PayComponent{{EmployeeID: 1, Code: "ALLOW", Name: "Allowance", Kind: models.ComponentEarning,
+					Amount: money.FromBaht(tc.allow), StartDate: tc.hired, Taxable: true}}
+			}
+			res, ok := Calculate(in)
+			if !ok {
+				t.Fatal("Calculate returned ok = false")
+			}
+			if res.Gross != baht(tc.gross) || res.SSO != baht(tc.wantSSO) || res.PVD != baht(tc.wantPVD) {
+				t.Fatalf("gross/sso/pvd = %s/%s/%s, want %.2f/%.2f/%.2f", res.Gross, res.SSO, res.PVD, tc.gross, tc.wantSSO, tc.wantPVD)
+			}
+			if tc.unpaid && res.UnpaidLeave != res.Salary {
+				t.Fatalf("unpaid leave %s, want the whole salary %s", res.UnpaidLeave, res.Salary)
+			}
+			if res.NetPay != res.Gross-res.Tax-res.SSO-res.PVD-res.Deductions {
+				t.Fatalf("net %s != %s − %s − %s − %s − %s", res.NetPay, res.Gross, res.Tax, res.SSO, res.PVD, res.Deductions)
+			}
+		})
+	}
+}
+
+// ยอดรวมของ run เท่ากับผลรวมของ items พอดี (ไม่มีเศษจากการปัดซ้ำ)
+func TestTotalsEqualSumOfItems(t *testing.T) {
+	period, err := MonthPeriod(2026, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// เงินเดือนที่ prorate แล้วมีเศษสตางค์ทุกคน
+	hires := []struct {
+		salary float64
+		hired  time.Time
+	}{
+		{33333.33, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{27777.77, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{15555.55, time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC)},
+	}
+	var items []models.PayrollItem
+	var gross, net money.Amount
+	for i, h := range hires {
+		res, ok := Calculate(Input{
+			Employee: models.Employee{ID: uint(i + 1), BaseSalary: money.FromBaht(h.salary), SSOEnabled: true, PVDRate: 0.05, HiredAt: h.hired},
+			Period:   period,
+			Rules:    RulesFor(nil, period.End),
+		})
+		if !ok {
+			t.Fatalf("employee %d not calculated", i+1)
+		}
+		items = append(items, *res.Item(1))
+		gross += res.Gross
+		net += res.NetPay
+	}
+	tot := Totals(items)
+	if tot.Employees != len(hires) || tot.Gross != gross || tot.NetPay != net {
+		t.Fatalf("totals = %+v, want gross %s net %s", tot, gross, net)
+	}
+	if tot.NetPay != tot.Gross-tot.TaxWithheld-tot.SSO-tot.PVD {
+		t.Fatalf("run net %s != %s − %s − %s − %s", tot.NetPay, tot.Gross, tot.TaxWithheld, tot.SSO, tot.PVD)
+	}
+}
+
+// SSO เป็นยอดรายเดือน: ค่าจ้างรวมของเดือน (รวม run ก่อนหน้าใน YTD.Period*) อยู่ระหว่างฐานต่ำสุด/เพดาน
+// แล้วหักส่วนที่หักไปแล้วในเดือนเดียวกัน
+func TestCalculateSSOMonthToDate(t *testing.T) {
+	cases := []struct {
+		name                string
+		salary              float64
+		priorWage, priorSSO float64 // run ก่อนหน้าในเดือนเดียวกัน (ฝั่งนายจ้างเท่ากัน)
+		wantSSO             float64
+	}{
+		{name: "first run of the month", salary: 10000, wantSSO: 500},
+		// รวม 20,000 ชนเพดาน 17,500 → 875 − 500
+		{name: "second run tops up to the cap", salary: 10000, priorWage: 10000, priorSSO: 500, wantSSO: 375},
+		{name: "cap already reached", salary: 10000, priorWage: 20000, priorSSO: 875, wantSSO: 0},
+		// ฐานต่ำสุดคิดจากค่าจ้างรวมของเดือน ไม่ใช่ของแต่ละ run: 1,650 x 5% − 82.50
+		{name: "minimum base counts the month", salary: 1000, priorWage: 1000, priorSSO: 82.50, wantSSO: 17.50},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			period, err := MonthPeriod(2026, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, ok := Calculate(Input{
+				Employee: models.Employee{ID: 1, BaseSalary: money.FromBaht(tc.salary), SSOEnabled: true, HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Period:   period,
+				Rules:    RulesFor(nil, period.End),
+				YTD:      YTD{PeriodSSOWage: baht(tc.priorWage), PeriodSSO: baht(tc.priorSSO), PeriodEmployerSSO: baht(tc.priorSSO)},
+			})
+			if !ok {
+				t.Fatal("Calculate returned ok = false")
+			}
+			if res.SSO != baht(tc.wantSSO) || res.EmployerSSO != baht(tc.wantSSO) {
+				t.Fatalf("sso/employer = %s/%s, want %.2f", res.SSO, res.EmployerSSO, tc.wantSSO)
+			}
+		})
+	}
+}
+
+// run โบนัสในเดือนเดียวกันได้ยอด SSO ของ run ปกติผ่าน YearToDate และหักเพิ่มเฉพาะส่วนที่ยังไม่ชนเพดาน
+func TestBonusRunSSOUsesMonthToDate(t *testing.T) {
+	const (
+		salary = 12000 // SSO ของ run ปกติ 600
+		bonus  = 10000 // ค่าจ้างรวมของเดือน 22,000 ชนเพดาน 17,500 → 875 − 600
+		want   = 275
+	)
+	st := storage.New()
+	s := NewService(st)
+	e := &models.Employee{EmpCode: "E1", FirstName: "E1", BaseSalary: money.FromBaht(salary), SSOEnabled: true, Status: "active",
+		HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	reg := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 3, Type: models.RunRegular, Status: models.RunDraft}
+	if err := st.CreatePayrollRun(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CalculateRun(reg.ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	bon := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 3, Type: models.RunBonus, Status: models.RunDraft}
+	if err := st.CreatePayrollRun(bon); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateRunInput(&models.RunInput{RunID: bon.ID, EmployeeID: e.ID, Code: "BONUS", Description: "Bonus",
+		Kind: models.ComponentEarning, Amount: money.FromBaht(bonus), Taxable: true, SSOable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CalculateRun(bon.ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	items, err := st.ListPayrollItems(bon.ID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("bonus items = %d, err %v", len(items), err)
+	}
+	if got := items[0].SumCodes(models.CodeSSO); got != money.FromBaht(want) {
+		t.Fatalf("bonus SSO = %s, want %d.00", got, want)
+	}
+	if got := items[0].SumCodes(models.CodeSSOEmployer); got != money.FromBaht(want) {
+		t.Fatalf("bonus employer SSO = %s, want %d.00", got, want)
+	}
+}
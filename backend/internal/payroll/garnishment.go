@@ -0,0 +1,229 @@
+package payroll
+
+import (
+	"fmt"
+	"sort"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// DefaultGarnishmentProtected เงินเดือนที่ไม่อยู่ในข่ายบังคับคดี (ป.วิ.พ. ม.302: ไม่เกินเดือนละ 20,000 บาท)
+// ใช้เมื่อคำสั่งอายัดไม่ได้ระบุยอดที่พนักงานต้องได้รับ
+var DefaultGarnishmentProtected = money.FromBaht(20000)
+
+// GarnishmentDue คำสั่งอายัดที่ต้องหักใน run พร้อมผลการคำนวณ
+// Computed = Rate x Base (หรือ Fixed) ไม่เกิน Cap และ Balance
+// Amount = ยอดที่หักได้จริงโดยเงินสุทธิไม่ต่ำกว่า Protected
+type GarnishmentDue struct {
+	GarnishmentID uint         `json:"garnishmentId"`
+	OrderNo       string       `json:"orderNo"`
+	Creditor      string       `json:"creditor"`
+	Rate          float64      `json:"rate,omitempty"`
+	Fixed         money.Amount `json:"fixed,omitempty"`
+	Cap           money.Amount `json:"cap,omitempty"`
+	Balance       money.Amount `json:"balance"`
+	Protected     money.Amount `json:"protected"` // ยอดต่องวด
+	Base          money.Amount `json:"base"`      // เงินได้หลังหักภาษี/SSO/PVD และลาไม่รับค่าจ้าง
+	Computed      money.Amount `json:"computed"`
+	Amount        money.Amount `json:"amount"`
+}
+
+// apply คำนวณยอดอายัดจากเงินได้หลังหักตามกฎหมาย (base) และยอดที่เหลือหลังอายัดลำดับก่อนหน้า (net)
+func (g *GarnishmentDue) apply(base, net money.Amount) {
+	g.Base = base
+	g.Computed = g.Fixed
+	if g.Rate > 0 {
+		g.Computed = money.Max(base, 0).MulRate(g.Rate)
+	}
+	if g.Cap > 0 {
+		g.Computed = money.Min(g.Computed, g.Cap)
+	}
+	g.Computed = money.Min(g.Computed, g.Balance)
+	g.Amount = money.Max(money.Min(g.Computed, net-g.Protected), 0)
+}
+
+func (g GarnishmentDue) description() string {
+	return fmt.Sprintf("Garnishment %s (%s)", g.OrderNo, g.Creditor)
+}
+
+// garnishmentDues คำสั่งอายัดของพนักงานที่มีผลใน run เรียงตามลำดับความสำคัญ (Priority แล้วตามลำดับที่รับคำสั่ง)
+// หักใน run ปกติและ run termination; ยอดคงเหลือหักส่วนที่อยู่ใน run อื่นที่ยังไม่อนุมัติ
+func (s *Service) garnishmentDues(e models.Employee, run *models.PayrollRun, period Period, rc *runCache) ([]GarnishmentDue, error) {
+	if run.OffCycle() && run.Type != models.RunTermination {
+		return nil, nil
+	}
+	orders, err := s.Store.ListGarnishments(e.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].Priority != orders[j].Priority {
+			return orders[i].Priority < orders[j].Priority
+		}
+		return orders[i].ID < orders[j].ID
+	})
+
+	var out []GarnishmentDue
+	for _, g := range orders {
+		if g.Status != models.GarnishmentActive || g.Outstanding <= 0 || dateOnly(g.StartDate).After(period.End) {
+			continue
+		}
+		pending, err := rc.pending(models.CodeGarnish, g.ID, run)
+		if err != nil {
+			return nil, err
+		}
+		left := g.Outstanding - pending
+		if left <= 0 {
+			continue
+		}
+		out = append(out, GarnishmentDue{
+			GarnishmentID: g.ID,
+			OrderNo:       g.OrderNo,
+			Creditor:      g.Creditor,
+			Rate:          g.Rate,
+			Fixed:         g.Amount,
+			Cap:           g.Cap,
+			Balance:       left,
+			Protected:     PeriodAmount(g.Protected, period),
+		})
+	}
+	return out, nil
+}
+
+// postGarnishments บันทึกยอดนำส่งตามบรรทัด GARNISH ของ run ที่อนุมัติ และลดยอดคงเหลือของคำสั่งอายัด
+func (s *Service) postGarnishments(run *models.PayrollRun, by string) error {
+	items, err := s.Store.ListPayrollItems(run.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range runRefIDs(items, models.CodeGarnish) {
+		var amt money.Amount
+		for _, it := range items {
+			amt += refAmount(it, models.CodeGarnish, id)
+		}
+		g, err := s.Store.GetGarnishment(id)
+		if err != nil {
+			return err
+		}
+		if amt > g.Outstanding {
+			return fmt.Errorf("%w: garnishment %s deducts %s, outstanding %s", ErrStaleDeductions, g.OrderNo, amt, g.Outstanding)
+		}
+		if err := s.Store.CreateGarnishmentRemittance(&models.GarnishmentRemittance{GarnishmentID: id, RunID: run.ID, Amount: amt, CreatedBy: by}); err != nil {
+			return err
+		}
+		g.Outstanding -= amt
+		if g.Outstanding <= 0 {
+			g.Outstanding, g.Status = 0, models.GarnishmentSatisfied
+		}
+		if err := s.Store.UpdateGarnishment(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseGarnishments ยกเลิกยอดนำส่งของ run ที่ถูกยกเลิกอนุมัติ (คืนยอดคงเหลือ)
+func (s *Service) reverseGarnishments(run *models.PayrollRun) error {
+	items, err := s.Store.ListPayrollItems(run.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range runRefIDs(items, models.CodeGarnish) {
+		g, err := s.Store.GetGarnishment(id)
+		if err != nil {
+			return err
+		}
+		rems, err := s.Store.ListGarnishmentRemittances(id)
+		if err != nil {
+			return err
+		}
+		for _, r := range rems {
+			if r.RunID != run.ID {
+				continue
+			}
+			if err := s.Store.DeleteGarnishmentRemittance(r.ID); err != nil {
+				return err
+			}
+			g.Outstanding += r.Amount
+		}
+		if g.Status == models.GarnishmentSatisfied && g.Outstanding > 0 {
+			g.Status = models.GarnishmentActive
+		}
+		if err := s.Store.UpdateGarnishment(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemittanceReport ยอดอายัดที่ต้องนำส่งของ run แยกตามเจ้าหนี้
+type RemittanceReport struct {
+	RunID     uint                 `json:"runId"`
+	Creditors []CreditorRemittance `json:"creditors"`
+	Total     money.Amount         `json:"total"`
+}
+
+// CreditorRemittance ยอดนำส่งของเจ้าหนี้หนึ่งราย
+type CreditorRemittance struct {
+	Creditor string            `json:"creditor"`
+	Account  string            `json:"account,omitempty"`
+	Orders   []OrderRemittance `json:"orders"`
+	Total    money.Amount      `json:"total"`
+}
+
+// OrderRemittance ยอดนำส่งของคำสั่งอายัดหนึ่งคำสั่ง
+type OrderRemittance struct {
+	GarnishmentID uint         `json:"garnishmentId"`
+	OrderNo       string       `json:"orderNo"`
+	EmployeeID    uint         `json:"employeeId"`
+	Amount        money.Amount `json:"amount"`
+	Outstanding   money.Amount `json:"outstanding"` // ยอดคงเหลือปัจจุบันของคำสั่ง
+}
+
+// RemittanceReport สรุปบรรทัด GARNISH ของ run แยกตามเจ้าหนี้ (เจ้าหนี้และบัญชีเดียวกันรวมกัน) เรียงตามชื่อเจ้าหนี้
+func (s *Service) RemittanceReport(runID uint) (RemittanceReport, error) {
+	rep := RemittanceReport{RunID: runID, Creditors: []CreditorRemittance{}}
+	if run, err := s.Store.GetPayrollRun(runID); err != nil || run == nil {
+		return rep, ErrRunNotFound
+	}
+	items, err := s.Store.ListPayrollItems(runID)
+	if err != nil {
+		return rep, err
+	}
+
+	type key struct{ creditor, account string }
+	by := make(map[key]*CreditorRemittance)
+	for _, id := range runRefIDs(items, models.CodeGarnish) {
+		g, err := s.Store.GetGarnishment(id)
+		if err != nil {
+			return rep, err
+		}
+		k := key{g.Creditor, g.CreditorAccount}
+		cr, ok := by[k]
+		if !ok {
+			cr = &CreditorRemittance{Creditor: g.Creditor, Account: g.CreditorAccount}
+			by[k] = cr
+		}
+		for _, it := range items {
+			amt := refAmount(it, models.CodeGarnish, id)
+			if amt == 0 {
+				continue
+			}
+			cr.Orders = append(cr.Orders, OrderRemittance{GarnishmentID: g.ID, OrderNo: g.OrderNo, EmployeeID: it.EmployeeID, Amount: amt, Outstanding: g.Outstanding})
+			cr.Total += amt
+			rep.Total += amt
+		}
+	}
+	for _, cr := range by {
+		rep.Creditors = append(rep.Creditors, *cr)
+	}
+	sort.Slice(rep.Creditors, func(i, j int) bool {
+		a, b := rep.Creditors[i], rep.Creditors[j]
+		if a.Creditor != b.Creditor {
+			return a.Creditor < b.Creditor
+		}
+		return a.Account < b.Account
+	})
+	return rep, nil
+}
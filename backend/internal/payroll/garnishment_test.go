@@ -0,0 +1,194 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+func addGarnishment(t *testing.T, st storage.Port, empID uint, g models.Garnishment) *models.Garnishment {
+	t.Helper()
+	g.EmployeeID = empID
+	g.Creditor, g.Status, g.StartDate = "กรมบังคับคดี", models.GarnishmentActive, date(2026, 1, 1)
+	if g.Outstanding == 0 {
+		g.Outstanding = g.Total
+	}
+	if err := st.CreateGarnishment(&g); err != nil {
+		t.Fatal(err)
+	}
+	return &g
+}
+
+// อายัดคิดจากเงินได้หลังหักภาษี/SSO/PVD ตามลำดับความสำคัญ และเงินสุทธิไม่ต่ำกว่ายอดคุ้มครอง
+func TestGarnishmentAfterStatutoryDeductions(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 50000, true)
+	e.PVDRate = 0.05
+	if err := st.UpdateEmployee(&e); err != nil {
+		t.Fatal(err)
+	}
+	// ลำดับรองแต่รับคำสั่งก่อน: ต้องหักหลังคำสั่ง Priority 1
+	second := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "B", Priority: 2, Amount: baht(10000), Protected: baht(20000), Total: baht(100000)})
+	first := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Priority: 1, Rate: 0.5, Protected: baht(20000), Total: baht(1000000)})
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	it := itemOf(t, st, jan.ID, e.ID)
+	if it.TaxWithheld <= 0 || it.SSO <= 0 || it.PVD <= 0 {
+		t.Fatalf("fixture must have tax, SSO and PVD: tax %s, sso %s, pvd %s", it.TaxWithheld, it.SSO, it.PVD)
+	}
+
+	base := it.GrossPay() - it.TaxWithheld - it.SSO - it.PVD
+	a := refAmount(it, models.CodeGarnish, first.ID)
+	if want := base.MulRate(0.5); a != want {
+		t.Errorf("order A = %s, want 50%% of %s after statutory deductions = %s", a, base, want)
+	}
+	b := refAmount(it, models.CodeGarnish, second.ID)
+	if want := money.Min(baht(10000), base-a-baht(20000)); b != want {
+		t.Errorf("order B = %s, want %s (limited by the protected minimum)", b, want)
+	}
+	if b >= baht(10000) {
+		t.Fatalf("fixture must hit the protected floor, order B = %s", b)
+	}
+	if it.NetPay != baht(20000) {
+		t.Errorf("net pay = %s, want the protected 20000.00", it.NetPay)
+	}
+}
+
+// คำสั่งอายัดมาก่อนเงินหักตามความยินยอม: รายการหักประจำ/ครั้งเดียวไม่ลดยอดอายัด ยอดคุ้มครองเทียบกับเงินได้หลังหักตามกฎหมาย
+func TestGarnishmentBeforeVoluntaryDeductions(t *testing.T) {
+	cases := []struct {
+		name              string
+		recurring, oneOff float64
+	}{
+		{name: "no voluntary deductions"},
+		{name: "recurring deduction", recurring: 3000},
+		{name: "one-off deduction", oneOff: 3000},
+		{name: "both", recurring: 1000, oneOff: 2000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, st := newTestService()
+			e := addEmployee(t, st, "E1", 50000, true)
+			g := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Amount: baht(40000), Protected: baht(20000), Total: baht(1000000)})
+			if tc.recurring > 0 {
+				if err := st.CreatePayComponent(&models.PayComponent{EmployeeID: e.ID, Code: "UNION", Name: "Union dues", Kind: models.ComponentDeduction,
+					Amount: baht(tc.recurring), StartDate: e.HiredAt}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			jan := addRun(t, st, 2026, 1, "")
+			if tc.oneOff > 0 {
+				if err := st.CreateRunInput(&models.RunInput{RunID: jan.ID, EmployeeID: e.ID, Code: "CANTEEN", Description: "Canteen",
+					Kind: models.ComponentDeduction, Amount: baht(tc.oneOff)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			mustCalculate(t, s, jan.ID)
+			it := itemOf(t, st, jan.ID, e.ID)
+
+			statutory := it.GrossPay() - it.TaxWithheld - it.SSO - it.PVD
+			if got, want := refAmount(it, models.CodeGarnish, g.ID), statutory-baht(20000); got != want {
+				t.Errorf("garnishment = %s, want %s (statutory net %s less the protected 20000.00)", got, want, statutory)
+			}
+			if want := baht(20000 - tc.recurring - tc.oneOff); it.NetPay != want {
+				t.Errorf("net pay = %s, want %s", it.NetPay, want)
+			}
+		})
+	}
+}
+
+// เงินสุทธิต่ำกว่ายอดคุ้มครองอยู่แล้ว: ไม่หักเลย
+func TestGarnishmentBelowProtectedMinimum(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 18000, true)
+	g := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Rate: 0.3, Protected: baht(20000), Total: baht(50000)})
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	it := itemOf(t, st, jan.ID, e.ID)
+	if got := refAmount(it, models.CodeGarnish, g.ID); got != 0 {
+		t.Fatalf("garnishment = %s, want 0 when net pay is below the protected amount", got)
+	}
+}
+
+// run งวดหลังที่ค้างไว้ไม่กันยอดของงวดก่อน และอนุมัติ run ค้างหลังหักครบยอดแล้วต้องถูกปฏิเสธโดยไม่บันทึกอะไรเลย
+func TestGarnishmentStaleApproval(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 50000, true)
+	g := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Amount: baht(5000), Protected: baht(20000), Total: baht(5000)})
+
+	feb := addRun(t, st, 2026, 2, "")
+	mustCalculate(t, s, feb.ID)
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	if got := refAmount(itemOf(t, st, jan.ID, e.ID), models.CodeGarnish, g.ID); got != baht(5000) {
+		t.Fatalf("January garnishment = %s, want 5000.00", got)
+	}
+	mustApprove(t, s, jan.ID)
+
+	if _, err := s.Transition(feb.ID, models.RunApproved, "test", ""); !errors.Is(err, ErrStaleDeductions) {
+		t.Fatalf("err = %v, want ErrStaleDeductions", err)
+	}
+	if rems, _ := st.ListGarnishmentRemittances(g.ID); len(rems) != 1 {
+		t.Fatalf("remittances = %d, want 1 (January only)", len(rems))
+	}
+
+	mustCalculate(t, s, feb.ID)
+	if got := refAmount(itemOf(t, st, feb.ID, e.ID), models.CodeGarnish, g.ID); got != 0 {
+		t.Fatalf("recalculated February garnishment = %s, want 0", got)
+	}
+	mustApprove(t, s, feb.ID)
+}
+
+// เงินกู้ที่บันทึกไม่ผ่านต้องยกเลิกยอดนำส่งอายัดที่บันทึกไปก่อนหน้าใน transaction เดียวกัน
+func TestGarnishmentPostingRollsBack(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 50000, true)
+	g := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Amount: baht(5000), Protected: baht(20000), Total: baht(50000)})
+	l := addLoan(t, st, e.ID, 3000, 1000)
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	l.Outstanding = baht(500)
+	if err := st.UpdateLoan(l); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transition(jan.ID, models.RunApproved, "test", ""); !errors.Is(err, ErrStaleDeductions) {
+		t.Fatalf("err = %v, want ErrStaleDeductions", err)
+	}
+	got, _ := st.GetGarnishment(g.ID)
+	if got.Outstanding != baht(50000) {
+		t.Fatalf("garnishment outstanding = %s, want 50000.00", got.Outstanding)
+	}
+	if rems, _ := st.ListGarnishmentRemittances(g.ID); len(rems) != 0 {
+		t.Fatalf("remittances = %d, want 0", len(rems))
+	}
+}
+
+// ยกเลิกอนุมัติคืนยอดคงเหลือและสถานะของคำสั่ง
+func TestGarnishmentReverse(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 50000, true)
+	g := addGarnishment(t, st, e.ID, models.Garnishment{OrderNo: "A", Amount: baht(5000), Protected: baht(20000), Total: baht(5000)})
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	mustApprove(t, s, jan.ID)
+	if got, _ := st.GetGarnishment(g.ID); got.Status != models.GarnishmentSatisfied || got.Outstanding != 0 {
+		t.Fatalf("after approval: %s outstanding %s, want satisfied 0", got.Status, got.Outstanding)
+	}
+	if _, err := s.Transition(jan.ID, models.RunCalculated, "test", "reopen"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := st.GetGarnishment(g.ID)
+	if got.Status != models.GarnishmentActive || got.Outstanding != baht(5000) {
+		t.Fatalf("after reversal: %s outstanding %s, want active 5000.00", got.Status, got.Outstanding)
+	}
+	if rems, _ := st.ListGarnishmentRemittances(g.ID); len(rems) != 0 {
+		t.Fatalf("remittances = %d, want 0", len(rems))
+	}
+}
@@ -0,0 +1,82 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// fixture service ที่ใช้ in-memory store และตารางอัตราที่ติดมากับระบบ
+
+func newTestService() (*Service, *storage.Storage) {
+	st := storage.New()
+	return NewService(st), st
+}
+
+func date(y, m, d int) time.Time { return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC) }
+
+// addEmployee พนักงานรายเดือนเข้างานตั้งแต่ปี 2024 ไม่มี PVD และประกันสังคมตาม sso
+func addEmployee(t *testing.T, st storage.Port, code string, salary float64, sso bool) models.Employee {
+	t.Helper()
+	e := &models.Employee{
+		EmpCode:    code,
+		FirstName:  code,
+		LastName:   "Test",
+		BaseSalary: baht(salary),
+		SSOEnabled: sso,
+		Status:     "active",
+		HiredAt:    date(2024, 1, 1),
+	}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateSalaryRecord(&models.SalaryRecord{EmployeeID: e.ID, EffectiveFrom: e.HiredAt, BaseSalary: e.BaseSalary, Reason: models.SalaryHire}); err != nil {
+		t.Fatal(err)
+	}
+	return *e
+}
+
+// addRun run ที่ยังเป็น draft (typ ว่าง = regular)
+func addRun(t *testing.T, st storage.Port, year, month int, typ string, empIDs ...uint) *models.PayrollRun {
+	t.Helper()
+	if typ == "" {
+		typ = models.RunRegular
+	}
+	r := &models.PayrollRun{PeriodYear: year, PeriodMonth: month, Type: typ, Status: models.RunDraft, EmployeeIDs: empIDs}
+	if err := st.CreatePayrollRun(r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func mustCalculate(t *testing.T, s *Service, runID uint) {
+	t.Helper()
+	if _, err := s.CalculateRun(runID, "test"); err != nil {
+		t.Fatalf("calculate run %d: %v", runID, err)
+	}
+}
+
+func mustApprove(t *testing.T, s *Service, runID uint) {
+	t.Helper()
+	if _, err := s.Transition(runID, models.RunApproved, "test", ""); err != nil {
+		t.Fatalf("approve run %d: %v", runID, err)
+	}
+}
+
+// itemOf item ของพนักงานใน run
+func itemOf(t *testing.T, st storage.Port, runID, empID uint) models.PayrollItem {
+	t.Helper()
+	items, err := st.ListPayrollItems(runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range items {
+		if it.EmployeeID == empID {
+			return it
+		}
+	}
+	t.Fatalf("run %d has no item for employee %d", runID, empID)
+	return models.PayrollItem{}
+}
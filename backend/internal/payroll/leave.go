@@ -0,0 +1,73 @@
+package payroll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+)
+
+// DailyRateBasis สูตรคิดค่าจ้างรายวันสำหรับหักลาไม่รับค่าจ้าง
+type DailyRateBasis string
+
+const (
+	// DailyRateCalendar30 เงินเดือน / 30 และนับวันลาตามวันปฏิทิน
+	DailyRateCalendar30 DailyRateBasis = "calendar30"
+	// DailyRateWorkingDays เงินเดือน / จำนวนวันทำงาน (จันทร์–ศุกร์) ในงวด และนับเฉพาะวันทำงานที่ลา
+	DailyRateWorkingDays DailyRateBasis = "working_days"
+)
+
+var dailyRateBasis = DailyRateCalendar30
+
+// SetDailyRateBasis ตั้งสูตรค่าจ้างรายวันที่ใช้ทั้งระบบ
+func SetDailyRateBasis(b DailyRateBasis) { dailyRateBasis = b }
+
+// ParseDailyRateBasis แปลงค่าจาก config ("" = calendar30)
+func ParseDailyRateBasis(s string) (DailyRateBasis, error) {
+	switch DailyRateBasis(strings.ToLower(strings.TrimSpace(s))) {
+	case "", DailyRateCalendar30:
+		return DailyRateCalendar30, nil
+	case DailyRateWorkingDays:
+		return DailyRateWorkingDays, nil
+	}
+	return DailyRateCalendar30, fmt.Errorf("unknown daily rate basis %q", s)
+}
+
+// unpaidLeave คำนวณจำนวนวันลาไม่รับค่าจ้าง (ที่อนุมัติแล้ว) ในช่วง from..to ของงวด p
+// (ช่วงจ้างงานที่ใช้เงินเดือนอัตราเดียวกัน) พร้อมตัวหารค่าจ้างรายวัน (ค่าจ้างรายวัน = เงินเดือน / divisor)
+func unpaidLeave(e models.Employee, leaves []models.Leave, p Period, from, to time.Time) (days, divisor int) {
+	for _, lv := range leaves {
+		if lv.EmployeeID != e.ID || !lv.Approved() || !lv.IsUnpaid() {
+			continue
+		}
+		s := maxTime(from, dateOnly(lv.StartDate))
+		t := minTime(to, dateOnly(lv.EndDate))
+		for d := s; !d.After(t); d = d.AddDate(0, 0, 1) {
+			if dailyRateBasis == DailyRateWorkingDays && !isWorkingDay(d) {
+				continue
+			}
+			days++
+		}
+	}
+
+	if dailyRateBasis == DailyRateWorkingDays {
+		return days, workingDays(p.Start, p.End)
+	}
+	return days, 30
+}
+
+func isWorkingDay(d time.Time) bool {
+	wd := d.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+func workingDays(from, to time.Time) int {
+	n := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if isWorkingDay(d) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,128 @@
+package payroll
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+)
+
+var (
+	// ErrRunLocked run ถูกอนุมัติแล้ว ห้ามคำนวณใหม่ แก้ไข item หรือลบ
+	ErrRunLocked = errors.New("payroll run is locked")
+	// ErrInvalidTransition เปลี่ยนสถานะที่ไม่อนุญาต
+	ErrInvalidTransition = errors.New("invalid run status transition")
+)
+
+// runTransitions สถานะปลายทางที่อนุญาตจากแต่ละสถานะ
+// draft → calculated เกิดจากการคำนวณเท่านั้น; approved → calculated คือยกเลิกการอนุมัติก่อนจ่าย
+var runTransitions = map[string][]string{
+	models.RunDraft:      {models.RunCalculated},
+	models.RunCalculated: {models.RunCalculated, models.RunApproved, models.RunDraft},
+	models.RunApproved:   {models.RunPaid, models.RunCalculated},
+	models.RunPaid:       {models.RunClosed},
+	models.RunClosed:     nil,
+}
+
+// CanTransition ตรวจว่าเปลี่ยนสถานะ from → to ได้หรือไม่
+func CanTransition(from, to string) bool {
+	if from == "" {
+		from = models.RunDraft
+	}
+	for _, st := range runTransitions[from] {
+		if st == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRunStatus ตรวจว่าเป็นสถานะที่ระบบรองรับ
+func ValidRunStatus(s string) bool {
+	_, ok := runTransitions[s]
+	return ok
+}
+
+// Transition เปลี่ยนสถานะ run พร้อมบันทึกผู้ทำรายการ
+// การถอยกลับเป็น draft จะล้าง items ที่คำนวณไว้ การอนุมัติบันทึกการชำระเงินกู้/ยอดนำส่งคำสั่งอายัด
+// ตามบรรทัด LOAN/GARNISH และการยกเลิกอนุมัติคืนยอดคงเหลือ
+func (s *Service) Transition(runID uint, to, by, note string) (*models.PayrollRun, error) {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return nil, ErrRunNotFound
+	}
+	if to == models.RunCalculated && run.Status != models.RunApproved {
+		// เข้าสถานะ calculated ได้ด้วยการคำนวณ (POST /calculate) เท่านั้น
+		return nil, ErrInvalidTransition
+	}
+	if !CanTransition(run.Status, to) {
+		return nil, ErrInvalidTransition
+	}
+	// ผลข้างเคียงของการเปลี่ยนสถานะกับตัวสถานะเองบันทึกใน transaction เดียว
+	// (อนุมัติไม่สำเร็จ = ไม่มียอดชำระเงินกู้/ยอดนำส่งค้างอยู่ขณะที่ run ยังเป็น calculated)
+	err = s.inTx(func(tx *Service) error {
+		switch {
+		case to == models.RunDraft:
+			if err := tx.Store.ClearPayrollItems(run.ID); err != nil {
+				return err
+			}
+		case to == models.RunApproved:
+			if err := tx.postGarnishments(run, by); err != nil {
+				return err
+			}
+			if err := tx.postLoanRepayments(run, by); err != nil {
+				return err
+			}
+		case run.Status == models.RunApproved && to == models.RunCalculated:
+			if err := tx.reverseGarnishments(run); err != nil {
+				return err
+			}
+			if err := tx.reverseLoanRepayments(run); err != nil {
+				return err
+			}
+		}
+		return tx.setStatus(run, to, by, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// setStatus บันทึกสถานะใหม่ของ run และประวัติการเปลี่ยน
+func (s *Service) setStatus(run *models.PayrollRun, to, by, note string) error {
+	from := run.Status
+	if from == "" {
+		from = models.RunDraft
+	}
+	run.Status = to
+	run.Locked = !run.Editable()
+	if err := s.Store.UpdatePayrollRun(run); err != nil {
+		return err
+	}
+	return s.Store.CreateRunTransition(&models.RunTransition{
+		RunID:      run.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		By:         by,
+		Note:       note,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// DeleteRun ลบ run ที่ยังไม่ได้อนุมัติ พร้อม items ทั้งหมด
+func (s *Service) DeleteRun(runID uint) error {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return ErrRunNotFound
+	}
+	if !run.Editable() {
+		return ErrRunLocked
+	}
+	return s.inTx(func(tx *Service) error {
+		if err := tx.Store.ClearPayrollItems(run.ID); err != nil {
+			return err
+		}
+		return tx.Store.DeletePayrollRun(run.ID)
+	})
+}
@@ -0,0 +1,288 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// ErrStaleDeductions บรรทัด LOAN/GARNISH ของ run เกินยอดคงเหลือ ณ ตอนอนุมัติ (run อื่นอนุมัติไปก่อน) ต้องคำนวณ run ใหม่
+var ErrStaleDeductions = errors.New("deductions exceed the outstanding balance; recalculate the run")
+
+// LoanDue ยอดผ่อนชำระเงินกู้ที่ถึงกำหนดใน run (Amount = ยอดที่หักได้จริงหลังคุมไม่ให้เงินสุทธิติดลบ)
+type LoanDue struct {
+	LoanID      uint         `json:"loanId"`
+	Description string       `json:"description"`
+	Due         money.Amount `json:"due"`
+	Amount      money.Amount `json:"amount"`
+}
+
+// PlanLoan คิดดอกเบี้ยแบบคงที่ (เงินต้น x อัตราต่อปี x ระยะเวลาผ่อนเป็นปี) และยอดหักต่องวด (ปัดขึ้นเป็นสตางค์)
+// ตั้งยอดคงเหลือเท่ากับยอดที่ต้องชำระทั้งหมด ใช้ตอนสร้างเงินกู้
+func PlanLoan(l *models.Loan, frequency string) {
+	l.Interest = l.Principal.MulRate(l.InterestRate).MulDiv(int64(l.Installments), int64(PeriodsPerYear(frequency)))
+	n := int64(l.Installments)
+	l.Installment = money.Satang((l.Total().Satang() + n - 1) / n)
+	l.Outstanding = l.Total()
+}
+
+// loanDues ยอดผ่อนที่ถึงกำหนดของพนักงานใน run: run ปกติหักตามงวด (หรือยอดคงเหลือทั้งหมดเมื่อขอปิดบัญชีผ่าน payroll)
+// run termination หักยอดคงเหลือทั้งหมด และ run นอกรอบอื่นไม่หัก
+// ยอดคงเหลือหักส่วนที่อยู่ใน run อื่นซึ่งคำนวณแล้วแต่ยังไม่อนุมัติ (ดู pending) เพื่อไม่ให้หักเกินยอดหนี้
+func (s *Service) loanDues(e models.Employee, run *models.PayrollRun, period Period, rc *runCache) ([]LoanDue, error) {
+	if run.OffCycle() && run.Type != models.RunTermination {
+		return nil, nil
+	}
+	loans, err := s.Store.ListLoans(e.ID)
+	if err != nil {
+		return nil, err
+	}
+	var out []LoanDue
+	for _, l := range loans {
+		if l.Status != models.LoanActive || l.Outstanding <= 0 || dateOnly(l.StartDate).After(period.End) {
+			continue
+		}
+		pending, err := rc.pending(models.CodeLoan, l.ID, run)
+		if err != nil {
+			return nil, err
+		}
+		left := l.Outstanding - pending
+		if left <= 0 {
+			continue
+		}
+		due := l.Installment
+		if l.PayoffInPayroll || run.Type == models.RunTermination {
+			due = left
+		}
+		due = money.Min(due, left)
+		desc := l.Description
+		if desc == "" {
+			desc = "Loan repayment"
+		}
+		out = append(out, LoanDue{LoanID: l.ID, Description: desc, Due: due, Amount: due})
+	}
+	return out, nil
+}
+
+// pending ยอดของบรรทัดรหัส code ที่อ้างอิง id ใน run อื่นที่คำนวณแล้วแต่ยังไม่อนุมัติ (ยังไม่ลดยอดคงเหลือของเงินกู้/คำสั่งอายัด)
+// นับเฉพาะ run ของงวดเดียวกันหรือก่อนหน้า current ที่ยังไม่ถูกแทนที่ run ค้างที่ไม่มีใครอนุมัติจึงไม่กันยอดของ run จริง
+// ถ้าภายหลังมี run อื่นอนุมัติไปก่อนจนยอดเกิน การอนุมัติจะถูกปฏิเสธด้วย ErrStaleDeductions
+func (rc *runCache) pending(code string, id uint, current *models.PayrollRun) (money.Amount, error) {
+	var t money.Amount
+	end := runEnd(*current)
+	for _, r := range rc.runs {
+		if r.ID == current.ID || !calculated(r) || !r.Editable() || runEnd(r).After(end) || rc.superseded(r) {
+			continue
+		}
+		items, err := rc.items(r.ID)
+		if err != nil {
+			return 0, err
+		}
+		for _, it := range items {
+			t += refAmount(it, code, id)
+		}
+	}
+	return t, nil
+}
+
+// superseded run ที่ยังไม่อนุมัติแต่กลุ่มจ่ายเงินเดียวกันอนุมัติ run ของงวดหลังจากนั้นไปแล้ว (ถือว่าค้าง ไม่นับเป็นยอดรอหัก)
+func (rc *runCache) superseded(r models.PayrollRun) bool {
+	end := runEnd(r)
+	for _, o := range rc.runs {
+		if o.ID != r.ID && !o.Editable() && o.SameGroup(r.PayGroupID) && runEnd(o).After(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineRef เงินกู้/คำสั่งอายัดที่บรรทัด LOAN/GARNISH อ้างอิง (nil = บรรทัดรหัสอื่น)
+func lineRef(l models.PayrollLine, code string) *uint {
+	if l.Code != code {
+		return nil
+	}
+	switch code {
+	case models.CodeLoan:
+		return l.RefLoanID
+	case models.CodeGarnish:
+		return l.RefGarnishmentID
+	}
+	return nil
+}
+
+// refAmount ยอดของบรรทัดรหัส code ที่อ้างอิง id ใน item
+func refAmount(it models.PayrollItem, code string, id uint) money.Amount {
+	var t money.Amount
+	for _, l := range it.Lines {
+		if ref := lineRef(l, code); ref != nil && *ref == id {
+			t += l.Amount
+		}
+	}
+	return t
+}
+
+// runRefIDs id ที่บรรทัดรหัส code ใน items ของ run อ้างอิง (ตามลำดับที่พบ)
+func runRefIDs(items []models.PayrollItem, code string) []uint {
+	seen := make(map[uint]bool)
+	var out []uint
+	for _, it := range items {
+		for _, l := range it.Lines {
+			if ref := lineRef(l, code); ref != nil && !seen[*ref] {
+				seen[*ref] = true
+				out = append(out, *ref)
+			}
+		}
+	}
+	return out
+}
+
+// postLoanRepayments บันทึกการชำระเงินกู้ตามบรรทัด LOAN ของ run ที่อนุมัติ และลดยอดคงเหลือ
+// เงินกู้ที่ถูกพักชำระหรือปิดไปแล้วหลังคำนวณ run ทำให้บรรทัด LOAN ไม่เป็นปัจจุบัน (ต้องคำนวณใหม่)
+func (s *Service) postLoanRepayments(run *models.PayrollRun, by string) error {
+	items, err := s.Store.ListPayrollItems(run.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range runRefIDs(items, models.CodeLoan) {
+		var amt money.Amount
+		for _, it := range items {
+			amt += refAmount(it, models.CodeLoan, id)
+		}
+		l, err := s.Store.GetLoan(id)
+		if err != nil {
+			return err
+		}
+		if l.Status != models.LoanActive {
+			return fmt.Errorf("%w: loan %d is %s", ErrStaleDeductions, id, l.Status)
+		}
+		if amt > l.Outstanding {
+			return fmt.Errorf("%w: loan %d deducts %s, outstanding %s", ErrStaleDeductions, id, amt, l.Outstanding)
+		}
+		runID := run.ID
+		if err := s.Store.CreateLoanRepayment(&models.LoanRepayment{LoanID: id, RunID: &runID, Amount: amt, CreatedBy: by}); err != nil {
+			return err
+		}
+		l.Outstanding -= amt
+		if l.Outstanding <= 0 {
+			l.Outstanding, l.Status, l.PayoffInPayroll = 0, models.LoanPaidOff, false
+		}
+		if err := s.Store.UpdateLoan(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseLoanRepayments ยกเลิกการชำระเงินกู้ของ run ที่ถูกยกเลิกอนุมัติ (คืนยอดคงเหลือ)
+func (s *Service) reverseLoanRepayments(run *models.PayrollRun) error {
+	items, err := s.Store.ListPayrollItems(run.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range runRefIDs(items, models.CodeLoan) {
+		l, err := s.Store.GetLoan(id)
+		if err != nil {
+			return err
+		}
+		reps, err := s.Store.ListLoanRepayments(id)
+		if err != nil {
+			return err
+		}
+		for _, r := range reps {
+			if r.RunID == nil || *r.RunID != run.ID {
+				continue
+			}
+			if err := s.Store.DeleteLoanRepayment(r.ID); err != nil {
+				return err
+			}
+			l.Outstanding += r.Amount
+		}
+		if l.Status == models.LoanPaidOff && l.Outstanding > 0 {
+			l.Status = models.LoanActive
+		}
+		if err := s.Store.UpdateLoan(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoanSchedule ประวัติการชำระและตารางผ่อนที่เหลือของเงินกู้
+type LoanSchedule struct {
+	Loan       models.Loan            `json:"loan"`
+	Repayments []models.LoanRepayment `json:"repayments"`
+	Paid       money.Amount           `json:"paid"`
+	Schedule   []LoanInstallment      `json:"schedule"` // ว่างเมื่อพักชำระหรือชำระครบแล้ว
+}
+
+// LoanInstallment งวดผ่อนที่คาดว่าจะหักในอนาคต
+type LoanInstallment struct {
+	No          int          `json:"no"`
+	PeriodStart time.Time    `json:"periodStart"`
+	PeriodEnd   time.Time    `json:"periodEnd"`
+	Amount      money.Amount `json:"amount"`
+	Balance     money.Amount `json:"balance"` // ยอดคงเหลือหลังหักงวดนี้
+}
+
+// maxLoanInstallments กันลูปไม่รู้จบเมื่อยอดหักต่องวดผิดปกติ
+const maxLoanInstallments = 1000
+
+// LoanSchedule ตารางผ่อนของเงินกู้: เริ่มจากงวดที่ครอบคลุมวันเริ่มหัก หรืองวดถัดจาก run ล่าสุดที่หักไปแล้ว
+func (s *Service) LoanSchedule(loanID uint) (LoanSchedule, error) {
+	l, err := s.Store.GetLoan(loanID)
+	if err != nil {
+		return LoanSchedule{}, err
+	}
+	reps, err := s.Store.ListLoanRepayments(l.ID)
+	if err != nil {
+		return LoanSchedule{}, err
+	}
+	out := LoanSchedule{Loan: *l, Repayments: reps, Schedule: []LoanInstallment{}}
+	for _, r := range reps {
+		out.Paid += r.Amount
+	}
+	if l.Status != models.LoanActive || l.Outstanding <= 0 {
+		return out, nil
+	}
+
+	e, err := s.Store.GetEmployee(l.EmployeeID)
+	if err != nil {
+		return out, err
+	}
+	group, err := s.payGroup(e.PayGroupID)
+	if err != nil {
+		return out, err
+	}
+	from := dateOnly(l.StartDate)
+	for _, r := range reps {
+		if r.RunID == nil {
+			continue
+		}
+		run, err := s.Store.GetPayrollRun(*r.RunID)
+		if err != nil || run == nil {
+			continue
+		}
+		if next := runEnd(*run).AddDate(0, 0, 1); next.After(from) {
+			from = next
+		}
+	}
+
+	balance := l.Outstanding
+	for no := 1; balance > 0 && no <= maxLoanInstallments; no++ {
+		p, err := PeriodOn(group, from)
+		if err != nil {
+			return out, err
+		}
+		amt := money.Min(l.Installment, balance)
+		if l.PayoffInPayroll {
+			amt = balance
+		}
+		balance -= amt
+		out.Schedule = append(out.Schedule, LoanInstallment{No: no, PeriodStart: p.Start, PeriodEnd: p.End, Amount: amt, Balance: balance})
+		from = p.End.AddDate(0, 0, 1)
+	}
+	return out, nil
+}
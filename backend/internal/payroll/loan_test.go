@@ -0,0 +1,146 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+func addLoan(t *testing.T, st storage.Port, empID uint, total, installment float64) *models.Loan {
+	t.Helper()
+	l := &models.Loan{
+		EmployeeID:   empID,
+		Type:         models.LoanAdvance,
+		Principal:    baht(total),
+		Installments: int(total / installment),
+		Installment:  baht(installment),
+		StartDate:    date(2026, 1, 1),
+		Outstanding:  baht(total),
+		Status:       models.LoanActive,
+	}
+	if err := st.CreateLoan(l); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// run ของงวดหลังที่คำนวณค้างไว้ต้องไม่กันยอดหักของงวดก่อน และการอนุมัติ run ค้างหลังยอดหมดต้องถูกปฏิเสธทั้ง run
+func TestLoanPendingIgnoresLaterRuns(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 30000, true)
+	l := addLoan(t, st, e.ID, 1000, 1000)
+
+	feb := addRun(t, st, 2026, 2, "")
+	mustCalculate(t, s, feb.ID)
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+
+	if got := itemOf(t, st, jan.ID, e.ID).SumCodes(models.CodeLoan); got != baht(1000) {
+		t.Fatalf("January loan deduction = %s, want 1000.00 (stale February run must not block it)", got)
+	}
+	mustApprove(t, s, jan.ID)
+
+	_, err := s.Transition(feb.ID, models.RunApproved, "test", "")
+	if !errors.Is(err, ErrStaleDeductions) {
+		t.Fatalf("approving February after the loan is repaid: err = %v, want ErrStaleDeductions", err)
+	}
+	if r, _ := st.GetPayrollRun(feb.ID); r.Status != models.RunCalculated {
+		t.Fatalf("February status = %s, want calculated", r.Status)
+	}
+	if reps, _ := st.ListLoanRepayments(l.ID); len(reps) != 1 {
+		t.Fatalf("repayments = %d, want 1 (January only)", len(reps))
+	}
+
+	mustCalculate(t, s, feb.ID)
+	if got := itemOf(t, st, feb.ID, e.ID).SumCodes(models.CodeLoan); got != 0 {
+		t.Fatalf("recalculated February loan deduction = %s, want 0", got)
+	}
+	mustApprove(t, s, feb.ID)
+}
+
+// run งวดก่อนที่ค้างอยู่หลังอนุมัติงวดถัดไปแล้ว (superseded) ไม่นับเป็นยอดรอหัก
+func TestLoanPendingIgnoresSupersededRuns(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 30000, true)
+	addLoan(t, st, e.ID, 2000, 1000)
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	feb := addRun(t, st, 2026, 2, "")
+	mustCalculate(t, s, feb.ID)
+	if got := itemOf(t, st, feb.ID, e.ID).SumCodes(models.CodeLoan); got != baht(1000) {
+		t.Fatalf("February loan deduction = %s, want 1000.00", got)
+	}
+	mustApprove(t, s, feb.ID)
+
+	mar := addRun(t, st, 2026, 3, "")
+	mustCalculate(t, s, mar.ID)
+	if got := itemOf(t, st, mar.ID, e.ID).SumCodes(models.CodeLoan); got != baht(1000) {
+		t.Fatalf("March loan deduction = %s, want 1000.00 (superseded January run must not count)", got)
+	}
+}
+
+// การอนุมัติที่ล้มกลางทางต้องไม่ทิ้งยอดชำระของเงินกู้ก้อนก่อนหน้าไว้
+func TestLoanPostingIsAtomic(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 30000, true)
+	first := addLoan(t, st, e.ID, 5000, 1000)
+	second := addLoan(t, st, e.ID, 1000, 1000)
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+
+	// ยอดของเงินกู้ก้อนที่สองลดลงหลังคำนวณ (เช่นชำระเงินสด) ทำให้บรรทัด LOAN ของ run เกินยอดคงเหลือ
+	second.Outstanding = baht(500)
+	if err := st.UpdateLoan(second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transition(jan.ID, models.RunApproved, "test", ""); !errors.Is(err, ErrStaleDeductions) {
+		t.Fatalf("err = %v, want ErrStaleDeductions", err)
+	}
+	l, _ := st.GetLoan(first.ID)
+	if l.Outstanding != baht(5000) {
+		t.Fatalf("first loan outstanding = %s, want 5000.00 (posting must roll back)", l.Outstanding)
+	}
+	if reps, _ := st.ListLoanRepayments(first.ID); len(reps) != 0 {
+		t.Fatalf("first loan repayments = %d, want 0", len(reps))
+	}
+}
+
+// เงินกู้ที่ถูกพักชำระหรือปิดหลังคำนวณ run: อนุมัติไม่ได้จนกว่าจะคำนวณใหม่ และไม่บันทึกการชำระ
+func TestLoanPostingRejectsInactiveLoan(t *testing.T) {
+	cases := []struct {
+		status      string
+		outstanding float64
+	}{
+		{models.LoanPaused, 5000},
+		{models.LoanPaidOff, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.status, func(t *testing.T) {
+			s, st := newTestService()
+			e := addEmployee(t, st, "E1", 30000, true)
+			loan := addLoan(t, st, e.ID, 5000, 1000)
+
+			jan := addRun(t, st, 2026, 1, "")
+			mustCalculate(t, s, jan.ID)
+
+			loan.Status, loan.Outstanding = tc.status, baht(tc.outstanding)
+			if err := st.UpdateLoan(loan); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Transition(jan.ID, models.RunApproved, "test", ""); !errors.Is(err, ErrStaleDeductions) {
+				t.Fatalf("err = %v, want ErrStaleDeductions", err)
+			}
+			l, _ := st.GetLoan(loan.ID)
+			if l.Status != tc.status || l.Outstanding != baht(tc.outstanding) {
+				t.Fatalf("loan = %s / %s, want %s / %.2f unchanged", l.Status, l.Outstanding, tc.status, tc.outstanding)
+			}
+			if reps, _ := st.ListLoanRepayments(loan.ID); len(reps) != 0 {
+				t.Fatalf("repayments = %d, want 0", len(reps))
+			}
+		})
+	}
+}
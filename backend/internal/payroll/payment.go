@@ -0,0 +1,144 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/bankfile"
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// ErrRunNotApproved ไฟล์โอนเงินสร้างได้เฉพาะ run ที่อนุมัติแล้ว (ยอดไม่เปลี่ยนอีก)
+var ErrRunNotApproved = errors.New("payroll run is not approved")
+
+// payDateOffset จ่ายเงินเดือนหลังวันสิ้นงวด 5 วัน
+const payDateOffset = 5
+
+// PayDate วันจ่ายเงินของ run (วันสิ้นงวด + 5 วัน) ใช้ใน payslip และเป็นวันโอนตั้งต้นของไฟล์โอนเงิน
+func PayDate(r models.PayrollRun) time.Time {
+	return runEnd(r).AddDate(0, 0, payDateOffset)
+}
+
+// Payments รายการโอนเงินสุทธิของ run แยกตามวิธีรับเงิน: Bank เข้าไฟล์ของธนาคารบริษัท, PromptPay เข้าไฟล์ pain.001 แบบ proxy
+// เงินสุทธิรวมของ run (RunTotal) = ยอดของทั้งสองไฟล์ + เงินสุทธิของ item ที่ไม่โอน (SkippedTotal) ทุกสตางค์
+type Payments struct {
+	RunID        uint
+	RunTotal     money.Amount
+	Bank         bankfile.Batch
+	PromptPay    bankfile.Batch
+	Skipped      []uint       // item ที่เงินสุทธิไม่เป็นบวก (ไม่มีรายการโอน)
+	SkippedTotal money.Amount // เงินสุทธิรวมของ Skipped (ศูนย์หรือติดลบ)
+}
+
+// Difference ส่วนต่างระหว่างเงินสุทธิรวมของ run กับยอดรวมของไฟล์โอนเงินบวกยอดที่ไม่โอน (0 = ตรงกัน)
+func (p Payments) Difference() money.Amount {
+	return p.RunTotal - p.SkippedTotal - p.Bank.Total() - p.PromptPay.Total()
+}
+
+// Reconciled ยอดรวมของไฟล์โอนเงินตรงกับเงินสุทธิรวมของ run
+func (p Payments) Reconciled() bool { return p.Difference() == 0 }
+
+// PaymentSummary สรุปไฟล์โอนเงินของ run สำหรับตรวจยอดก่อนส่งธนาคาร
+type PaymentSummary struct {
+	RunID        uint         `json:"runId"`
+	RunTotal     money.Amount `json:"runTotal"`
+	Bank         FileSummary  `json:"bank"`
+	PromptPay    FileSummary  `json:"promptPay"`
+	Skipped      []uint       `json:"skippedItems,omitempty"`
+	SkippedTotal money.Amount `json:"skippedTotal"`
+	Difference   money.Amount `json:"difference"`
+	Reconciled   bool         `json:"reconciled"`
+}
+
+// FileSummary จำนวนรายการและยอดรวมควบคุมของไฟล์โอนเงินหนึ่งไฟล์
+type FileSummary struct {
+	Format string       `json:"format"`
+	Count  int          `json:"count"`
+	Total  money.Amount `json:"total"`
+}
+
+// Summary สรุปยอดของไฟล์โอนเงินทั้งสองไฟล์
+func (p Payments) Summary(bankFormat string) PaymentSummary {
+	return PaymentSummary{
+		RunID:        p.RunID,
+		RunTotal:     p.RunTotal,
+		Bank:         FileSummary{Format: bankFormat, Count: len(p.Bank.Credits), Total: p.Bank.Total()},
+		PromptPay:    FileSummary{Format: bankfile.PromptPay{}.Format(), Count: len(p.PromptPay.Credits), Total: p.PromptPay.Total()},
+		Skipped:      p.Skipped,
+		SkippedTotal: p.SkippedTotal,
+		Difference:   p.Difference(),
+		Reconciled:   p.Reconciled(),
+	}
+}
+
+// Payments รายการโอนเงินสุทธิของ run ที่อนุมัติแล้ว (item ที่เงินสุทธิไม่เป็นบวกไม่มีรายการโอน)
+// effective = วันที่โอน (nil = วันจ่ายเงินของ run); พนักงานที่ไม่ได้ระบุธนาคารถือว่าใช้ธนาคารเดียวกับบริษัท
+func (s *Service) Payments(runID uint, effective *time.Time) (Payments, error) {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return Payments{}, ErrRunNotFound
+	}
+	if run.Editable() {
+		return Payments{}, ErrRunNotApproved
+	}
+	items, err := s.Store.ListPayrollItems(runID)
+	if err != nil {
+		return Payments{}, err
+	}
+
+	co := bankfile.CurrentCompany()
+	batch := func(ref string) bankfile.Batch {
+		b := bankfile.Batch{Company: co, Reference: ref, Effective: PayDate(*run), Created: time.Now()}
+		if effective != nil {
+			b.Effective = dateOnly(*effective)
+		}
+		return b
+	}
+	p := Payments{
+		RunID:     run.ID,
+		RunTotal:  Totals(items).NetPay,
+		Bank:      batch(fmt.Sprintf("RUN-%d", run.ID)),
+		PromptPay: batch(fmt.Sprintf("RUN-%d-PP", run.ID)),
+	}
+	for _, it := range items {
+		if it.NetPay <= 0 {
+			p.Skipped = append(p.Skipped, it.ID)
+			p.SkippedTotal += it.NetPay
+			continue
+		}
+		e, err := s.Store.GetEmployee(it.EmployeeID)
+		if err != nil {
+			return p, err
+		}
+		fullName := strings.TrimSpace(e.FirstName + " " + e.LastName)
+		if e.PaidByPromptPay() {
+			p.PromptPay.Credits = append(p.PromptPay.Credits, bankfile.Credit{
+				Ref:       e.EmpCode,
+				Name:      fullName,
+				ProxyType: e.PromptPayType,
+				ProxyID:   e.PromptPayID,
+				Amount:    it.NetPay,
+			})
+			continue
+		}
+		bank := e.BankCode
+		if bank == "" {
+			bank = co.BankCode
+		}
+		name := e.BankAccountName
+		if name == "" {
+			name = fullName
+		}
+		p.Bank.Credits = append(p.Bank.Credits, bankfile.Credit{
+			Ref:      e.EmpCode,
+			Name:     name,
+			BankCode: bank,
+			Account:  e.BankAccount,
+			Amount:   it.NetPay,
+		})
+	}
+	return p, nil
+}
@@ -0,0 +1,54 @@
+package payroll
+
+import (
+	"testing"
+
+	"backend/internal/models"
+)
+
+// item ที่เงินสุทธิไม่เป็นบวกไม่มีรายการโอน แต่ยังอยู่ในเงินสุทธิรวมของ run: ต้องกระทบยอดผ่าน SkippedTotal
+func TestPaymentsReconcileSkippedItems(t *testing.T) {
+	s, st := newTestService()
+	bank := addEmployee(t, st, "E1", 30000, true)
+	pp := addEmployee(t, st, "E2", 25000, true)
+	pp.PaymentMethod, pp.PromptPayType, pp.PromptPayID = models.PaymentPromptPay, models.PromptPayMobile, "0812345678"
+	if err := st.UpdateEmployee(&pp); err != nil {
+		t.Fatal(err)
+	}
+	// ไม่มีเงินเดือนแต่ยังมีเงินหักประจำ (หักเต็มจำนวน): เงินสุทธิติดลบ
+	zero := addEmployee(t, st, "E3", 0, true)
+	if err := st.CreatePayComponent(&models.PayComponent{EmployeeID: zero.ID, Code: "UNIFORM", Name: "Uniform", Kind: models.ComponentDeduction,
+		Amount: baht(500), StartDate: zero.HiredAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	jan := addRun(t, st, 2026, 1, "")
+	mustCalculate(t, s, jan.ID)
+	mustApprove(t, s, jan.ID)
+
+	neg := itemOf(t, st, jan.ID, zero.ID)
+	if neg.NetPay >= 0 {
+		t.Fatalf("fixture must have a negative net pay, got %s", neg.NetPay)
+	}
+	p, err := s.Payments(jan.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Bank.Credits) != 1 || len(p.PromptPay.Credits) != 1 {
+		t.Fatalf("credits: bank %d, promptpay %d, want 1 and 1", len(p.Bank.Credits), len(p.PromptPay.Credits))
+	}
+	if len(p.Skipped) != 1 || p.Skipped[0] != neg.ID || p.SkippedTotal != neg.NetPay {
+		t.Fatalf("skipped = %v total %s, want [%d] total %s", p.Skipped, p.SkippedTotal, neg.ID, neg.NetPay)
+	}
+	want := itemOf(t, st, jan.ID, bank.ID).NetPay + itemOf(t, st, jan.ID, pp.ID).NetPay + neg.NetPay
+	if p.RunTotal != want {
+		t.Fatalf("run total = %s, want %s", p.RunTotal, want)
+	}
+	sum := p.Summary("CSV")
+	if sum.Difference != 0 || !sum.Reconciled {
+		t.Fatalf("difference = %s, reconciled %v; want a reconciled run", sum.Difference, sum.Reconciled)
+	}
+	if sum.Bank.Total+sum.PromptPay.Total+sum.SkippedTotal != sum.RunTotal {
+		t.Fatalf("bank %s + promptpay %s + skipped %s != run total %s", sum.Bank.Total, sum.PromptPay.Total, sum.SkippedTotal, sum.RunTotal)
+	}
+}
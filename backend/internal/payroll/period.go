@@ -0,0 +1,110 @@
+package payroll
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+)
+
+// ErrInvalidPeriod ปี/เดือนของงวดไม่ถูกต้อง
+var ErrInvalidPeriod = errors.New("invalid payroll period")
+
+// Period ช่วงวันของงวดเงินเดือน (รวมวันแรกและวันสุดท้าย) ใช้เวลา UTC เสมอ
+// Index/Count คือลำดับงวดและจำนวนงวดทั้งปีภาษีตามปฏิทินของกลุ่มจ่ายเงิน
+type Period struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Index     int       `json:"index"`
+	Count     int       `json:"count"`
+	Frequency string    `json:"frequency"`
+}
+
+// MonthPeriod คืนงวดเต็มเดือนของปี/เดือนที่กำหนด
+func MonthPeriod(year, month int) (Period, error) {
+	if year < 1 || month < 1 || month > 12 {
+		return Period{}, ErrInvalidPeriod
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	return Period{Start: start, End: end, Index: month, Count: 12, Frequency: models.FrequencyMonthly}, nil
+}
+
+// Remaining จำนวนงวดที่เหลือในปีภาษี (รวมงวดนี้)
+func (p Period) Remaining() int {
+	if p.Count <= 0 || p.Index <= 0 {
+		return 12 - int(p.End.Month()) + 1
+	}
+	return p.Count - p.Index + 1
+}
+
+// PerYear จำนวนงวดจริงในปีภาษีของงวดนี้ (biweekly 26/27, weekly 52/53) ไม่ทราบ = จำนวนงวดมาตรฐานของความถี่
+func (p Period) PerYear() int {
+	if p.Count > 0 {
+		return p.Count
+	}
+	return PeriodsPerYear(p.Frequency)
+}
+
+// Days จำนวนวันในงวด (inclusive)
+func (p Period) Days() int {
+	return daysBetween(p.Start, p.End) + 1
+}
+
+// overlapDays คำนวณจำนวนวันทำงานที่ซ้อนกับงวด (นับแบบรวมปลายทั้งสองด้าน)
+func overlapDays(hire time.Time, end *time.Time, p Period) (worked, total int) {
+	total = p.Days()
+	if total < 0 {
+		total = 0
+	}
+
+	start := maxTime(p.Start, dateOnly(hire))
+	last := p.End
+	if end != nil {
+		if dateOnly(*end).Before(p.Start) {
+			return 0, total
+		}
+		last = minTime(p.End, dateOnly(*end))
+	}
+
+	w := daysBetween(start, last) + 1
+	if w < 0 {
+		w = 0
+	}
+	return w, total
+}
+
+// dateOnly ตัดเวลาออกและแปลงเป็น UTC เพื่อให้ทุก entry point นับวันเหมือนกัน
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// minEnd คืนวันสิ้นสุดที่มาก่อน (nil = ไม่มีวันสิ้นสุด)
+func minEnd(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.Before(*b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,202 @@
+package payroll
+
+import (
+	"sort"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// สถานะของพนักงานใน preview เทียบกับ run ก่อนหน้า
+const (
+	PreviewNew       = "new"       // ไม่มีใน run ก่อนหน้า
+	PreviewRemoved   = "removed"   // มีใน run ก่อนหน้าแต่ไม่มีใน run นี้
+	PreviewChanged   = "changed"   // ยอดบรรทัดใดบรรทัดหนึ่งต่างจากเดิม
+	PreviewUnchanged = "unchanged" // ทุกบรรทัดเท่าเดิม
+)
+
+// Preview ผลคำนวณ run แบบไม่บันทึก (dry run) พร้อมความต่างจาก run ก่อนหน้าของกลุ่มเดียวกัน
+type Preview struct {
+	RunID          uint          `json:"runId"`
+	Period         Period        `json:"period"`
+	PreviousRunID  *uint         `json:"previousRunId,omitempty"`
+	Employees      []PreviewItem `json:"employees"`
+	Totals         RunTotals     `json:"totals"`
+	PreviousTotals *RunTotals    `json:"previousTotals,omitempty"`
+}
+
+// PreviewItem ผลของพนักงานหนึ่งคน (Result = nil เมื่อ Status = removed)
+// Hired/Left = เริ่มงาน/พ้นสภาพภายในงวดนี้
+type PreviewItem struct {
+	EmployeeID     uint         `json:"employeeId"`
+	Status         string       `json:"status"`
+	Hired          bool         `json:"hired"`
+	Left           bool         `json:"left"`
+	NetPay         money.Amount `json:"netPay"`
+	PreviousNetPay money.Amount `json:"previousNetPay"`
+	NetPayDelta    money.Amount `json:"netPayDelta"`
+	Changes        []LineChange `json:"changes,omitempty"`
+	Result         *Result      `json:"result,omitempty"`
+}
+
+// LineChange ยอดรวมตามรหัสบรรทัดที่ต่างจาก run ก่อนหน้า
+type LineChange struct {
+	Code     string       `json:"code"`
+	Category string       `json:"category"`
+	Previous money.Amount `json:"previous"`
+	Current  money.Amount `json:"current"`
+	Delta    money.Amount `json:"delta"`
+}
+
+// Preview คำนวณ run โดยไม่ล้างหรือบันทึก items และไม่เปลี่ยนสถานะ run
+// แล้วเทียบกับ run ก่อนหน้า (พนักงานใหม่, พ้นสภาพ, เงินสุทธิและรายการที่เปลี่ยน)
+func (s *Service) Preview(runID uint) (Preview, error) {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return Preview{}, ErrRunNotFound
+	}
+	period, results, err := s.compute(run)
+	if err != nil {
+		return Preview{}, err
+	}
+	out := Preview{RunID: run.ID, Period: period, Employees: []PreviewItem{}}
+
+	var prevItems []models.PayrollItem
+	prev, err := s.previousRun(run)
+	if err != nil {
+		return out, err
+	}
+	if prev != nil {
+		if prevItems, err = s.Store.ListPayrollItems(prev.ID); err != nil {
+			return out, err
+		}
+		out.PreviousRunID = &prev.ID
+		pt := Totals(prevItems)
+		out.PreviousTotals = &pt
+	}
+	prevBy := make(map[uint]models.PayrollItem, len(prevItems))
+	for _, it := range prevItems {
+		prevBy[it.EmployeeID] = it
+	}
+
+	emps, err := s.Store.ListEmployees()
+	if err != nil {
+		return out, err
+	}
+	empBy := make(map[uint]models.Employee, len(emps))
+	for _, e := range emps {
+		empBy[e.ID] = e
+	}
+	within := func(id uint) (hired, left bool) {
+		e, ok := empBy[id]
+		if !ok {
+			return false, false
+		}
+		h := dateOnly(e.HiredAt)
+		hired = !h.Before(period.Start) && !h.After(period.End)
+		left = e.TerminatedAt != nil && !dateOnly(*e.TerminatedAt).After(period.End)
+		return hired, left
+	}
+
+	items := make([]models.PayrollItem, 0, len(results))
+	seen := make(map[uint]bool, len(results))
+	for i := range results {
+		res := &results[i]
+		item := res.Item(run.ID)
+		items = append(items, *item)
+		seen[res.EmployeeID] = true
+
+		pi := PreviewItem{EmployeeID: res.EmployeeID, NetPay: item.NetPay, Result: res}
+		pi.Hired, pi.Left = within(res.EmployeeID)
+		if p, ok := prevBy[res.EmployeeID]; ok {
+			pi.PreviousNetPay = p.NetPay
+			pi.Changes = lineChanges(p, *item)
+			pi.Status = PreviewUnchanged
+			if len(pi.Changes) > 0 {
+				pi.Status = PreviewChanged
+			}
+		} else {
+			pi.Status = PreviewNew
+			pi.Changes = lineChanges(models.PayrollItem{}, *item)
+		}
+		pi.NetPayDelta = pi.NetPay - pi.PreviousNetPay
+		out.Employees = append(out.Employees, pi)
+	}
+	for _, p := range prevItems {
+		if seen[p.EmployeeID] {
+			continue
+		}
+		pi := PreviewItem{
+			EmployeeID: p.EmployeeID, Status: PreviewRemoved,
+			PreviousNetPay: p.NetPay, NetPayDelta: -p.NetPay,
+			Changes: lineChanges(p, models.PayrollItem{}),
+		}
+		pi.Hired, pi.Left = within(p.EmployeeID)
+		out.Employees = append(out.Employees, pi)
+	}
+	sort.SliceStable(out.Employees, func(i, j int) bool { return out.Employees[i].EmployeeID < out.Employees[j].EmployeeID })
+	out.Totals = Totals(items)
+	return out, nil
+}
+
+// previousRun run ล่าสุดประเภทเดียวกันของกลุ่มเดียวกันที่สิ้นงวดก่อน run นี้
+// (run นอกรอบของงวดเดียวกันนับ run ที่สร้างก่อน) คืน nil เมื่อไม่มี
+func (s *Service) previousRun(run *models.PayrollRun) (*models.PayrollRun, error) {
+	runs, err := s.Store.ListPayrollRuns(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	end := runEnd(*run)
+	var best *models.PayrollRun
+	for i, r := range runs {
+		if r.ID == run.ID || r.OffCycle() != run.OffCycle() || (r.OffCycle() && r.Type != run.Type) || !r.SameGroup(run.PayGroupID) {
+			continue
+		}
+		re := runEnd(r)
+		if re.After(end) || (re.Equal(end) && (!run.OffCycle() || r.ID > run.ID)) {
+			continue
+		}
+		if best == nil || re.After(runEnd(*best)) || (re.Equal(runEnd(*best)) && r.ID > best.ID) {
+			best = &runs[i]
+		}
+	}
+	return best, nil
+}
+
+// lineChanges เทียบยอดรวมตามรหัสบรรทัด (ไม่นับบรรทัดข้อมูลประกอบ) คืนเฉพาะรหัสที่ยอดต่างกัน
+func lineChanges(prev, cur models.PayrollItem) []LineChange {
+	type key struct{ code, category string }
+	var order []key
+	sums := make(map[key]*LineChange)
+	add := func(it models.PayrollItem, current bool) {
+		for _, l := range it.Lines {
+			if l.Category == models.LineInfo {
+				continue
+			}
+			k := key{l.Code, l.Category}
+			lc, ok := sums[k]
+			if !ok {
+				lc = &LineChange{Code: l.Code, Category: l.Category}
+				sums[k] = lc
+				order = append(order, k)
+			}
+			if current {
+				lc.Current += l.Amount
+			} else {
+				lc.Previous += l.Amount
+			}
+		}
+	}
+	add(cur, true)
+	add(prev, false)
+
+	var out []LineChange
+	for _, k := range order {
+		lc := sums[k]
+		lc.Delta = lc.Current - lc.Previous
+		if lc.Delta != 0 {
+			out = append(out, *lc)
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package payroll
+
+import (
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+func line(code, category string, amount float64) models.PayrollLine {
+	return models.PayrollLine{Code: code, Category: category, Amount: money.FromBaht(amount)}
+}
+
+// lineChanges รวมยอดตามรหัส+หมวด ข้ามบรรทัด info และคืนเฉพาะรหัสที่ยอดต่าง เรียงตาม run ปัจจุบันก่อน
+func TestLineChanges(t *testing.T) {
+	earn, ded, info := models.LineEarning, models.LineDeduction, models.LineInfo
+	cases := []struct {
+		name      string
+		prev, cur []models.PayrollLine
+		want      []LineChange
+	}{
+		{
+			name: "unchanged",
+			prev: []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line(models.CodeSSO, ded, 750)},
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line(models.CodeSSO, ded, 750)},
+		},
+		{
+			name: "info lines are ignored",
+			prev: []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line(models.CodeWorkedDays, info, 0)},
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000)},
+		},
+		{
+			name: "changed amount",
+			prev: []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line(models.CodeTax, ded, 500)},
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 36000), line(models.CodeTax, ded, 500)},
+			want: []LineChange{{Code: models.CodeBaseSalary, Category: earn, Previous: money.FromBaht(30000), Current: money.FromBaht(36000), Delta: money.FromBaht(6000)}},
+		},
+		{
+			// บรรทัดรหัสเดียวกันหลายบรรทัด (เช่นเงินเดือนแยกช่วงกลางงวด) เทียบยอดรวม
+			name: "same code summed",
+			prev: []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000)},
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 14516.13), line(models.CodeBaseSalary, earn, 15483.87)},
+		},
+		{
+			name: "added and removed codes",
+			prev: []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line("UNIFORM", ded, 300)},
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line("OT", earn, 2500)},
+			want: []LineChange{
+				{Code: "OT", Category: earn, Current: money.FromBaht(2500), Delta: money.FromBaht(2500)},
+				{Code: "UNIFORM", Category: ded, Previous: money.FromBaht(300), Delta: money.FromBaht(-300)},
+			},
+		},
+		{
+			// รหัสเดียวกันต่างหมวดแยกกัน
+			name: "category is part of the key",
+			prev: []models.PayrollLine{line("ADJ", earn, 500)},
+			cur:  []models.PayrollLine{line("ADJ", ded, 500)},
+			want: []LineChange{
+				{Code: "ADJ", Category: ded, Current: money.FromBaht(500), Delta: money.FromBaht(500)},
+				{Code: "ADJ", Category: earn, Previous: money.FromBaht(500), Delta: money.FromBaht(-500)},
+			},
+		},
+		{
+			name: "new employee",
+			cur:  []models.PayrollLine{line(models.CodeBaseSalary, earn, 30000), line(models.CodeWorkedDays, info, 31)},
+			want: []LineChange{{Code: models.CodeBaseSalary, Category: earn, Current: money.FromBaht(30000), Delta: money.FromBaht(30000)}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := lineChanges(models.PayrollItem{Lines: tc.prev}, models.PayrollItem{Lines: tc.cur})
+			if len(got) != len(tc.want) {
+				t.Fatalf("changes = %+v, want %+v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("changes[%d] = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+// previousRun: run ประเภทเดียวกัน กลุ่มเดียวกัน สิ้นงวดก่อน (run นอกรอบงวดเดียวกันนับเฉพาะที่สร้างก่อน)
+func TestPreviousRun(t *testing.T) {
+	group := uint(7)
+	// run ของปี 2026 สร้างตามลำดับนี้ (ลำดับการสร้างมีผลกับ run นอกรอบงวดเดียวกัน)
+	runs := []struct {
+		key   string
+		month int
+		typ   string
+		group *uint
+	}{
+		{"jan", 1, models.RunRegular, nil},
+		{"feb", 2, models.RunRegular, nil},
+		{"mar", 3, models.RunRegular, nil},
+		{"apr", 4, models.RunRegular, nil},
+		{"febBonus", 2, models.RunBonus, nil},
+		{"marBonus", 3, models.RunBonus, nil},
+		{"marBonus2", 3, models.RunBonus, nil},
+		{"marCorrection", 3, models.RunCorrection, nil},
+		{"febGroup", 2, models.RunRegular, &group},
+		{"marGroup", 3, models.RunRegular, &group},
+	}
+	cases := []struct {
+		name, run, want string // want ว่าง = ไม่มี run ก่อนหน้า
+	}{
+		{"regular takes the latest earlier period", "mar", "feb"},
+		{"first run has none", "jan", ""},
+		{"off-cycle same period created earlier", "marBonus2", "marBonus"},
+		{"off-cycle earlier period", "marBonus", "febBonus"},
+		{"off-cycle of another type", "marCorrection", ""},
+		{"pay group is kept apart", "marGroup", "febGroup"},
+		{"default group ignores other groups", "feb", "jan"},
+	}
+
+	st := storage.New()
+	s := NewService(st)
+	byKey := make(map[string]*models.PayrollRun, len(runs))
+	for _, r := range runs {
+		run := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: r.month, Type: r.typ, Status: models.RunDraft, PayGroupID: r.group}
+		if err := st.CreatePayrollRun(run); err != nil {
+			t.Fatal(err)
+		}
+		byKey[r.key] = run
+	}
+	for _, tc := range cases {
+		got, err := s.previousRun(byKey[tc.run])
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tc.want == "" && got != nil:
+			t.Errorf("%s: previous = run %d, want none", tc.name, got.ID)
+		case tc.want != "" && (got == nil || got.ID != byKey[tc.want].ID):
+			t.Errorf("%s: previous = %v, want %s (run %d)", tc.name, got, tc.want, byKey[tc.want].ID)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package payroll
+
+import (
+	"sort"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+// RetroPay ส่วนต่างเงินเดือนย้อนหลังของงวดที่ปิดไปแล้ว (run ที่อนุมัติแล้ว) จ่ายเพิ่ม/เรียกคืนใน run ปัจจุบัน
+// Amount = เงินเดือนที่คำนวณใหม่ตามประวัติปัจจุบัน − เงินเดือนที่จ่ายใน run เดิม − ส่วนต่างที่จ่ายไปแล้วใน run อื่น
+type RetroPay struct {
+	RunID        uint         `json:"runId"` // run เดิมของงวดที่คำนวณใหม่
+	Period       Period       `json:"period"`
+	Recalculated money.Amount `json:"recalculated"`
+	Paid         money.Amount `json:"paid"`
+	PaidRetro    money.Amount `json:"paidRetro"`
+	Amount       money.Amount `json:"amount"`
+}
+
+// retroPays คำนวณส่วนต่างย้อนหลังของพนักงานสำหรับ run ปกติ:
+// ตรวจ run ปกติของกลุ่มเดียวกันที่ล็อกแล้วและสิ้นงวดก่อนงวดนี้ ซึ่งมีประวัติเงินเดือนที่บันทึกหลังคำนวณ run นั้น
+// และมีผลภายในงวดของ run นั้น แล้วคำนวณเงินเดือนของงวดนั้นใหม่แบบเสมือน (ไม่แก้ run เดิม)
+func (s *Service) retroPays(e models.Employee, salaries []models.SalaryRecord, leaves []models.Leave, run *models.PayrollRun, period Period, rc *runCache) ([]RetroPay, error) {
+	if run.OffCycle() || len(salaries) == 0 {
+		return nil, nil
+	}
+	var out []RetroPay
+	for _, old := range rc.runs {
+		if old.ID == run.ID || old.OffCycle() || old.Editable() || !old.SameGroup(run.PayGroupID) || !runEnd(old).Before(period.Start) {
+			continue
+		}
+		calculatedAt, err := rc.calculatedAt(old)
+		if err != nil {
+			return nil, err
+		}
+		if !salaryChangedAfter(salaries, runEnd(old), calculatedAt) {
+			continue
+		}
+
+		items, err := rc.items(old.ID)
+		if err != nil {
+			return nil, err
+		}
+		var paid *models.PayrollItem
+		for i := range items {
+			if items[i].EmployeeID == e.ID {
+				paid = &items[i]
+				break
+			}
+		}
+		if paid == nil {
+			continue
+		}
+
+		group, err := s.payGroup(old.PayGroupID)
+		if err != nil {
+			return nil, err
+		}
+		p, err := RunPeriod(&old, group)
+		if err != nil {
+			return nil, err
+		}
+		res, ok := Calculate(Input{Employee: e, Leaves: leaves, Salaries: salaries, Period: p, Rules: rc.rules(p.Start)})
+		if !ok {
+			continue
+		}
+
+		rp := RetroPay{
+			RunID:        old.ID,
+			Period:       p,
+			Recalculated: res.Salary - res.UnpaidLeave,
+			Paid:         paid.SumCodes(models.CodeBaseSalary) - paid.SumCodes(models.CodeUnpaidLeave),
+		}
+		if rp.PaidRetro, err = rc.paidRetro(e.ID, old.ID, run.ID); err != nil {
+			return nil, err
+		}
+		rp.Amount = rp.Recalculated - rp.Paid - rp.PaidRetro
+		if rp.Amount != 0 {
+			out = append(out, rp)
+		}
+	}
+	return out, nil
+}
+
+// salaryChangedAfter มี record ที่บันทึกหลัง since และมีผลไม่เกินวันสิ้นงวด end หรือไม่
+func salaryChangedAfter(salaries []models.SalaryRecord, end, since time.Time) bool {
+	for _, r := range salaries {
+		if r.CreatedAt.After(since) && !dateOnly(r.EffectiveFrom).After(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// runCache ข้อมูล run/items/ตารางอัตราที่ใช้ร่วมกันระหว่างพนักงานใน CalculateRun เดียวกัน
+type runCache struct {
+	store    storage.Port
+	runs     []models.PayrollRun
+	rates    []models.StatutoryRate
+	itemsBy  map[uint][]models.PayrollItem
+	calcTime map[uint]time.Time
+}
+
+func (s *Service) newRunCache(rates []models.StatutoryRate) (*runCache, error) {
+	runs, err := s.Store.ListPayrollRuns(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &runCache{
+		store:    s.Store,
+		runs:     runs,
+		rates:    rates,
+		itemsBy:  make(map[uint][]models.PayrollItem),
+		calcTime: make(map[uint]time.Time),
+	}, nil
+}
+
+func (rc *runCache) rules(at time.Time) Rules {
+	return RulesFor(rc.rates, at)
+}
+
+func (rc *runCache) items(runID uint) ([]models.PayrollItem, error) {
+	if items, ok := rc.itemsBy[runID]; ok {
+		return items, nil
+	}
+	items, err := rc.store.ListPayrollItems(runID)
+	if err != nil {
+		return nil, err
+	}
+	rc.itemsBy[runID] = items
+	return items, nil
+}
+
+// calculatedAt เวลาที่คำนวณ run ครั้งล่าสุด (ไม่นับการยกเลิกอนุมัติ approved → calculated)
+// run ที่ไม่มีประวัติสถานะใช้เวลาที่สร้าง run
+func (rc *runCache) calculatedAt(r models.PayrollRun) (time.Time, error) {
+	if t, ok := rc.calcTime[r.ID]; ok {
+		return t, nil
+	}
+	ts, err := rc.store.ListRunTransitions(r.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	at := r.CreatedAt
+	for _, t := range ts {
+		if t.ToStatus == models.RunCalculated && t.FromStatus != models.RunApproved && t.CreatedAt.After(at) {
+			at = t.CreatedAt
+		}
+	}
+	rc.calcTime[r.ID] = at
+	return at, nil
+}
+
+// paidRetro ส่วนต่างย้อนหลังของงวด refRunID ที่จ่ายไปแล้วใน run อื่น (ไม่นับ run ที่กำลังคำนวณ)
+func (rc *runCache) paidRetro(employeeID, refRunID, currentRunID uint) (money.Amount, error) {
+	var t money.Amount
+	for _, r := range rc.runs {
+		if r.ID == currentRunID || r.ID == refRunID {
+			continue
+		}
+		items, err := rc.items(r.ID)
+		if err != nil {
+			return 0, err
+		}
+		for _, it := range items {
+			if it.EmployeeID != employeeID {
+				continue
+			}
+			for _, l := range it.Lines {
+				if l.Code == models.CodeRetroPay && l.RefRunID != nil && *l.RefRunID == refRunID {
+					t += l.SignedAmount()
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// RetroReport ส่วนต่างย้อนหลังที่จ่ายใน run แยกตามงวดเดิม
+type RetroReport struct {
+	RunID   uint                `json:"runId"`
+	Periods []RetroPeriodReport `json:"periods"`
+	Total   money.Amount        `json:"total"`
+}
+
+// RetroPeriodReport ส่วนต่างของงวดเดิมหนึ่งงวด (RunID = run เดิมของงวดนั้น)
+type RetroPeriodReport struct {
+	RunID       uint            `json:"runId"`
+	PeriodYear  int             `json:"periodYear"`
+	PeriodMonth int             `json:"periodMonth"`
+	PeriodStart time.Time       `json:"periodStart"`
+	PeriodEnd   time.Time       `json:"periodEnd"`
+	Employees   []RetroEmployee `json:"employees"`
+	Total       money.Amount    `json:"total"`
+}
+
+// RetroEmployee ส่วนต่างของพนักงานหนึ่งคนในงวดเดิม (ติดลบ = เรียกคืน)
+type RetroEmployee struct {
+	EmployeeID uint         `json:"employeeId"`
+	Amount     money.Amount `json:"amount"`
+}
+
+// RetroReport สรุปบรรทัด RETRO ของ run แยกตามงวดเดิม เรียงตามวันสิ้นงวด
+func (s *Service) RetroReport(runID uint) (RetroReport, error) {
+	rep := RetroReport{RunID: runID, Periods: []RetroPeriodReport{}}
+	if run, err := s.Store.GetPayrollRun(runID); err != nil || run == nil {
+		return rep, ErrRunNotFound
+	}
+	items, err := s.Store.ListPayrollItems(runID)
+	if err != nil {
+		return rep, err
+	}
+
+	byRun := make(map[uint]*RetroPeriodReport)
+	for _, it := range items {
+		for _, l := range it.Lines {
+			if l.Code != models.CodeRetroPay || l.RefRunID == nil {
+				continue
+			}
+			p, ok := byRun[*l.RefRunID]
+			if !ok {
+				ref, err := s.Store.GetPayrollRun(*l.RefRunID)
+				if err != nil || ref == nil {
+					return rep, ErrRunNotFound
+				}
+				p = &RetroPeriodReport{
+					RunID:       ref.ID,
+					PeriodYear:  ref.PeriodYear,
+					PeriodMonth: ref.PeriodMonth,
+					PeriodStart: runStart(*ref),
+					PeriodEnd:   runEnd(*ref),
+				}
+				byRun[ref.ID] = p
+			}
+			amt := l.SignedAmount()
+			p.Employees = append(p.Employees, RetroEmployee{EmployeeID: it.EmployeeID, Amount: amt})
+			p.Total += amt
+			rep.Total += amt
+		}
+	}
+	for _, p := range byRun {
+		rep.Periods = append(rep.Periods, *p)
+	}
+	sort.Slice(rep.Periods, func(i, j int) bool {
+		a, b := rep.Periods[i], rep.Periods[j]
+		if !a.PeriodEnd.Equal(b.PeriodEnd) {
+			return a.PeriodEnd.Before(b.PeriodEnd)
+		}
+		return a.RunID < b.RunID
+	})
+	return rep, nil
+}
@@ -0,0 +1,194 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+// ส่วนต่างย้อนหลังของงวด ม.ค. 2026 คำนวณให้ run ก.พ.: พนักงานเข้างานปี 2024 เงินเดือน salary
+// run ม.ค. คำนวณ (และอนุมัติตาม approved) ก่อนบันทึกประวัติเงินเดือน change
+func TestRetroPays(t *testing.T) {
+	type change struct {
+		from     time.Time
+		salary   float64
+		recorded time.Time // เวลาที่บันทึก (ก่อน/หลังคำนวณ run ม.ค.)
+	}
+	afterCalc := time.Now().Add(time.Hour)
+	beforeCalc := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		salary   float64
+		change   *change
+		approved bool
+		runType  string
+		want     []float64
+	}{
+		{name: "no change", salary: 30000, approved: true, runType: models.RunRegular},
+		{
+			name: "raise from the start of the period", salary: 30000, approved: true, runType: models.RunRegular,
+			change: &change{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 36000, afterCalc},
+			want:   []float64{6000},
+		},
+		{
+			// 30,000 x 15/31 + 36,000 x 16/31 = 14,516.13 + 18,580.65 − 30,000
+			name: "raise mid-period is prorated", salary: 30000, approved: true, runType: models.RunRegular,
+			change: &change{time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC), 36000, afterCalc},
+			want:   []float64{3096.78},
+		},
+		{
+			name: "decrease is recovered", salary: 30000, approved: true, runType: models.RunRegular,
+			change: &change{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 27000, afterCalc},
+			want:   []float64{-3000},
+		},
+		{
+			name: "effective after the old period", salary: 30000, approved: true, runType: models.RunRegular,
+			change: &change{time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), 36000, afterCalc},
+		},
+		{
+			name: "recorded before the old run was calculated", salary: 30000, approved: true, runType: models.RunRegular,
+			change: &change{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 36000, beforeCalc},
+		},
+		{
+			name: "old run still editable", salary: 30000, approved: false, runType: models.RunRegular,
+			change: &change{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 36000, afterCalc},
+		},
+		{
+			name: "off-cycle run does not pay retro", salary: 30000, approved: true, runType: models.RunBonus,
+			change: &change{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 36000, afterCalc},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := storage.New()
+			s := NewService(st)
+			hired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			e := &models.Employee{EmpCode: "E1", FirstName: "E1", BaseSalary: money.FromBaht(tc.salary), SSOEnabled: true, Status: "active", HiredAt: hired}
+			if err := st.CreateEmployee(e); err != nil {
+				t.Fatal(err)
+			}
+			salaries := []models.SalaryRecord{{EmployeeID: e.ID, EffectiveFrom: hired, BaseSalary: e.BaseSalary, Reason: models.SalaryHire, CreatedAt: beforeCalc}}
+
+			jan := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 1, Type: models.RunRegular, Status: models.RunDraft}
+			if err := st.CreatePayrollRun(jan); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.CalculateRun(jan.ID, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.approved {
+				if _, err := s.Transition(jan.ID, models.RunApproved, "test", ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			feb := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 2, Type: tc.runType, Status: models.RunDraft}
+			if err := st.CreatePayrollRun(feb); err != nil {
+				t.Fatal(err)
+			}
+			if c := tc.change; c != nil {
+				salaries = append(salaries, models.SalaryRecord{EmployeeID: e.ID, EffectiveFrom: c.from, BaseSalary: money.FromBaht(c.salary), Reason: models.SalaryMerit, CreatedAt: c.recorded})
+			}
+
+			rc, err := s.newRunCache(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			period, err := MonthPeriod(2026, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.retroPays(*e, salaries, nil, feb, period, rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("retro = %+v, want %d entries", got, len(tc.want))
+			}
+			for i, rp := range got {
+				if rp.RunID != jan.ID || rp.Amount != money.FromBaht(tc.want[i]) {
+					t.Fatalf("retro[%d] = run %d amount %s, want run %d amount %.2f", i, rp.RunID, rp.Amount, jan.ID, tc.want[i])
+				}
+				if rp.Amount != rp.Recalculated-rp.Paid-rp.PaidRetro {
+					t.Fatalf("retro[%d] amount %s != %s − %s − %s", i, rp.Amount, rp.Recalculated, rp.Paid, rp.PaidRetro)
+				}
+			}
+		})
+	}
+}
+
+// ส่วนต่างที่จ่ายไปแล้วใน run อื่นหักออกจากการคิดย้อนหลังครั้งถัดไป แต่ไม่นับ run ที่กำลังคำนวณใหม่
+// เงินเดือน 30,000 ขึ้นเป็น 36,000 ย้อนหลังถึง 1 ม.ค. หลังปิดงวด ม.ค. แล้วจ่ายส่วนต่าง 6,000 ใน run ก.พ.
+func TestRetroPaidRetro(t *testing.T) {
+	st := storage.New()
+	s := NewService(st)
+	e := &models.Employee{EmpCode: "E1", FirstName: "E1", BaseSalary: money.FromBaht(30000), SSOEnabled: true, Status: "active",
+		HiredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := st.CreateEmployee(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateSalaryRecord(&models.SalaryRecord{EmployeeID: e.ID, EffectiveFrom: e.HiredAt, BaseSalary: e.BaseSalary, Reason: models.SalaryHire}); err != nil {
+		t.Fatal(err)
+	}
+	run := func(month int) *models.PayrollRun {
+		r := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: month, Type: models.RunRegular, Status: models.RunDraft}
+		if err := st.CreatePayrollRun(r); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CalculateRun(r.ID, "test"); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	retroOf := func(r *models.PayrollRun) money.Amount {
+		items, err := st.ListPayrollItems(r.ID)
+		if err != nil || len(items) != 1 {
+			t.Fatalf("run %d items = %d, err %v", r.ID, len(items), err)
+		}
+		return items[0].SumCodes(models.CodeRetroPay)
+	}
+
+	jan := run(1)
+	if _, err := s.Transition(jan.ID, models.RunApproved, "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateSalaryRecord(&models.SalaryRecord{EmployeeID: e.ID, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), BaseSalary: money.FromBaht(36000), Reason: models.SalaryMerit}); err != nil {
+		t.Fatal(err)
+	}
+	feb := run(2)
+	if got := retroOf(feb); got != money.FromBaht(6000) {
+		t.Fatalf("February retro = %s, want 6000.00", got)
+	}
+	if _, err := s.Transition(feb.ID, models.RunApproved, "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	mar := run(3)
+
+	rc, err := s.newRunCache(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		ref, curr uint
+		want      float64
+	}{
+		{"paid in February counts for March", jan.ID, mar.ID, 6000},
+		{"recalculating February excludes itself", jan.ID, feb.ID, 0},
+		{"no retro paid for February", feb.ID, mar.ID, 0},
+	}
+	for _, tc := range cases {
+		got, err := rc.paidRetro(e.ID, tc.ref, tc.curr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != money.FromBaht(tc.want) {
+			t.Errorf("%s: paidRetro = %s, want %.2f", tc.name, got, tc.want)
+		}
+	}
+	if got := retroOf(mar); got != 0 {
+		t.Fatalf("March retro = %s, want 0 (already paid in February)", got)
+	}
+}
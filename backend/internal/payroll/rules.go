@@ -0,0 +1,99 @@
+package payroll
+
+import (
+	"sort"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// Rules ชุดพารามิเตอร์ตามกฎหมายที่ใช้คำนวณงวดหนึ่ง ๆ (resolve มาจาก StatutoryRate)
+type Rules struct {
+	EffectiveFrom time.Time `json:"effectiveFrom"`    // เวอร์ชันของตารางอัตราที่ใช้
+	RateID        uint      `json:"rateId,omitempty"` // 0 = ตารางที่ติดมากับระบบ
+	Note          string    `json:"note,omitempty"`
+
+	SSOMinBase      money.Amount `json:"ssoMinBase"`      // ฐานค่าจ้างขั้นต่ำที่ใช้คิด SSO
+	SSOMaxBase      money.Amount `json:"ssoMaxBase"`      // เพดานค่าจ้างที่ใช้คิด SSO
+	SSORate         float64      `json:"ssoRate"`         // อัตราเงินสมทบฝั่งลูกจ้าง
+	SSOEmployerRate float64      `json:"ssoEmployerRate"` // อัตราเงินสมทบฝั่งนายจ้าง
+
+	PersonalAllowance money.Amount `json:"personalAllowance"`
+	ExpenseRate       float64      `json:"expenseRate"`
+	ExpenseCap        money.Amount `json:"expenseCap"`
+	TaxBrackets       []TaxBracket `json:"taxBrackets"`
+}
+
+// BuiltinRates ตารางอัตราที่ติดมากับระบบ ใช้เมื่อยังไม่มีการบันทึกตารางในฐานข้อมูล
+// และเป็นค่าพื้นฐานที่ตารางที่บันทึกไว้ (วันที่มีผลเดียวกัน) จะ override ได้
+// เพดานค่าจ้าง SSO ปรับตามกฎกระทรวง: 17,500 (2569), 20,000 (2572), 23,000 (2575)
+var BuiltinRates = []models.StatutoryRate{
+	builtinRate(2015, 15000, "ฐาน 1,650–15,000 อัตรา 5%"),
+	builtinRate(2026, 17500, "เพดานค่าจ้าง 17,500"),
+	builtinRate(2029, 20000, "เพดานค่าจ้าง 20,000"),
+	builtinRate(2032, 23000, "เพดานค่าจ้าง 23,000"),
+}
+
+func builtinRate(year int, maxBase float64, note string) models.StatutoryRate {
+	return models.StatutoryRate{
+		EffectiveFrom:     time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC),
+		SSOMinBase:        money.FromBaht(1650),
+		SSOMaxBase:        money.FromBaht(maxBase),
+		SSOEmployeeRate:   0.05,
+		SSOEmployerRate:   0.05,
+		PersonalAllowance: money.FromBaht(60000),
+		ExpenseRate:       0.50,
+		ExpenseCap:        money.FromBaht(100000),
+		TaxBrackets:       DefaultTaxBrackets,
+		Note:              note,
+	}
+}
+
+// RulesFor เลือกตารางอัตราล่าสุดที่มีผลไม่เกินวันที่ at
+// ตารางใน stored จะแทนที่ BuiltinRates ที่มีวันที่มีผลเดียวกัน
+func RulesFor(stored []models.StatutoryRate, at time.Time) Rules {
+	byDate := make(map[time.Time]models.StatutoryRate, len(BuiltinRates)+len(stored))
+	for _, r := range BuiltinRates {
+		byDate[dateOnly(r.EffectiveFrom)] = r
+	}
+	for _, r := range stored {
+		byDate[dateOnly(r.EffectiveFrom)] = r
+	}
+
+	tables := make([]models.StatutoryRate, 0, len(byDate))
+	for _, r := range byDate {
+		tables = append(tables, r)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].EffectiveFrom.Before(tables[j].EffectiveFrom) })
+
+	// ถ้างวดอยู่ก่อนตารางแรก ใช้ตารางแรกไปก่อน
+	chosen := tables[0]
+	for _, r := range tables {
+		if dateOnly(r.EffectiveFrom).After(dateOnly(at)) {
+			break
+		}
+		chosen = r
+	}
+	return rulesFromTable(chosen)
+}
+
+func rulesFromTable(r models.StatutoryRate) Rules {
+	brackets := r.TaxBrackets
+	if len(brackets) == 0 {
+		brackets = DefaultTaxBrackets
+	}
+	return Rules{
+		EffectiveFrom:     dateOnly(r.EffectiveFrom),
+		RateID:            r.ID,
+		Note:              r.Note,
+		SSOMinBase:        r.SSOMinBase,
+		SSOMaxBase:        r.SSOMaxBase,
+		SSORate:           r.SSOEmployeeRate,
+		SSOEmployerRate:   r.SSOEmployerRate,
+		PersonalAllowance: r.PersonalAllowance,
+		ExpenseRate:       r.ExpenseRate,
+		ExpenseCap:        r.ExpenseCap,
+		TaxBrackets:       brackets,
+	}
+}
@@ -0,0 +1,96 @@
+package payroll
+
+import (
+	"sort"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// SalarySpan ช่วงเวลาที่เงินเดือนอัตราหนึ่งมีผล (To = nil คือยังมีผลอยู่)
+type SalarySpan struct {
+	models.SalaryRecord
+	To         *time.Time `json:"effectiveTo"`
+	Superseded bool       `json:"superseded"` // ถูกแทนด้วย record ที่มีผลวันเดียวกันซึ่งบันทึกทีหลัง
+}
+
+// sortSalaries เรียงประวัติเงินเดือนตามวันมีผล แล้วตามลำดับที่บันทึก
+func sortSalaries(records []models.SalaryRecord) []models.SalaryRecord {
+	out := append([]models.SalaryRecord(nil), records...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := dateOnly(out[i].EffectiveFrom), dateOnly(out[j].EffectiveFrom)
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// SalaryTimeline แปลงประวัติเงินเดือนเป็นช่วงเวลาที่แต่ละอัตรามีผล
+func SalaryTimeline(records []models.SalaryRecord) []SalarySpan {
+	sorted := sortSalaries(records)
+	out := make([]SalarySpan, len(sorted))
+	var next *time.Time
+	for i := len(sorted) - 1; i >= 0; i-- {
+		out[i].SalaryRecord = sorted[i]
+		from := dateOnly(sorted[i].EffectiveFrom)
+		if next != nil && next.Equal(from) {
+			out[i].Superseded = true
+			continue
+		}
+		if next != nil {
+			to := next.AddDate(0, 0, -1)
+			out[i].To = &to
+		}
+		next = &from
+	}
+	return out
+}
+
+// SalaryAt เงินเดือนรายเดือนที่มีผล ณ วันที่ d
+// วันก่อน record แรกใช้อัตราของ record แรก และพนักงานที่ไม่มีประวัติใช้ fallback (Employee.BaseSalary)
+func SalaryAt(records []models.SalaryRecord, fallback money.Amount, d time.Time) money.Amount {
+	sorted := sortSalaries(records)
+	if len(sorted) == 0 {
+		return fallback
+	}
+	d = dateOnly(d)
+	rate := sorted[0].BaseSalary
+	for _, r := range sorted {
+		if dateOnly(r.EffectiveFrom).After(d) {
+			break
+		}
+		rate = r.BaseSalary
+	}
+	return rate
+}
+
+// salarySegment ช่วงวันในงวดที่ใช้เงินเดือนอัตราเดียวกัน
+type salarySegment struct {
+	From, To time.Time
+	Monthly  money.Amount
+}
+
+// salarySegments แบ่งช่วง from..to ตามวันที่เงินเดือนเปลี่ยน (ช่วงที่อัตราเท่ากันรวมเป็นช่วงเดียว)
+func salarySegments(records []models.SalaryRecord, fallback money.Amount, from, to time.Time) []salarySegment {
+	if to.Before(from) {
+		return nil
+	}
+	segs := []salarySegment{{From: from, To: to, Monthly: SalaryAt(records, fallback, from)}}
+	for _, r := range sortSalaries(records) {
+		d := dateOnly(r.EffectiveFrom)
+		if !d.After(from) || d.After(to) {
+			continue
+		}
+		rate := SalaryAt(records, fallback, d)
+		last := &segs[len(segs)-1]
+		if rate == last.Monthly {
+			continue
+		}
+		last.To = d.AddDate(0, 0, -1)
+		segs = append(segs, salarySegment{From: d, To: to, Monthly: rate})
+	}
+	return segs
+}
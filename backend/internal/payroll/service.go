@@ -0,0 +1,325 @@
+package payroll
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// ErrRunNotFound ไม่พบ payroll run
+var ErrRunNotFound = errors.New("run not found")
+
+// Service ตัวคำนวณ payroll run ที่ทุก entry point (HTTP handler, services, CLI) เรียกใช้ร่วมกัน
+type Service struct {
+	Store storage.Port
+}
+
+func NewService(store storage.Port) *Service {
+	return &Service{Store: store}
+}
+
+// CalculateRun ล้าง items เดิมแล้วคำนวณใหม่ทั้ง run คืนจำนวนพนักงานที่คำนวณได้
+// คำนวณได้เฉพาะ run ที่ยังไม่อนุมัติ และ run จะเข้าสถานะ calculated (by = ผู้สั่งคำนวณ)
+func (s *Service) CalculateRun(runID uint, by string) (int, error) {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return 0, ErrRunNotFound
+	}
+	if !run.Editable() {
+		return 0, ErrRunLocked
+	}
+
+	_, results, err := s.compute(run)
+	if err != nil {
+		return 0, err
+	}
+	// ล้าง items เดิม บันทึกผลใหม่ และเปลี่ยนสถานะใน transaction เดียว (ล้มกลางทาง = run คงเดิม)
+	err = s.inTx(func(tx *Service) error {
+		if err := tx.Store.ClearPayrollItems(run.ID); err != nil {
+			return err
+		}
+		for _, res := range results {
+			if err := tx.Store.SavePayrollItem(res.Item(run.ID)); err != nil {
+				return err
+			}
+		}
+		return tx.setStatus(run, models.RunCalculated, by, "")
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(results), nil
+}
+
+// inTx ทำ fn ใน transaction เดียวของ store: fn คืน error = ย้อนทุกการเขียนที่ทำผ่าน tx.Store
+func (s *Service) inTx(fn func(tx *Service) error) error {
+	return s.Store.WithTx(func(st storage.Port) error {
+		tx := *s
+		tx.Store = st
+		return fn(&tx)
+	})
+}
+
+// compute คำนวณผลของทุกพนักงานใน run โดยไม่บันทึกอะไร (ใช้ร่วมกันระหว่าง CalculateRun และ Preview)
+// ผลไม่ขึ้นกับ items เดิมของ run นี้ (YTD และ retro นับเฉพาะ run อื่น)
+func (s *Service) compute(run *models.PayrollRun) (Period, []Result, error) {
+	group, err := s.payGroup(run.PayGroupID)
+	if err != nil {
+		return Period{}, nil, err
+	}
+	period, err := RunPeriod(run, group)
+	if err != nil {
+		return Period{}, nil, err
+	}
+
+	inputs, err := s.Store.ListRunInputs(run.ID)
+	if err != nil {
+		return period, nil, err
+	}
+	emps, err := s.runEmployees(run, inputs)
+	if err != nil {
+		return period, nil, err
+	}
+
+	// ใช้ตารางอัตราที่มีผล ณ วันเริ่มงวด เพื่อให้คำนวณ run ย้อนหลังด้วยกติกาของปีนั้น
+	rates, err := s.Store.ListStatutoryRates()
+	if err != nil {
+		return period, nil, err
+	}
+	rules := RulesFor(rates, period.Start)
+	leaves, err := s.Store.ListLeaves()
+	if err != nil {
+		return period, nil, err
+	}
+	rc, err := s.newRunCache(rates)
+	if err != nil {
+		return period, nil, err
+	}
+
+	results := make([]Result, 0, len(emps))
+	for _, e := range emps {
+		ytd, err := s.YearToDate(e, run)
+		if err != nil {
+			return period, nil, err
+		}
+
+		comps, err := s.Store.ListPayComponents(e.ID)
+		if err != nil {
+			return period, nil, err
+		}
+
+		salaries, err := s.Store.ListSalaryRecords(e.ID)
+		if err != nil {
+			return period, nil, err
+		}
+
+		in := Input{Employee: e, Components: runComponents(run, comps), Inputs: inputs, Leaves: leaves, Salaries: salaries, Period: period, Rules: rules, YTD: ytd}
+		if run.OffCycle() {
+			if in.OffCycle, err = s.regularBasis(e, salaries, run, group, period, rules); err != nil {
+				return period, nil, err
+			}
+		} else if in.Retro, err = s.retroPays(e, salaries, leaves, run, period, rc); err != nil {
+			return period, nil, err
+		}
+		if in.Garnish, err = s.garnishmentDues(e, run, period, rc); err != nil {
+			return period, nil, err
+		}
+		if in.Loans, err = s.loanDues(e, run, period, rc); err != nil {
+			return period, nil, err
+		}
+		res, ok := Calculate(in)
+		if !ok {
+			continue
+		}
+		results = append(results, res)
+	}
+	return period, results, nil
+}
+
+// payGroup โหลดกลุ่มจ่ายเงิน (nil = กลุ่มรายเดือนตั้งต้น)
+func (s *Service) payGroup(id *uint) (*models.PayGroup, error) {
+	if id == nil {
+		return nil, nil
+	}
+	return s.Store.GetPayGroup(*id)
+}
+
+// RegularRun run ปกติของกลุ่มจ่ายเงินในงวด p (nil เมื่อยังไม่มี)
+func (s *Service) RegularRun(groupID *uint, p Period) (*models.PayrollRun, error) {
+	runs, err := s.Store.ListPayrollRuns(p.End.Year(), int(p.End.Month()))
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range runs {
+		if !r.OffCycle() && r.SameGroup(groupID) && runStart(r).Equal(p.Start) {
+			return &runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// runEmployees พนักงานที่รวมใน run: ตาม EmployeeIDs ถ้าระบุ (รวมพนักงานที่พ้นสภาพแล้ว),
+// ไม่เช่นนั้น run ปกติใช้พนักงาน active ทุกคนในกลุ่มจ่ายเงินของ run รวมผู้ที่พ้นสภาพตั้งแต่วันเริ่มงวด
+// (ได้เงินเดือนตามสัดส่วนถึงวันพ้นสภาพ) และ run นอกรอบใช้เฉพาะผู้ที่มี RunInput
+func (s *Service) runEmployees(run *models.PayrollRun, inputs []models.RunInput) ([]models.Employee, error) {
+	if len(run.EmployeeIDs) == 0 && !run.OffCycle() {
+		all, err := s.Store.ListEmployees()
+		if err != nil {
+			return nil, err
+		}
+		start := runStart(*run)
+		out := make([]models.Employee, 0, len(all))
+		for _, e := range all {
+			leftInPeriod := e.TerminatedAt != nil && !dateOnly(*e.TerminatedAt).Before(start)
+			if (e.Status == "active" || leftInPeriod) && run.SameGroup(e.PayGroupID) {
+				out = append(out, e)
+			}
+		}
+		return out, nil
+	}
+
+	want := make(map[uint]bool)
+	for _, id := range run.EmployeeIDs {
+		want[id] = true
+	}
+	if len(want) == 0 {
+		for _, ri := range inputs {
+			want[ri.EmployeeID] = true
+		}
+	}
+
+	all, err := s.Store.ListEmployees()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Employee, 0, len(want))
+	for _, e := range all {
+		if want[e.ID] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// runComponents รายการประจำที่รวมใน run: ตาม ComponentCodes ถ้าระบุ,
+// ไม่เช่นนั้น run ปกติรวมทั้งหมด และ run นอกรอบไม่รวม
+func runComponents(run *models.PayrollRun, comps []models.PayComponent) []models.PayComponent {
+	if len(run.ComponentCodes) == 0 {
+		if run.OffCycle() {
+			return nil
+		}
+		return comps
+	}
+	out := make([]models.PayComponent, 0, len(comps))
+	for _, pc := range comps {
+		for _, code := range run.ComponentCodes {
+			if pc.Code == code {
+				out = append(out, pc)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// regularBasis เงินได้ปกติต่องวดที่ใช้ประมาณการทั้งปีสำหรับภาษีเงินได้ครั้งเดียว:
+// ใช้ผลของ run ปกติในงวดเดียวกันถ้าคำนวณไว้ก่อนแล้ว (อยู่ใน YTD แล้ว จึงเหลืองวดถัดไป)
+// ไม่เช่นนั้นประมาณจากเงินเดือนที่มีผล ณ วันสิ้นงวด (รวมงวดนี้ในจำนวนงวดที่เหลือ)
+// พนักงานที่พ้นสภาพไม่มีเงินได้ปกติในงวดที่เริ่มหลังวันออกจากงาน
+func (s *Service) regularBasis(e models.Employee, salaries []models.SalaryRecord, run *models.PayrollRun, group *models.PayGroup, period Period, rules Rules) (*OffCycle, error) {
+	cal, err := Calendar(group, run.PeriodYear)
+	if err != nil {
+		return nil, err
+	}
+	left := func(fromIndex int) int {
+		n := 0
+		for _, p := range cal {
+			if p.Index >= fromIndex && (e.TerminatedAt == nil || !p.Start.After(dateOnly(*e.TerminatedAt))) {
+				n++
+			}
+		}
+		return n
+	}
+
+	regular, err := s.RegularRun(run.PayGroupID, period)
+	if err != nil {
+		return nil, err
+	}
+	if regular != nil && regular.ID < run.ID {
+		items, err := s.Store.ListPayrollItems(regular.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			if it.EmployeeID == e.ID {
+				return &OffCycle{
+					Income:    it.TaxableIncome(),
+					SSO:       it.SumCodes(models.CodeSSO),
+					PVD:       it.SumCodes(models.CodePVD),
+					Remaining: left(period.Index + 1),
+				}, nil
+			}
+		}
+	}
+
+	pay := PeriodAmount(SalaryAt(salaries, e.BaseSalary, period.End), period)
+	oc := &OffCycle{Income: pay, PVD: pay.MulRate(e.PVDRate), Remaining: left(period.Index)}
+	if e.SSOEnabled {
+		oc.SSO = ssoBase(pay, rules).MulRate(rules.SSORate)
+	}
+	return oc, nil
+}
+
+// YearToDate รวมยอดเงินได้/SSO/PVD/ภาษีของพนักงานจากทุก run ในปีเดียวกันที่อยู่ก่อน run นี้
+// (งวดที่สิ้นสุดก่อน และ run ของงวดเดียวกันที่สร้างก่อน) ภาษีสะสมนับเฉพาะบรรทัด TAX
+// (ไม่รวมส่วนที่หักเพิ่มตาม WithholdingRate) ยอด Period* รวมเฉพาะ run ในเดือนเดียวกัน
+func (s *Service) YearToDate(e models.Employee, run *models.PayrollRun) (YTD, error) {
+	var ytd YTD
+	runs, err := s.Store.ListPayrollRuns(run.PeriodYear, 0)
+	if err != nil {
+		return ytd, err
+	}
+	end := runEnd(*run)
+	for _, r := range runs {
+		if re := runEnd(r); re.After(end) || (re.Equal(end) && r.ID >= run.ID) {
+			continue
+		}
+		sameMonth := r.PeriodMonth == run.PeriodMonth
+		items, err := s.Store.ListPayrollItems(r.ID)
+		if err != nil {
+			return ytd, err
+		}
+		for _, it := range items {
+			if it.EmployeeID != e.ID {
+				continue
+			}
+			ytd.Income += it.TaxableIncome()
+			ytd.SSO += it.SumCodes(models.CodeSSO)
+			ytd.PVD += it.SumCodes(models.CodePVD)
+			ytd.Tax += it.SumCodes(models.CodeTax)
+			if sameMonth {
+				ytd.PeriodSSOWage += it.SSOWage()
+				ytd.PeriodSSO += it.SumCodes(models.CodeSSO)
+				ytd.PeriodEmployerSSO += it.SumCodes(models.CodeSSOEmployer)
+			}
+		}
+	}
+	return ytd, nil
+}
+
+// runStart / runEnd วันเริ่ม/สิ้นงวดของ run (run ที่ไม่ระบุงวดคือเต็มเดือน)
+func runStart(r models.PayrollRun) time.Time {
+	if r.PeriodStart != nil {
+		return dateOnly(*r.PeriodStart)
+	}
+	return time.Date(r.PeriodYear, time.Month(r.PeriodMonth), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func runEnd(r models.PayrollRun) time.Time {
+	if r.PeriodEnd != nil {
+		return dateOnly(*r.PeriodEnd)
+	}
+	return runStart(r).AddDate(0, 1, -1)
+}
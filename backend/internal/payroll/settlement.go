@@ -0,0 +1,280 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/leave"
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+var (
+	// ErrAlreadyTerminated พนักงานพ้นสภาพไปแล้ว
+	ErrAlreadyTerminated = errors.New("employee is already terminated")
+	// ErrInvalidTermination วันที่/เหตุที่พ้นสภาพไม่ถูกต้อง
+	ErrInvalidTermination = errors.New("invalid termination")
+	// ErrEmployeeNotFound ไม่พบพนักงาน
+	ErrEmployeeNotFound = errors.New("employee not found")
+)
+
+// ค่าชดเชยส่วนที่ได้รับยกเว้นภาษี (ม.42(17) ประกอบกฎกระทรวงฉบับที่ 126 ที่แก้ไขปี 2562
+// ให้สอดคล้องกับอัตราค่าชดเชย 400 วัน): ไม่เกินค่าจ้าง 400 วันสุดท้าย และไม่เกิน 600,000 บาท
+const severanceExemptDays = 400
+
+var severanceExemptCap = money.FromBaht(600000)
+
+// severanceTiers อัตราค่าชดเชยตาม ม.118: อายุงานครบ (ปี, วัน) → ค่าจ้างอัตราสุดท้ายกี่วัน
+// เรียงจากมากไปน้อย อายุงานไม่ถึง 120 วันไม่มีสิทธิ
+var severanceTiers = []struct{ years, days int }{
+	{20, 400}, {10, 300}, {6, 240}, {3, 180}, {1, 90},
+}
+
+// Settlement เงินที่ต้องจ่ายเมื่อพ้นสภาพตาม พ.ร.บ.คุ้มครองแรงงาน
+// ค่าจ้างรายวัน = เงินเดือนอัตราสุดท้าย / 30
+type Settlement struct {
+	EmployeeID   uint      `json:"employeeId"`
+	Reason       string    `json:"reason"`
+	HiredAt      time.Time `json:"hiredAt"`
+	TerminatedAt time.Time `json:"terminatedAt"`
+	NoticeDate   time.Time `json:"noticeDate"`
+	ServiceDays  int       `json:"serviceDays"`
+	ServiceYears int       `json:"serviceYears"` // ปีเต็ม
+
+	MonthlyWage money.Amount `json:"monthlyWage"`
+	DailyWage   money.Amount `json:"dailyWage"`
+
+	SeveranceDays    int          `json:"severanceDays"`
+	Severance        money.Amount `json:"severance"`
+	SeveranceExempt  money.Amount `json:"severanceExempt"`
+	SeveranceTaxable money.Amount `json:"severanceTaxable"`
+
+	// NoticeEffective วันที่การบอกกล่าวมีผลเร็วที่สุด (ม.17: ณ วันจ่ายค่าจ้างงวดถัดไป ไม่เกิน 3 เดือน)
+	// NoticeShortDays วันที่ขาดไปนับจากวันพ้นสภาพ → ค่าจ้างแทนการบอกกล่าวล่วงหน้า (ม.17/1)
+	NoticeEffective *time.Time   `json:"noticeEffective,omitempty"`
+	NoticeShortDays int          `json:"noticeShortDays"`
+	NoticePay       money.Amount `json:"noticePay"`
+
+	LeaveDays   int          `json:"leaveDays"` // วันหยุดพักผ่อนประจำปีคงเหลือ (รวมยกมา)
+	LeavePayout money.Amount `json:"leavePayout"`
+
+	Total money.Amount `json:"total"`
+}
+
+// Settlement คำนวณเงินที่ต้องจ่ายเมื่อพนักงานพ้นสภาพ ณ วันที่ date ด้วยเหตุ reason
+// notice = วันที่บอกกล่าว (nil = บอกกล่าววันเดียวกับวันพ้นสภาพ) ไม่บันทึกอะไร
+func (s *Service) Settlement(e models.Employee, date time.Time, reason string, notice *time.Time) (Settlement, error) {
+	date = dateOnly(date)
+	hired := dateOnly(e.HiredAt)
+	if !models.ValidTerminationReason(reason) || date.Before(hired) {
+		return Settlement{}, ErrInvalidTermination
+	}
+	noticeDate := date
+	if notice != nil {
+		noticeDate = dateOnly(*notice)
+		if noticeDate.After(date) {
+			return Settlement{}, ErrInvalidTermination
+		}
+	}
+
+	salaries, err := s.Store.ListSalaryRecords(e.ID)
+	if err != nil {
+		return Settlement{}, err
+	}
+	st := Settlement{
+		EmployeeID:   e.ID,
+		Reason:       reason,
+		HiredAt:      hired,
+		TerminatedAt: date,
+		NoticeDate:   noticeDate,
+		ServiceDays:  daysBetween(hired, date) + 1,
+		ServiceYears: leave.ServiceMonths(hired, date.AddDate(0, 0, 1)) / 12,
+		MonthlyWage:  SalaryAt(salaries, e.BaseSalary, date),
+	}
+	st.DailyWage = st.MonthlyWage.Div(30)
+
+	// ค่าชดเชย: เลิกจ้าง/เกษียณเท่านั้น
+	if reason == models.TerminationLayoff || reason == models.TerminationRetirement {
+		st.SeveranceDays = severanceDays(st.ServiceDays, st.ServiceYears)
+		st.Severance = st.MonthlyWage.MulDiv(int64(st.SeveranceDays), 30)
+		st.SeveranceExempt = money.Min(st.Severance, money.Min(st.MonthlyWage.MulDiv(severanceExemptDays, 30), severanceExemptCap))
+		st.SeveranceTaxable = st.Severance - st.SeveranceExempt
+	}
+
+	// ค่าจ้างแทนการบอกกล่าวล่วงหน้า: เลิกจ้างโดยบอกกล่าวไม่ทันรอบจ่ายค่าจ้างถัดไป
+	if reason == models.TerminationLayoff {
+		group, err := s.payGroup(e.PayGroupID)
+		if err != nil {
+			return Settlement{}, err
+		}
+		effective, err := noticeEffective(group, noticeDate)
+		if err != nil {
+			return Settlement{}, err
+		}
+		st.NoticeEffective = &effective
+		if effective.After(date) {
+			st.NoticeShortDays = daysBetween(date, effective)
+			st.NoticePay = st.MonthlyWage.MulDiv(int64(st.NoticeShortDays), 30)
+		}
+	}
+
+	// วันหยุดพักผ่อนประจำปีคงเหลือ (ม.67): ไม่จ่ายเมื่อเลิกจ้างเพราะกระทำผิดตาม ม.119
+	if reason != models.TerminationMisconduct {
+		stored, err := s.Store.ListLeavePolicies()
+		if err != nil {
+			return Settlement{}, err
+		}
+		if p, ok := leave.PolicyFor(stored, models.LeaveAnnual); ok {
+			leaves, err := s.Store.ListLeaves()
+			if err != nil {
+				return Settlement{}, err
+			}
+			if b := leave.ComputeBalance(p, e, leaves, date); b.Remaining > 0 {
+				st.LeaveDays = b.Remaining
+				st.LeavePayout = st.MonthlyWage.MulDiv(int64(b.Remaining), 30)
+			}
+		}
+	}
+
+	st.Total = st.Severance + st.NoticePay + st.LeavePayout
+	return st, nil
+}
+
+// severanceDays จำนวนวันค่าชดเชยตามอายุงาน (ทำงานครบ 120 วันแต่ไม่ถึง 1 ปี = 30 วัน)
+func severanceDays(serviceDays, serviceYears int) int {
+	for _, t := range severanceTiers {
+		if serviceYears >= t.years {
+			return t.days
+		}
+	}
+	if serviceDays >= 120 {
+		return 30
+	}
+	return 0
+}
+
+// noticeEffective วันที่การบอกกล่าว ณ วันที่ notice มีผลเร็วที่สุด:
+// วันสิ้นงวดถัดจากงวดที่บอกกล่าว (ถือวันสิ้นงวดเป็นวันจ่ายค่าจ้าง) แต่ไม่เกิน 3 เดือนนับจากวันบอกกล่าว
+func noticeEffective(group *models.PayGroup, notice time.Time) (time.Time, error) {
+	cur, err := PeriodOn(group, notice)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, err := PeriodOn(group, cur.End.AddDate(0, 0, 1))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return minTime(next.End, notice.AddDate(0, 3, 0)), nil
+}
+
+// Inputs รายการจ่ายครั้งเดียวของ run ประเภท termination
+// ค่าชดเชย/ค่าบอกกล่าว/ค่าพักร้อนไม่ใช่ค่าจ้างที่ต้องนำส่งประกันสังคม
+func (st Settlement) Inputs(runID uint) []models.RunInput {
+	var out []models.RunInput
+	add := func(code, desc string, amount money.Amount, taxable bool) {
+		if amount <= 0 {
+			return
+		}
+		out = append(out, models.RunInput{
+			RunID: runID, EmployeeID: st.EmployeeID, Code: code, Description: desc,
+			Kind: models.ComponentEarning, Amount: amount, Taxable: taxable,
+		})
+	}
+	add(models.CodeSeveranceExempt, fmt.Sprintf("Severance pay %d days (tax exempt)", st.SeveranceDays), st.SeveranceExempt, false)
+	add(models.CodeSeverance, fmt.Sprintf("Severance pay %d days", st.SeveranceDays), st.SeveranceTaxable, true)
+	add(models.CodeNoticePay, fmt.Sprintf("Pay in lieu of notice %d days", st.NoticeShortDays), st.NoticePay, true)
+	add(models.CodeLeavePayout, fmt.Sprintf("Unused annual leave %d days", st.LeaveDays), st.LeavePayout, true)
+	return out
+}
+
+// TerminateRequest ข้อมูลการพ้นสภาพ
+type TerminateRequest struct {
+	Date       time.Time
+	Reason     string
+	NoticeDate *time.Time
+	Note       string
+}
+
+// Terminate บันทึกการพ้นสภาพของพนักงาน แล้วสร้างและคำนวณ run ประเภท termination
+// ในงวดที่ครอบคลุมวันพ้นสภาพสำหรับเงินที่ต้องจ่าย (run = nil เมื่อไม่มีเงินต้องจ่าย)
+// เงินเดือนถึงวันพ้นสภาพยังจ่ายใน run ปกติของงวดนั้นตามสัดส่วนวันทำงาน
+func (s *Service) Terminate(employeeID uint, req TerminateRequest, by string) (Settlement, *models.PayrollRun, error) {
+	e, err := s.Store.GetEmployee(employeeID)
+	if err != nil || e == nil {
+		return Settlement{}, nil, ErrEmployeeNotFound
+	}
+	if e.TerminatedAt != nil || e.Status == "terminated" {
+		return Settlement{}, nil, ErrAlreadyTerminated
+	}
+	st, err := s.Settlement(*e, req.Date, req.Reason, req.NoticeDate)
+	if err != nil {
+		return st, nil, err
+	}
+
+	// สถานะพนักงาน, run และ input ของ run บันทึกพร้อมกัน: คำนวณไม่สำเร็จ = พนักงานยังไม่พ้นสภาพ
+	var run *models.PayrollRun
+	err = s.inTx(func(tx *Service) error {
+		run, err = tx.terminate(e, st, req, by)
+		return err
+	})
+	if err != nil {
+		return st, nil, err
+	}
+	return st, run, nil
+}
+
+// terminate บันทึกการพ้นสภาพและสร้าง run ของ Terminate (เรียกภายใน transaction)
+func (s *Service) terminate(e *models.Employee, st Settlement, req TerminateRequest, by string) (*models.PayrollRun, error) {
+	date := st.TerminatedAt
+	e.TerminatedAt = &date
+	e.Status = "terminated"
+	e.TerminationReason = req.Reason
+	e.TerminationNote = req.Note
+	e.NoticeDate = nil
+	if req.NoticeDate != nil {
+		nd := st.NoticeDate
+		e.NoticeDate = &nd
+	}
+	if err := s.Store.UpdateEmployee(e); err != nil {
+		return nil, err
+	}
+	if st.Total <= 0 {
+		return nil, nil
+	}
+
+	group, err := s.payGroup(e.PayGroupID)
+	if err != nil {
+		return nil, err
+	}
+	run := &models.PayrollRun{
+		PeriodYear:  date.Year(),
+		PeriodMonth: int(date.Month()),
+		Type:        models.RunTermination,
+		Description: fmt.Sprintf("Final settlement %s (%s)", e.EmpCode, req.Reason),
+		EmployeeIDs: []uint{e.ID},
+		PayGroupID:  e.PayGroupID,
+		Status:      models.RunDraft,
+	}
+	if group != nil {
+		p, err := PeriodOn(group, date)
+		if err != nil {
+			return nil, err
+		}
+		run.PeriodStart, run.PeriodEnd = &p.Start, &p.End
+		run.PeriodYear, run.PeriodMonth = p.End.Year(), int(p.End.Month())
+	}
+	if err := s.Store.CreatePayrollRun(run); err != nil {
+		return nil, err
+	}
+	for _, ri := range st.Inputs(run.ID) {
+		ri := ri
+		if err := s.Store.CreateRunInput(&ri); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.CalculateRun(run.ID, by); err != nil {
+		return nil, err
+	}
+	return s.Store.GetPayrollRun(run.ID)
+}
@@ -0,0 +1,187 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// ค่าชดเชยตาม ม.118 นับอายุงานถึงวันพ้นสภาพ (รวมวันสุดท้าย)
+func TestSettlementSeveranceTiers(t *testing.T) {
+	s, _ := newTestService()
+	cases := []struct {
+		name  string
+		hired time.Time
+		date  time.Time
+		days  int
+	}{
+		{"119 days", date(2026, 1, 1), date(2026, 4, 29), 0},
+		{"120 days", date(2026, 1, 1), date(2026, 4, 30), 30},
+		{"one day short of 1 year", date(2025, 3, 21), date(2026, 3, 19), 30},
+		{"1 year", date(2025, 3, 21), date(2026, 3, 20), 90},
+		{"one day short of 3 years", date(2023, 3, 22), date(2026, 3, 20), 90},
+		{"3 years", date(2023, 3, 21), date(2026, 3, 20), 180},
+		{"6 years", date(2020, 3, 21), date(2026, 3, 20), 240},
+		{"10 years", date(2016, 3, 21), date(2026, 3, 20), 300},
+		{"one day short of 20 years", date(2006, 3, 22), date(2026, 3, 20), 300},
+		{"20 years", date(2006, 3, 21), date(2026, 3, 20), 400},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := models.Employee{ID: 1, BaseSalary: baht(30000), HiredAt: tc.hired}
+			st, err := s.Settlement(e, tc.date, models.TerminationRetirement, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.SeveranceDays != tc.days {
+				t.Fatalf("severance days = %d, want %d (service %d days, %d years)", st.SeveranceDays, tc.days, st.ServiceDays, st.ServiceYears)
+			}
+			if want := baht(1000).Mul(int64(tc.days)); st.Severance != want {
+				t.Fatalf("severance = %s, want %s", st.Severance, want)
+			}
+		})
+	}
+}
+
+// ค่าชดเชยส่วนที่ยกเว้นภาษีไม่เกินค่าจ้าง 400 วันสุดท้ายและ 600,000 บาท; ลาออก/กระทำผิดไม่ได้ค่าชดเชย
+// พ้นสภาพ 20 มี.ค. 2026 ค่าจ้างรายวัน = เงินเดือน / 30
+func TestSettlementSeveranceExemptionAndReason(t *testing.T) {
+	s, _ := newTestService()
+	cases := []struct {
+		name              string
+		reason            string
+		hired             time.Time
+		salary            float64
+		exempt, taxable   float64
+		severance, notice bool
+	}{
+		// อายุงาน 20 ปี: 400 วัน = 400,000 ยกเว้นทั้งหมด (เกณฑ์เดิม 300 วัน/300,000 จะเสียภาษี 100,000)
+		{"layoff after 20 years", models.TerminationLayoff, date(2006, 3, 21), 30000, 400000, 0, true, true},
+		{"retirement after 20 years", models.TerminationRetirement, date(2006, 3, 21), 15000, 200000, 0, true, false},
+		// 400 วัน = 600,000 พอดีเพดาน
+		{"20 years at the cap", models.TerminationRetirement, date(2006, 3, 21), 45000, 600000, 0, true, false},
+		// 400 วัน = 800,000 ยกเว้น 600,000
+		{"20 years over the cap", models.TerminationRetirement, date(2006, 3, 21), 60000, 600000, 200000, true, false},
+		{"retirement after 10 years", models.TerminationRetirement, date(2016, 3, 21), 30000, 300000, 0, true, false},
+		{"resignation", models.TerminationResignation, date(2006, 3, 21), 30000, 0, 0, false, false},
+		{"misconduct", models.TerminationMisconduct, date(2006, 3, 21), 30000, 0, 0, false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := models.Employee{ID: 1, BaseSalary: baht(tc.salary), HiredAt: tc.hired}
+			st, err := s.Settlement(e, date(2026, 3, 20), tc.reason, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.SeveranceExempt != baht(tc.exempt) || st.SeveranceTaxable != baht(tc.taxable) {
+				t.Fatalf("exempt/taxable = %s/%s, want %.2f/%.2f", st.SeveranceExempt, st.SeveranceTaxable, tc.exempt, tc.taxable)
+			}
+			if (st.Severance > 0) != tc.severance || (st.NoticePay > 0) != tc.notice {
+				t.Fatalf("severance %s, notice pay %s for %s", st.Severance, st.NoticePay, tc.reason)
+			}
+		})
+	}
+}
+
+// ค่าจ้างแทนการบอกกล่าวล่วงหน้า (ม.17/1): การบอกกล่าวมีผลวันสิ้นงวดถัดจากงวดที่บอกกล่าว ไม่เกิน 3 เดือน
+func TestSettlementNoticePay(t *testing.T) {
+	s, _ := newTestService()
+	cases := []struct {
+		name      string
+		notice    *time.Time
+		effective time.Time
+		short     int
+	}{
+		{"no notice", nil, date(2026, 4, 30), 41},
+		{"notice in the previous period", ptr(date(2026, 2, 10)), date(2026, 3, 31), 11},
+		{"notice two periods ahead", ptr(date(2026, 1, 15)), date(2026, 2, 28), 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := models.Employee{ID: 1, BaseSalary: baht(30000), HiredAt: date(2024, 1, 1)}
+			st, err := s.Settlement(e, date(2026, 3, 20), models.TerminationLayoff, tc.notice)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.NoticeEffective == nil || !st.NoticeEffective.Equal(tc.effective) {
+				t.Fatalf("notice effective = %v, want %s", st.NoticeEffective, tc.effective.Format(time.DateOnly))
+			}
+			if st.NoticeShortDays != tc.short {
+				t.Fatalf("short days = %d, want %d", st.NoticeShortDays, tc.short)
+			}
+			if want := baht(1000).Mul(int64(tc.short)); st.NoticePay != want {
+				t.Fatalf("notice pay = %s, want %s", st.NoticePay, want)
+			}
+		})
+	}
+}
+
+func TestSettlementInvalid(t *testing.T) {
+	s, _ := newTestService()
+	e := models.Employee{ID: 1, BaseSalary: baht(30000), HiredAt: date(2024, 1, 1)}
+	for name, fn := range map[string]func() error{
+		"before hire":    func() error { _, err := s.Settlement(e, date(2023, 12, 31), models.TerminationLayoff, nil); return err },
+		"unknown reason": func() error { _, err := s.Settlement(e, date(2026, 3, 20), "fired", nil); return err },
+		"notice after date": func() error {
+			_, err := s.Settlement(e, date(2026, 3, 20), models.TerminationLayoff, ptr(date(2026, 3, 21)))
+			return err
+		},
+	} {
+		if err := fn(); !errors.Is(err, ErrInvalidTermination) {
+			t.Errorf("%s: err = %v, want ErrInvalidTermination", name, err)
+		}
+	}
+}
+
+// failingInputs store ที่บันทึก input ของ run ไม่ได้ ใช้ทดสอบว่า Terminate ย้อนกลับทั้งหมด
+type failingInputs struct{ storage.Port }
+
+var errInjected = errors.New("injected")
+
+func (f failingInputs) CreateRunInput(*models.RunInput) error { return errInjected }
+
+func (f failingInputs) WithTx(fn func(tx storage.Port) error) error {
+	return f.Port.WithTx(func(tx storage.Port) error { return fn(failingInputs{tx}) })
+}
+
+func TestTerminateIsAtomic(t *testing.T) {
+	_, st := newTestService()
+	e := addEmployee(t, st, "E1", 30000, true)
+	s := NewService(failingInputs{st})
+
+	if _, _, err := s.Terminate(e.ID, TerminateRequest{Date: date(2026, 3, 20), Reason: models.TerminationLayoff}, "test"); !errors.Is(err, errInjected) {
+		t.Fatalf("err = %v, want the injected error", err)
+	}
+	got, _ := st.GetEmployee(e.ID)
+	if got.TerminatedAt != nil || got.Status == "terminated" {
+		t.Fatalf("employee was terminated although the settlement run failed: %+v", got)
+	}
+	if runs, _ := st.ListPayrollRuns(0, 0); len(runs) != 0 {
+		t.Fatalf("runs = %d, want 0", len(runs))
+	}
+}
+
+func TestTerminateCreatesCalculatedRun(t *testing.T) {
+	s, st := newTestService()
+	e := addEmployee(t, st, "E1", 30000, true)
+
+	set, run, err := s.Terminate(e.ID, TerminateRequest{Date: date(2026, 3, 20), Reason: models.TerminationLayoff}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || run.Type != models.RunTermination || run.Status != models.RunCalculated {
+		t.Fatalf("run = %+v, want a calculated termination run", run)
+	}
+	it := itemOf(t, st, run.ID, e.ID)
+	if got := it.SumCodes(models.CodeSeveranceExempt, models.CodeSeverance, models.CodeNoticePay, models.CodeLeavePayout); got != set.Total {
+		t.Fatalf("settlement lines = %s, want %s", got, set.Total)
+	}
+	if _, _, err := s.Terminate(e.ID, TerminateRequest{Date: date(2026, 3, 20), Reason: models.TerminationLayoff}, "test"); !errors.Is(err, ErrAlreadyTerminated) {
+		t.Fatalf("second terminate: err = %v, want ErrAlreadyTerminated", err)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
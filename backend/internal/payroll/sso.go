@@ -0,0 +1,108 @@
+package payroll
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/ssofile"
+)
+
+// ErrRunNotCalculated ไฟล์นำส่งเงินสมทบสร้างได้เมื่อ run คำนวณแล้ว (ยังเป็น draft = ยังไม่มียอด SSO)
+var ErrRunNotCalculated = errors.New("payroll run is not calculated")
+
+// SSOReport รายการนำส่งเงินสมทบประกันสังคม (สปส.1-10) ของเดือนของ run
+// เงินสมทบเป็นยอดรายเดือน จึงรวมทุก run ของเดือนเดียวกันที่คำนวณแล้ว (run นอกรอบ และงวดของกลุ่มรายสัปดาห์/รายปักษ์)
+// ค่าจ้างที่ใช้คำนวณ = ค่าจ้างรวมของเดือนปรับตามฐานขั้นต่ำ/เพดาน; เงินสมทบ = ยอดที่หัก/สมทบจริงในแต่ละ run
+// พนักงานที่ไม่ได้ระบุสาขาใช้สาขาตั้งต้นของนายจ้าง
+func (s *Service) SSOReport(runID uint) (ssofile.Report, error) {
+	run, err := s.Store.GetPayrollRun(runID)
+	if err != nil || run == nil {
+		return ssofile.Report{}, ErrRunNotFound
+	}
+	if !calculated(*run) {
+		return ssofile.Report{}, ErrRunNotCalculated
+	}
+	rates, err := s.Store.ListStatutoryRates()
+	if err != nil {
+		return ssofile.Report{}, err
+	}
+	rules := RulesFor(rates, time.Date(run.PeriodYear, time.Month(run.PeriodMonth), 1, 0, 0, 0, 0, time.UTC))
+	runs, err := s.Store.ListPayrollRuns(run.PeriodYear, run.PeriodMonth)
+	if err != nil {
+		return ssofile.Report{}, err
+	}
+
+	type monthly struct{ wage, employee, employer money.Amount }
+	sums := map[uint]*monthly{}
+	er := ssofile.CurrentEmployer()
+	report := ssofile.Report{
+		Year:         run.PeriodYear,
+		Month:        run.PeriodMonth,
+		Name:         er.Name,
+		Rate:         rules.SSORate,
+		EmployerRate: rules.SSOEmployerRate,
+	}
+	for _, r := range runs {
+		if !calculated(r) {
+			continue
+		}
+		items, err := s.Store.ListPayrollItems(r.ID)
+		if err != nil {
+			return report, err
+		}
+		report.RunIDs = append(report.RunIDs, r.ID)
+		if d := PayDate(r); d.After(report.PayDate) {
+			report.PayDate = d
+		}
+		for _, it := range items {
+			m := sums[it.EmployeeID]
+			if m == nil {
+				m = &monthly{}
+				sums[it.EmployeeID] = m
+			}
+			m.wage += it.SSOWage()
+			m.employee += it.SumCodes(models.CodeSSO)
+			m.employer += it.SumCodes(models.CodeSSOEmployer)
+		}
+	}
+
+	emps := make([]*models.Employee, 0, len(sums))
+	for id, m := range sums {
+		if m.employee == 0 && m.employer == 0 {
+			continue // ไม่อยู่ในระบบประกันสังคม หรือไม่มีค่าจ้างที่ต้องสมทบในเดือน
+		}
+		e, err := s.Store.GetEmployee(id)
+		if err != nil {
+			return report, err
+		}
+		emps = append(emps, e)
+	}
+	sort.Slice(emps, func(i, j int) bool { return emps[i].EmpCode < emps[j].EmpCode })
+	for _, e := range emps {
+		m := sums[e.ID]
+		branch := e.SSOBranch
+		if branch == "" {
+			branch = er.Branch
+		}
+		report.Add(er.Account, branch, ssofile.Contribution{
+			EmployeeID: e.ID,
+			EmpCode:    e.EmpCode,
+			NationalID: e.NationalID,
+			FirstName:  e.FirstName,
+			LastName:   e.LastName,
+			ActualWage: money.Max(m.wage, 0),
+			Wage:       ssoBase(m.wage, rules),
+			Employee:   m.employee,
+			Employer:   m.employer,
+		})
+	}
+	return report, nil
+}
+
+// calculated run ที่คำนวณแล้ว (calculated ขึ้นไป) มียอดของพนักงานครบ
+func calculated(r models.PayrollRun) bool {
+	return r.Status != "" && r.Status != models.RunDraft
+}
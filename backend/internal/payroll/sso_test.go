@@ -0,0 +1,77 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+	"backend/internal/storage"
+)
+
+// ผู้ประกันตนที่ไม่มีค่าจ้างในเดือน (ลาไม่รับค่าจ้างทั้งเดือน หรือมีแต่รายการที่ไม่ใช่ค่าจ้าง) ไม่มีเงินสมทบและไม่อยู่ในไฟล์ สปส.1-10
+func TestSSOReportSkipsEmployeesWithoutWage(t *testing.T) {
+	hired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		code   string
+		salary float64
+		unpaid bool    // ลาไม่รับค่าจ้างทั้งเดือน
+		allow  float64 // ค่าตอบแทนที่ไม่ใช่ค่าจ้าง
+		sso    float64
+	}{
+		{code: "E1", salary: 12000, sso: 600},
+		{code: "E2", salary: 30000, unpaid: true},
+		{code: "E3", allow: 3000},
+	}
+	st := storage.New()
+	s := NewService(st)
+	run := &models.PayrollRun{PeriodYear: 2026, PeriodMonth: 3, Type: models.RunRegular, Status: models.RunDraft}
+	if err := st.CreatePayrollRun(run); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]uint{}
+	for _, tc := range cases {
+		e := &models.Employee{EmpCode: tc.code, FirstName: tc.code, BaseSalary: money.FromBaht(tc.salary), SSOEnabled: true, Status: "active", HiredAt: hired}
+		if err := st.CreateEmployee(e); err != nil {
+			t.Fatal(err)
+		}
+		ids[tc.code] = e.ID
+		if tc.unpaid {
+			if err := st.CreateLeave(&models.Leave{EmployeeID: e.ID, Type: models.LeaveUnpaid, Status: models.LeaveApproved,
+				StartDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tc.allow > 0 {
+			if err := st.CreatePayComponent(&models.PayComponent{EmployeeID: e.ID, Code: "ALLOW", Name: "Allowance", Kind: models.ComponentEarning,
+				Amount: money.FromBaht(tc.allow), StartDate: hired, Taxable: true}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := s.CalculateRun(run.ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := st.ListPayrollItems(run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range cases {
+		for _, it := range items {
+			if it.EmployeeID == ids[tc.code] {
+				if got := it.SumCodes(models.CodeSSO); got != baht(tc.sso) {
+					t.Errorf("%s: SSO %s, want %.2f", tc.code, got, tc.sso)
+				}
+			}
+		}
+	}
+
+	r, err := s.SSOReport(run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Count != 1 || len(r.Branches) != 1 || len(r.Branches[0].Contributions) != 1 || r.Branches[0].Contributions[0].EmpCode != "E1" {
+		t.Fatalf("SSO report = %+v, want only E1", r)
+	}
+}
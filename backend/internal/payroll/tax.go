@@ -0,0 +1,147 @@
+package payroll
+
+import (
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// TaxBracket ขั้นบันไดภาษีเงินได้บุคคลธรรมดา
+type TaxBracket = models.TaxBracket
+
+// DefaultTaxBrackets อัตราภาษีแบบก้าวหน้า 0–35% ตามประมวลรัษฎากร มาตรา 48
+var DefaultTaxBrackets = []TaxBracket{
+	{Upper: money.FromBaht(150000), Rate: 0},
+	{Upper: money.FromBaht(300000), Rate: 0.05},
+	{Upper: money.FromBaht(500000), Rate: 0.10},
+	{Upper: money.FromBaht(750000), Rate: 0.15},
+	{Upper: money.FromBaht(1000000), Rate: 0.20},
+	{Upper: money.FromBaht(2000000), Rate: 0.25},
+	{Upper: money.FromBaht(5000000), Rate: 0.30},
+	{Upper: 0, Rate: 0.35},
+}
+
+// ค่าใช้จ่าย (50% ไม่เกิน 100,000) และค่าลดหย่อนส่วนตัวอยู่ใน Rules ตามตารางอัตราของปีนั้น
+const (
+	// PVDDeductionRate / PVDDeductionCap เงินสะสม PVD หักได้ไม่เกิน 15% ของค่าจ้างและไม่เกิน 500,000 บาท
+	PVDDeductionRate = 0.15
+	PVDDeductionCap  = money.Amount(500000_00)
+)
+
+// TaxInput ข้อมูลสำหรับคำนวณภาษีหัก ณ ที่จ่ายของงวดเดือน
+// ยอด YTD คือยอดสะสมของปีภาษีเดียวกัน "ก่อน" งวดนี้
+type TaxInput struct {
+	Month     int          `json:"month"`     // เดือนของงวด (1-12)
+	Remaining int          `json:"remaining"` // จำนวนงวดที่เหลือในปีรวมงวดนี้ (0 = งวดรายเดือน คิดจาก Month)
+	Income    money.Amount `json:"income"`    // เงินได้พึงประเมินของงวดนี้
+	SSO       money.Amount `json:"sso"`       // เงินสมทบประกันสังคมของงวดนี้
+	PVD       money.Amount `json:"pvd"`       // เงินสะสมกองทุนสำรองเลี้ยงชีพของงวดนี้
+
+	YTDIncome money.Amount `json:"ytdIncome"`
+	YTDSSO    money.Amount `json:"ytdSso"`
+	YTDPVD    money.Amount `json:"ytdPvd"`
+	YTDTax    money.Amount `json:"ytdTax"`
+}
+
+// TaxResult ผลการคำนวณภาษีแบบประมาณการทั้งปี
+type TaxResult struct {
+	Remaining     int          `json:"remaining"` // จำนวนงวดที่ใช้ประมาณการ/เฉลี่ย
+	AnnualIncome  money.Amount `json:"annualIncome"`
+	Expense       money.Amount `json:"expense"`
+	AnnualSSO     money.Amount `json:"annualSso"`
+	PVDAllowance  money.Amount `json:"pvdAllowance"`
+	Allowances    money.Amount `json:"allowances"`
+	NetIncome     money.Amount `json:"netIncome"`
+	AnnualTax     money.Amount `json:"annualTax"`
+	BaseAnnualTax money.Amount `json:"baseAnnualTax,omitempty"` // ภาษีทั้งปีไม่รวมเงินได้ครั้งเดียว (OneOffTax)
+	Withholding   money.Amount `json:"withholding"`
+}
+
+// WithholdingTax คำนวณภาษีหัก ณ ที่จ่ายของงวดตามวิธีของกรมสรรพากร:
+// ประมาณการเงินได้ทั้งปี (ยอดสะสม + งวดนี้ x จำนวนงวดที่เหลือ) → หักค่าใช้จ่าย
+// → หักค่าลดหย่อน (ส่วนตัว, SSO, PVD) → คิดภาษีขั้นบันได แล้วเฉลี่ยภาษีที่ยังไม่ได้หัก
+// ไปตามจำนวนงวดที่เหลือของปี (งวดรายเดือน = จำนวนเดือนที่เหลือ)
+func WithholdingTax(in TaxInput, rules Rules) TaxResult {
+	remaining := int64(in.Remaining)
+	if remaining <= 0 {
+		month := in.Month
+		if month < 1 {
+			month = 1
+		}
+		if month > 12 {
+			month = 12
+		}
+		remaining = int64(12 - month + 1)
+	}
+
+	res := annualTax(in, remaining, 0, 0, rules)
+	res.Withholding = money.Max((res.AnnualTax - in.YTDTax).Div(remaining), 0)
+	return res
+}
+
+// OneOffTax ภาษีหัก ณ ที่จ่ายของเงินได้ที่จ่ายครั้งเดียว (โบนัส, เงินได้นอกรอบ) ตามวิธีของกรมสรรพากร:
+// ภาษีจากเงินได้ทั้งปี "รวม" เงินได้ครั้งเดียว ลบด้วยภาษีจากเงินได้ปกติทั้งปี แล้วหักทั้งจำนวนในครั้งเดียว
+// regular คือเงินได้ปกติต่องวด (Income/SSO/PVD) พร้อมยอดสะสม, remaining คือจำนวนงวด
+// ที่ยังจะจ่ายเงินได้ปกติ (ไม่นับงวดที่อยู่ในยอดสะสมแล้ว)
+func OneOffTax(regular TaxInput, remaining int, income, sso money.Amount, rules Rules) TaxResult {
+	if remaining < 0 {
+		remaining = 0
+	}
+	base := annualTax(regular, int64(remaining), 0, 0, rules)
+	res := annualTax(regular, int64(remaining), income, sso, rules)
+	res.BaseAnnualTax = base.AnnualTax
+	res.Withholding = money.Max(res.AnnualTax-base.AnnualTax, 0)
+	return res
+}
+
+// annualTax ประมาณการเงินได้ทั้งปี (ยอดสะสม + ต่องวด x remaining + เงินได้ครั้งเดียว) แล้วคิดภาษีทั้งปี
+func annualTax(in TaxInput, remaining int64, extraIncome, extraSSO money.Amount, rules Rules) TaxResult {
+	annualIncome := in.YTDIncome + in.Income.Mul(remaining) + extraIncome
+	annualSSO := in.YTDSSO + in.SSO.Mul(remaining) + extraSSO
+	annualPVD := in.YTDPVD + in.PVD.Mul(remaining)
+
+	expense := money.Min(annualIncome.MulRate(rules.ExpenseRate), rules.ExpenseCap)
+	pvd := money.Min(annualPVD, money.Min(annualIncome.MulRate(PVDDeductionRate), PVDDeductionCap))
+	allowances := rules.PersonalAllowance + annualSSO + pvd
+
+	net := money.Max(annualIncome-expense-allowances, 0)
+	return TaxResult{
+		Remaining:    int(remaining),
+		AnnualIncome: annualIncome,
+		Expense:      expense,
+		AnnualSSO:    annualSSO,
+		PVDAllowance: pvd,
+		Allowances:   allowances,
+		NetIncome:    net,
+		AnnualTax:    ProgressiveTax(net, rules.TaxBrackets),
+	}
+}
+
+// ProgressiveTax คิดภาษีทั้งปีจากเงินได้สุทธิตามขั้นบันได
+func ProgressiveTax(netIncome money.Amount, brackets []TaxBracket) money.Amount {
+	var tax money.Amount
+	for _, st := range BracketSteps(netIncome, brackets) {
+		tax += st.Tax
+	}
+	return tax
+}
+
+// BracketSteps แจกแจงภาษีทีละขั้นบันไดเฉพาะขั้นที่มีเงินได้สุทธิตกอยู่
+func BracketSteps(netIncome money.Amount, brackets []TaxBracket) []BracketStep {
+	var steps []BracketStep
+	var lower money.Amount
+	for _, b := range brackets {
+		if netIncome <= lower {
+			break
+		}
+		upper := b.Upper
+		if upper == 0 || netIncome < upper {
+			upper = netIncome
+		}
+		steps = append(steps, BracketStep{Lower: lower, Upper: b.Upper, Rate: b.Rate, Base: upper - lower, Tax: (upper - lower).MulRate(b.Rate)})
+		lower = b.Upper
+		if b.Upper == 0 {
+			break
+		}
+	}
+	return steps
+}
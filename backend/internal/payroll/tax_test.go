@@ -0,0 +1,142 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/money"
+)
+
+func baht(b float64) money.Amount { return money.FromBaht(b) }
+
+// ภาษีขั้นบันไดตามมาตรา 48 ที่ขอบของแต่ละขั้น
+func TestProgressiveTaxBracketBoundaries(t *testing.T) {
+	cases := []struct {
+		net, tax float64
+	}{
+		{0, 0},
+		{150000, 0},
+		{150001, 0.05},
+		{300000, 7500},
+		{500000, 27500},
+		{750000, 65000},
+		{1000000, 115000},
+		{2000000, 365000},
+		{5000000, 1265000},
+		{5000001, 1265000.35},
+	}
+	for _, tc := range cases {
+		if got := ProgressiveTax(baht(tc.net), DefaultTaxBrackets); got != baht(tc.tax) {
+			t.Errorf("net %.2f: tax %s, want %.2f", tc.net, got, tc.tax)
+		}
+	}
+}
+
+// ตัวอย่างการคำนวณภาษีหัก ณ ที่จ่ายตามวิธีของกรมสรรพากร (ค่าใช้จ่าย 50% ไม่เกิน 100,000, ลดหย่อนส่วนตัว 60,000)
+func TestWithholdingTax(t *testing.T) {
+	rules := RulesFor(nil, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	cases := []struct {
+		name        string
+		in          TaxInput
+		net, annual float64
+		withholding float64
+	}{
+		{
+			// 20,000 x 12 = 240,000 - 100,000 - (60,000 + 9,000) = 71,000 ยังไม่ถึงขั้นแรก
+			name: "below the taxable threshold",
+			in:   TaxInput{Month: 1, Income: baht(20000), SSO: baht(750)},
+			net:  71000, annual: 0, withholding: 0,
+		},
+		{
+			// 50,000 x 12 = 600,000 - 100,000 - 69,000 = 431,000 → 7,500 + 13,100 = 20,600 / 12
+			name: "monthly salary from January",
+			in:   TaxInput{Month: 1, Income: baht(50000), SSO: baht(750)},
+			net:  431000, annual: 20600, withholding: 1716.67,
+		},
+		{
+			// ขึ้นเงินเดือนเดือน ก.ค.: สะสม 6 x 40,000 + 50,000 x 6 = 540,000 → net 371,000 → 14,600
+			// หักไปแล้ว 4,300.02 ที่เหลือเฉลี่ย 6 เดือน
+			name: "annualized from mid-year YTD",
+			in: TaxInput{Month: 7, Income: baht(50000), SSO: baht(750),
+				YTDIncome: baht(240000), YTDSSO: baht(4500), YTDTax: baht(4300.02)},
+			net: 371000, annual: 14600, withholding: 1716.66,
+		},
+		{
+			// งวดรายปักษ์: เหลือ 10 งวดรวมงวดนี้ สะสม 320,000 + 20,000 x 10 = 520,000
+			// SSO 6,000 + 375 x 10 = 9,750 → net 350,250 → 7,500 + 5,025 = 12,525; (12,525 − 7,000) / 10
+			name: "explicit remaining periods",
+			in: TaxInput{Month: 8, Remaining: 10, Income: baht(20000), SSO: baht(375),
+				YTDIncome: baht(320000), YTDSSO: baht(6000), YTDTax: baht(7000)},
+			net: 350250, annual: 12525, withholding: 552.50,
+		},
+		{
+			// PVD 20% ของค่าจ้างหักได้ไม่เกิน 15% ของเงินได้ (90,000)
+			name: "PVD allowance capped at 15% of income",
+			in:   TaxInput{Month: 1, Income: baht(50000), SSO: baht(750), PVD: baht(10000)},
+			net:  341000, annual: 11600, withholding: 966.67,
+		},
+		{
+			// ภาษีที่หักไปแล้ว (30,000) เกินภาษีทั้งปี (560,000 → net 391,250 → 16,625) ไม่หักติดลบ
+			name: "over-withheld YTD",
+			in: TaxInput{Month: 12, Income: baht(10000), SSO: baht(500),
+				YTDIncome: baht(550000), YTDSSO: baht(8250), YTDTax: baht(30000)},
+			net: 391250, annual: 16625, withholding: 0,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := WithholdingTax(tc.in, rules)
+			if res.NetIncome != baht(tc.net) || res.AnnualTax != baht(tc.annual) || res.Withholding != baht(tc.withholding) {
+				t.Fatalf("net/annual/withholding = %s/%s/%s, want %.2f/%.2f/%.2f",
+					res.NetIncome, res.AnnualTax, res.Withholding, tc.net, tc.annual, tc.withholding)
+			}
+		})
+	}
+}
+
+// เงินได้ครั้งเดียว: ภาษีทั้งปีรวมโบนัส ลบภาษีทั้งปีจากเงินได้ปกติ หักทั้งจำนวนในงวดที่จ่าย
+func TestOneOffTax(t *testing.T) {
+	rules := RulesFor(nil, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	cases := []struct {
+		name      string
+		regular   TaxInput
+		remaining int
+		bonus     float64
+		base      float64 // ภาษีทั้งปีจากเงินได้ปกติ
+		annual    float64 // ภาษีทั้งปีรวมเงินได้ครั้งเดียว
+		tax       float64
+	}{
+		{
+			// ปกติ 431,000 → 20,600; รวมโบนัส 100,000 → 531,000 → 27,500 + 4,650 = 32,150
+			name:    "bonus in January",
+			regular: TaxInput{Income: baht(50000), SSO: baht(750)}, remaining: 12, bonus: 100000,
+			base: 20600, annual: 32150, tax: 11550,
+		},
+		{
+			// จ่ายเงินได้ปกติครบทั้งปีแล้ว (ทุกอย่างอยู่ในยอดสะสม)
+			name:    "bonus after the last regular period",
+			regular: TaxInput{YTDIncome: baht(600000), YTDSSO: baht(9000), YTDTax: baht(20600)}, remaining: 0, bonus: 100000,
+			base: 20600, annual: 32150, tax: 11550,
+		},
+		{
+			// เงินได้รวมโบนัสยังไม่ถึงขั้นที่ต้องเสียภาษี
+			name:    "bonus below the threshold",
+			regular: TaxInput{Income: baht(15000), SSO: baht(750)}, remaining: 12, bonus: 20000,
+			base: 0, annual: 0, tax: 0,
+		},
+		{
+			// โบนัสข้ามขั้น: ปกติ 431,000 → รวม 300,000 = 731,000 → 27,500 + 34,650 = 62,150
+			name:    "bonus spanning brackets",
+			regular: TaxInput{Income: baht(50000), SSO: baht(750)}, remaining: 12, bonus: 300000,
+			base: 20600, annual: 62150, tax: 41550,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := OneOffTax(tc.regular, tc.remaining, baht(tc.bonus), 0, rules)
+			if res.BaseAnnualTax != baht(tc.base) || res.AnnualTax != baht(tc.annual) || res.Withholding != baht(tc.tax) {
+				t.Fatalf("base/annual/withholding = %s/%s/%s, want %.2f/%.2f/%.2f", res.BaseAnnualTax, res.AnnualTax, res.Withholding, tc.base, tc.annual, tc.tax)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package payroll
+
+import (
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// RunTotals ยอดรวมของ run = ผลรวมของ items ทุกรายการ (คิดเป็นสตางค์จึงตรงกันเสมอ)
+type RunTotals struct {
+	Employees       int          `json:"employees"`
+	Gross           money.Amount `json:"gross"`
+	TaxWithheld     money.Amount `json:"taxWithheld"`
+	SSO             money.Amount `json:"sso"`
+	PVD             money.Amount `json:"pvd"`
+	OtherDeductions money.Amount `json:"otherDeductions"`
+	NetPay          money.Amount `json:"netPay"`
+	EmployerCost    money.Amount `json:"employerCost"`
+}
+
+// Totals รวมยอดของ items
+func Totals(items []models.PayrollItem) RunTotals {
+	var t RunTotals
+	for _, it := range items {
+		t.Employees++
+		t.Gross += it.GrossPay()
+		t.TaxWithheld += it.TaxWithheld
+		t.SSO += it.SSO
+		t.PVD += it.PVD
+		t.OtherDeductions += it.OtherDeductions()
+		t.NetPay += it.NetPay
+		t.EmployerCost += it.Sum(models.LineEmployer)
+	}
+	return t
+}
@@ -0,0 +1,166 @@
+package payroll
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// Trace คำอธิบายขั้นตอนการคำนวณ payroll item หนึ่งรายการ (เก็บคู่กับ item ตอนคำนวณ)
+// ใช้ตอบคำถามพนักงานว่ายอดในสลิปมาจากไหน โดยไม่ต้องคำนวณใหม่
+type Trace struct {
+	Rules        RuleVersion      `json:"rules"`
+	Period       Period           `json:"period"`
+	Days         DaysTrace        `json:"days"`
+	Salary       []SalaryTrace    `json:"salary,omitempty"`
+	UnpaidLeave  []LeaveTrace     `json:"unpaidLeave,omitempty"`
+	Retro        []RetroPay       `json:"retro,omitempty"`
+	Components   []ComponentTrace `json:"components,omitempty"`
+	Garnishments []GarnishmentDue `json:"garnishments,omitempty"`
+	Loans        []LoanDue        `json:"loans,omitempty"`
+
+	Gross         money.Amount `json:"gross"`
+	TaxableIncome money.Amount `json:"taxableIncome"`
+
+	SSO      SSOTrace  `json:"sso"`
+	PVD      PVDTrace  `json:"pvd"`
+	Tax      TaxTrace  `json:"tax"`
+	RetroTax *TaxTrace `json:"retroTax,omitempty"`
+
+	// หักภาษีเพิ่มตามที่พนักงานขอ: taxableIncome x withholdingRate
+	WithholdingRate float64      `json:"withholdingRate,omitempty"`
+	ExtraTax        money.Amount `json:"extraTax,omitempty"`
+}
+
+// RuleVersion ตารางอัตราตามกฎหมายที่ใช้ (RateID = 0 คือตารางที่ติดมากับระบบ)
+type RuleVersion struct {
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	RateID        uint      `json:"rateId,omitempty"`
+	Note          string    `json:"note,omitempty"`
+}
+
+// DaysTrace วันทำงานที่ซ้อนกับงวด (overlapDays)
+type DaysTrace struct {
+	HiredAt      time.Time  `json:"hiredAt"`
+	TerminatedAt *time.Time `json:"terminatedAt,omitempty"`
+	Worked       int        `json:"worked"`
+	Total        int        `json:"total"`
+}
+
+// SalaryTrace เงินเดือนตามสัดส่วนของช่วงที่ใช้อัตราเดียวกัน: periodAmount x days / total
+type SalaryTrace struct {
+	From         time.Time    `json:"from"`
+	To           time.Time    `json:"to"`
+	Monthly      money.Amount `json:"monthly"`
+	PeriodAmount money.Amount `json:"periodAmount"`
+	Days         int          `json:"days"`
+	Amount       money.Amount `json:"amount"`
+}
+
+// LeaveTrace หักลาไม่รับค่าจ้างของช่วง: dailyBase x days / divisor (ไม่เกินเงินเดือนของช่วง)
+type LeaveTrace struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Basis     DailyRateBasis `json:"basis"`
+	DailyBase money.Amount   `json:"dailyBase"`
+	Days      int            `json:"days"`
+	Divisor   int            `json:"divisor"`
+	Amount    money.Amount   `json:"amount"`
+}
+
+// ComponentTrace รายการประจำ: เงินได้ = periodAmount x activeDays / total, เงินหักเต็มจำนวน
+type ComponentTrace struct {
+	Code         string       `json:"code"`
+	Kind         string       `json:"kind"`
+	Monthly      money.Amount `json:"monthly"`
+	PeriodAmount money.Amount `json:"periodAmount"`
+	ActiveDays   int          `json:"activeDays,omitempty"`
+	Amount       money.Amount `json:"amount"`
+}
+
+// SSOTrace ค่าจ้างรวมของเดือน → บีบฐาน (ขั้นต่ำ/เพดาน) → x อัตรา → หักส่วนที่ run ก่อนหน้าในเดือนหักไปแล้ว
+type SSOTrace struct {
+	Enabled       bool         `json:"enabled"`
+	Wage          money.Amount `json:"wage"`      // ค่าจ้าง SSOable ของ run นี้
+	PriorWage     money.Amount `json:"priorWage"` // ค่าจ้างของ run ก่อนหน้าในเดือนเดียวกัน
+	MinBase       money.Amount `json:"minBase"`
+	MaxBase       money.Amount `json:"maxBase"`
+	Base          money.Amount `json:"base"`
+	Capped        bool         `json:"capped"`
+	Rate          float64      `json:"rate"`
+	PriorDeducted money.Amount `json:"priorDeducted"`
+	Employee      money.Amount `json:"employee"`
+	EmployerRate  float64      `json:"employerRate"`
+	PriorEmployer money.Amount `json:"priorEmployer"`
+	Employer      money.Amount `json:"employer"`
+}
+
+// PVDTrace เงินสะสม PVD = ค่าจ้างที่จ่ายจริง x อัตราของพนักงาน
+type PVDTrace struct {
+	Base   money.Amount `json:"base"`
+	Rate   float64      `json:"rate"`
+	Amount money.Amount `json:"amount"`
+}
+
+// ภาษีคิดแบบใด
+const (
+	TaxMethodAnnualized = "annualized" // ประมาณการทั้งปีแล้วเฉลี่ยตามงวดที่เหลือ
+	TaxMethodOneOff     = "one_off"    // เงินได้ครั้งเดียว: ภาษีทั้งปีรวมเงินได้นี้ − ภาษีทั้งปีไม่รวม
+)
+
+// TaxTrace ขั้นตอนประมาณการภาษีทั้งปี
+type TaxTrace struct {
+	Method    string       `json:"method"`
+	Input     TaxInput     `json:"input"`
+	Remaining int          `json:"remaining"`
+	OneOff    money.Amount `json:"oneOff,omitempty"` // เงินได้ครั้งเดียว (method one_off)
+
+	AnnualIncome      money.Amount  `json:"annualIncome"`
+	ExpenseRate       float64       `json:"expenseRate"`
+	ExpenseCap        money.Amount  `json:"expenseCap"`
+	Expense           money.Amount  `json:"expense"`
+	PersonalAllowance money.Amount  `json:"personalAllowance"`
+	AnnualSSO         money.Amount  `json:"annualSso"`
+	PVDAllowance      money.Amount  `json:"pvdAllowance"`
+	Allowances        money.Amount  `json:"allowances"`
+	NetIncome         money.Amount  `json:"netIncome"`
+	Brackets          []BracketStep `json:"brackets"`
+	AnnualTax         money.Amount  `json:"annualTax"`
+	BaseAnnualTax     money.Amount  `json:"baseAnnualTax,omitempty"` // ภาษีทั้งปีไม่รวมเงินได้ครั้งเดียว
+	Withholding       money.Amount  `json:"withholding"`
+}
+
+// BracketStep ภาษีของขั้นบันไดหนึ่งขั้น
+type BracketStep struct {
+	Lower money.Amount `json:"lower"`
+	Upper money.Amount `json:"upper"` // 0 = ไม่มีเพดาน
+	Rate  float64      `json:"rate"`
+	Base  money.Amount `json:"base"` // เงินได้สุทธิที่ตกในขั้นนี้
+	Tax   money.Amount `json:"tax"`
+}
+
+func ruleVersion(r Rules) RuleVersion {
+	return RuleVersion{EffectiveFrom: r.EffectiveFrom, RateID: r.RateID, Note: r.Note}
+}
+
+// taxTrace สรุปผล TaxResult เป็นขั้นตอน (ขั้นบันไดคิดซ้ำจากเงินได้สุทธิด้วยสูตรเดียวกับ ProgressiveTax)
+func taxTrace(method string, in TaxInput, res TaxResult, rules Rules) TaxTrace {
+	return TaxTrace{
+		Method:            method,
+		Input:             in,
+		Remaining:         res.Remaining,
+		AnnualIncome:      res.AnnualIncome,
+		ExpenseRate:       rules.ExpenseRate,
+		ExpenseCap:        rules.ExpenseCap,
+		Expense:           res.Expense,
+		PersonalAllowance: rules.PersonalAllowance,
+		AnnualSSO:         res.AnnualSSO,
+		PVDAllowance:      res.PVDAllowance,
+		Allowances:        res.Allowances,
+		NetIncome:         res.NetIncome,
+		Brackets:          BracketSteps(res.NetIncome, rules.TaxBrackets),
+		AnnualTax:         res.AnnualTax,
+		BaseAnnualTax:     res.BaseAnnualTax,
+		Withholding:       res.Withholding,
+	}
+}
@@ -0,0 +1,100 @@
+package payroll
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// ขั้นบันไดที่แจกแจงใน trace รวมกันได้เท่ากับ ProgressiveTax
+func TestBracketSteps(t *testing.T) {
+	cases := []struct {
+		net   float64
+		bases []float64 // เงินได้สุทธิที่ตกในแต่ละขั้น
+		taxes []float64
+	}{
+		{net: 0},
+		{net: 150000, bases: []float64{150000}, taxes: []float64{0}},
+		{net: 431000, bases: []float64{150000, 150000, 131000}, taxes: []float64{0, 7500, 13100}},
+		{net: 5000001, bases: []float64{150000, 150000, 200000, 250000, 250000, 1000000, 3000000, 1}, taxes: []float64{0, 7500, 20000, 37500, 50000, 250000, 900000, 0.35}},
+	}
+	for _, tc := range cases {
+		steps := BracketSteps(baht(tc.net), DefaultTaxBrackets)
+		if len(steps) != len(tc.bases) {
+			t.Fatalf("net %.2f: %d steps, want %d", tc.net, len(steps), len(tc.bases))
+		}
+		var sum money.Amount
+		for i, st := range steps {
+			if st.Base != baht(tc.bases[i]) || st.Tax != baht(tc.taxes[i]) {
+				t.Errorf("net %.2f step %d: base/tax = %s/%s, want %.2f/%.2f", tc.net, i, st.Base, st.Tax, tc.bases[i], tc.taxes[i])
+			}
+			sum += st.Tax
+		}
+		if want := ProgressiveTax(baht(tc.net), DefaultTaxBrackets); sum != want {
+			t.Errorf("net %.2f: steps sum to %s, ProgressiveTax %s", tc.net, sum, want)
+		}
+	}
+}
+
+// trace ของ item อธิบายวันทำงาน ฐาน SSO/เพดาน และขั้นตอนภาษีได้ตรงกับยอดในผลลัพธ์ และเก็บลง item เป็น JSON
+func TestCalculateTrace(t *testing.T) {
+	cases := []struct {
+		name          string
+		salary        float64
+		hired         time.Time
+		worked, total int
+		ssoBase       float64
+		capped        bool
+	}{
+		{name: "full month over the cap", salary: 50000, hired: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), worked: 31, total: 31, ssoBase: 17500, capped: true},
+		// 31,000 x 16/31 = 16,000 ต่ำกว่าเพดาน
+		{name: "hired mid-month", salary: 31000, hired: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), worked: 16, total: 31, ssoBase: 16000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			period, err := MonthPeriod(2026, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, ok := Calculate(Input{
+				Employee: models.Employee{ID: 1, BaseSalary: money.FromBaht(tc.salary), SSOEnabled: true, HiredAt: tc.hired},
+				Period:   period,
+				Rules:    RulesFor(nil, period.End),
+			})
+			if !ok {
+				t.Fatal("Calculate returned ok = false")
+			}
+			tr := res.Trace
+			if tr.Days.Worked != tc.worked || tr.Days.Total != tc.total {
+				t.Fatalf("days = %d/%d, want %d/%d", tr.Days.Worked, tr.Days.Total, tc.worked, tc.total)
+			}
+			if !tr.Rules.EffectiveFrom.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("rules version = %s, want 2026-01-01", tr.Rules.EffectiveFrom)
+			}
+			if tr.SSO.Base != baht(tc.ssoBase) || tr.SSO.Capped != tc.capped || tr.SSO.Employee != res.SSO {
+				t.Fatalf("sso trace = %+v, want base %.2f capped %v employee %s", tr.SSO, tc.ssoBase, tc.capped, res.SSO)
+			}
+			if tr.Tax.Method != TaxMethodAnnualized || tr.Tax.Remaining != 10 || tr.Tax.Withholding != res.Tax-res.ExtraTax {
+				t.Fatalf("tax trace = %s remaining %d withholding %s, want annualized/10/%s", tr.Tax.Method, tr.Tax.Remaining, tr.Tax.Withholding, res.Tax-res.ExtraTax)
+			}
+			var sum money.Amount
+			for _, st := range tr.Tax.Brackets {
+				sum += st.Tax
+			}
+			if sum != tr.Tax.AnnualTax {
+				t.Fatalf("bracket taxes sum to %s, annual tax %s", sum, tr.Tax.AnnualTax)
+			}
+
+			var stored Trace
+			if err := json.Unmarshal(res.Item(1).Trace, &stored); err != nil {
+				t.Fatal(err)
+			}
+			if stored.SSO != tr.SSO || stored.Tax.AnnualTax != tr.Tax.AnnualTax {
+				t.Fatalf("stored trace differs: %+v", stored)
+			}
+		})
+	}
+}
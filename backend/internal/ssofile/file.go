@@ -0,0 +1,72 @@
+package ssofile
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"backend/internal/fixedwidth"
+	"backend/internal/money"
+)
+
+// Write เขียนไฟล์ข้อความ สปส.1-10 สำหรับอัปโหลดผ่าน e-Service (TIS-620, CRLF) ความยาว 135 ไบต์ต่อ record
+// หนึ่ง header ต่อบัญชีนายจ้าง/สาขา ตามด้วยรายการผู้ประกันตนของสาขานั้น; วันที่เป็นปี พ.ศ. 2 หลัก ยอดเงินเป็นสตางค์
+//
+//	1: 1 type | 10 employer account | 6 branch | 6 pay date DDMMYY | 4 period MMYY | 45 employer name |
+//	   4 rate (0500 = 5%) | 6 count | 15 total wage | 14 total contribution | 12 employee total | 12 employer total
+//	2: 1 type | 13 national ID | 3 title code | 30 first name | 35 last name | 14 wage | 12 contribution | filler
+func Write(w io.Writer, r Report) error {
+	if err := validate(r); err != nil {
+		return err
+	}
+	var f fixedwidth.File
+	for _, b := range r.Branches {
+		f.Add(fixedwidth.New(recordLength).Alpha("1", 1).Alpha(b.Account, 10).Alpha(b.Branch, 6).
+			Num(buddhistDate(r.PayDate), 6).Num(int64(r.Month*100+buddhistYear(r.Year)%100), 4).Alpha(r.Name, 45).
+			Num(int64(math.Round(r.Rate*10000)), 4).Num(int64(b.Count), countWidth).
+			Amount(b.Wage, wageTotalWidth).Amount(b.Total, contributionTotalWidth).
+			Amount(b.Employee, sideTotalWidth).Amount(b.Employer, sideTotalWidth))
+		for _, c := range b.Contributions {
+			// ระบบยังไม่เก็บคำนำหน้าชื่อ เว้นรหัสคำนำหน้าว่างไว้
+			f.Add(fixedwidth.New(recordLength).Alpha("2", 1).Alpha(c.NationalID, 13).Alpha("", 3).
+				Alpha(c.FirstName, 30).Alpha(c.LastName, 35).
+				Amount(c.Wage, wageWidth).Amount(c.Employee, contributionWidth).Filler())
+		}
+	}
+	return f.Write(w)
+}
+
+const (
+	recordLength           = 135
+	countWidth             = 6
+	wageTotalWidth         = 15
+	contributionTotalWidth = 14
+	sideTotalWidth         = 12
+	wageWidth              = 14
+	contributionWidth      = 12
+)
+
+// ยอดสูงสุดที่เขียนได้ในแต่ละ field
+var (
+	maxCount             = pow10(countWidth) - 1
+	maxWageTotal         = money.Satang(int64(pow10(wageTotalWidth) - 1))
+	maxContributionTotal = money.Satang(int64(pow10(contributionTotalWidth) - 1))
+	maxSideTotal         = money.Satang(int64(pow10(sideTotalWidth) - 1))
+	maxWage              = money.Satang(int64(pow10(wageWidth) - 1))
+	maxContribution      = money.Satang(int64(pow10(contributionWidth) - 1))
+)
+
+func pow10(n int) int {
+	p := 1
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+func buddhistYear(y int) int { return y + 543 }
+
+// buddhistDate วันที่แบบ DDMMYY ปี พ.ศ.
+func buddhistDate(t time.Time) int64 {
+	return int64(t.Day()*10000 + int(t.Month())*100 + buddhistYear(t.Year())%100)
+}
@@ -0,0 +1,105 @@
+package ssofile
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/money"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+// sampleReport เดือน ม.ค. 2026 สองสาขา: เพดานค่าจ้าง 17,500 ฐานขั้นต่ำ 1,650 และผู้มีค่าจ้างต่ำกว่าเพดาน
+func sampleReport() Report {
+	r := Report{
+		Year: 2026, Month: 1, PayDate: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC),
+		Name: "บริษัท ตัวอย่าง จำกัด", Rate: 0.05, EmployerRate: 0.05,
+	}
+	add := func(branch, code, id, first, last string, actual, wage float64) {
+		c := Contribution{EmpCode: code, NationalID: id, FirstName: first, LastName: last,
+			ActualWage: money.FromBaht(actual), Wage: money.FromBaht(wage)}
+		c.Employee = c.Wage.MulRate(r.Rate)
+		c.Employer = c.Wage.MulRate(r.EmployerRate)
+		r.Add("1234567890", branch, c)
+	}
+	add("000001", "E003", "1509900345677", "Somsak", "Deejai", 30000, 17500)
+	add(DefaultBranch, "E001", "1101700123456", "สมชาย", "ใจดี", 50000, 17500)
+	add(DefaultBranch, "E002", "3100600234561", "สมหญิง", "รักงาน", 12345.67, 12345.67)
+	add(DefaultBranch, "E004", "1200800456781", "Part", "Timer", 1000, 1650)
+	return r
+}
+
+func TestWriteGolden(t *testing.T) {
+	r := sampleReport()
+	var buf bytes.Buffer
+	if err := Write(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join("testdata", "sso110.golden")
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("file differs from %s:\n got: %q\nwant: %q", path, buf.Bytes(), want)
+	}
+
+	recs := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	types := "122212"
+	if len(recs) != len(types) {
+		t.Fatalf("%d records, want %d", len(recs), len(types))
+	}
+	for i, rec := range recs {
+		if len(rec) != recordLength || rec[0] != types[i] {
+			t.Errorf("record %d: type %q, %d bytes; want type %q, %d bytes", i+1, rec[0], len(rec), types[i], recordLength)
+		}
+	}
+	// header สำนักงานใหญ่: วันจ่าย 05/02/69, งวด 01/69, อัตรา 0500, 3 คน, ค่าจ้าง 31,495.67, เงินสมทบ 1,574.78 x 2
+	hq := recs[0]
+	for _, f := range []struct {
+		name      string
+		from, len int
+		want      string
+	}{
+		{"account", 1, 10, "1234567890"},
+		{"branch", 11, 6, "000000"},
+		{"pay date", 17, 6, "050269"},
+		{"period", 23, 4, "0169"},
+		{"rate", 72, 4, "0500"},
+		{"count", 76, 6, "000003"},
+		{"wage", 82, 15, "000000003149567"},
+		{"total", 97, 14, "00000000314956"},
+		{"employee", 111, 12, "000000157478"},
+		{"employer", 123, 12, "000000157478"},
+	} {
+		if got := hq[f.from : f.from+f.len]; got != f.want {
+			t.Errorf("header %s = %q, want %q", f.name, got, f.want)
+		}
+	}
+}
+
+func TestWriteRejectsInvalidReport(t *testing.T) {
+	r := sampleReport()
+	r.Branches[0].Contributions[0].NationalID = "1101700123457"
+	r.Branches[1].Account = "12345"
+	err := Write(&bytes.Buffer{}, r)
+	var re *ReportError
+	if !errors.As(err, &re) || len(re.Problems) != 2 {
+		t.Fatalf("err = %v, want 2 problems", err)
+	}
+	if re.Problems[0].Field != "nationalId" || re.Problems[1].Field != "employer" {
+		t.Fatalf("problems = %+v", re.Problems)
+	}
+}
@@ -0,0 +1,186 @@
+// Package ssofile สร้างไฟล์นำส่งเงินสมทบกองทุนประกันสังคมรายเดือน (สปส.1-10) สำหรับอัปโหลดผ่านระบบ e-Service
+// ของสำนักงานประกันสังคม และใบสรุปสำหรับพิมพ์ แยกตามบัญชีนายจ้างและลำดับที่สาขา
+package ssofile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/money"
+)
+
+// DefaultBranch ลำดับที่สาขาของสำนักงานใหญ่
+const DefaultBranch = "000000"
+
+var ErrInvalidEmployer = errors.New("invalid SSO employer registration")
+
+// Employer ทะเบียนนายจ้างกับสำนักงานประกันสังคม (ตั้งจาก config ตอนเริ่มระบบ)
+type Employer struct {
+	Account string // เลขที่บัญชีนายจ้าง 10 หลัก
+	Branch  string // ลำดับที่สาขาตั้งต้น 6 หลัก ใช้กับพนักงานที่ไม่ได้ระบุสาขา
+	Name    string // ชื่อสถานประกอบการ
+}
+
+var employer = Employer{Branch: DefaultBranch}
+
+// SetEmployer ตั้งทะเบียนนายจ้างที่ใช้ทั้งระบบ (สาขาว่าง = สำนักงานใหญ่)
+func SetEmployer(e Employer) {
+	if e.Branch == "" {
+		e.Branch = DefaultBranch
+	}
+	employer = e
+}
+
+// CurrentEmployer ทะเบียนนายจ้างที่ตั้งไว้
+func CurrentEmployer() Employer { return employer }
+
+// Validate ตรวจรูปแบบเลขที่บัญชีนายจ้างและลำดับที่สาขา (ไม่ตั้งบัญชี = ยังสร้างไฟล์ สปส.1-10 ไม่ได้)
+func (e Employer) Validate() error {
+	if e.Account != "" && !numeric(e.Account, 10) {
+		return fmt.Errorf("%w: employer account must be 10 digits", ErrInvalidEmployer)
+	}
+	if !ValidBranch(e.Branch) {
+		return fmt.Errorf("%w: branch must be 6 digits", ErrInvalidEmployer)
+	}
+	return nil
+}
+
+// ValidBranch ลำดับที่สาขาเป็นตัวเลข 6 หลัก
+func ValidBranch(b string) bool { return numeric(b, 6) }
+
+// Contribution เงินสมทบของผู้ประกันตนหนึ่งคนในเดือน (รวมทุก run ของเดือน)
+type Contribution struct {
+	EmployeeID uint         `json:"employeeId"`
+	EmpCode    string       `json:"empCode"`
+	NationalID string       `json:"nationalId"`
+	FirstName  string       `json:"firstName"`
+	LastName   string       `json:"lastName"`
+	ActualWage money.Amount `json:"actualWage"` // ค่าจ้างที่จ่ายจริงในเดือน
+	Wage       money.Amount `json:"wage"`       // ค่าจ้างที่ใช้คำนวณเงินสมทบ (ปรับตามฐานขั้นต่ำ/เพดาน)
+	Employee   money.Amount `json:"employee"`   // เงินสมทบส่วนผู้ประกันตน (ที่หักจากค่าจ้าง)
+	Employer   money.Amount `json:"employer"`   // เงินสมทบส่วนนายจ้าง
+}
+
+// Branch เงินสมทบของบัญชีนายจ้าง/สาขาหนึ่ง เป็นหนึ่ง header ในไฟล์พร้อมยอดรวมควบคุม
+type Branch struct {
+	Account       string         `json:"account"`
+	Branch        string         `json:"branch"`
+	Count         int            `json:"count"`
+	Wage          money.Amount   `json:"wage"`
+	Employee      money.Amount   `json:"employee"`
+	Employer      money.Amount   `json:"employer"`
+	Total         money.Amount   `json:"total"` // เงินสมทบรวมทั้งสองฝ่ายที่ต้องนำส่ง
+	Contributions []Contribution `json:"contributions"`
+}
+
+func (b *Branch) add(c Contribution) {
+	b.Contributions = append(b.Contributions, c)
+	b.Count++
+	b.Wage += c.Wage
+	b.Employee += c.Employee
+	b.Employer += c.Employer
+	b.Total += c.Employee + c.Employer
+}
+
+// Report รายการนำส่งเงินสมทบของเดือนหนึ่ง
+type Report struct {
+	Year         int          `json:"year"` // ปี ค.ศ. ของเดือนที่จ่ายค่าจ้าง
+	Month        int          `json:"month"`
+	PayDate      time.Time    `json:"payDate"`
+	Name         string       `json:"name"`
+	Rate         float64      `json:"rate"`         // อัตราเงินสมทบฝั่งลูกจ้าง
+	EmployerRate float64      `json:"employerRate"` // อัตราเงินสมทบฝั่งนายจ้าง
+	RunIDs       []uint       `json:"runIds"`       // run ของเดือนที่รวมในรายการ
+	Count        int          `json:"count"`
+	Wage         money.Amount `json:"wage"`
+	Employee     money.Amount `json:"employee"`
+	Employer     money.Amount `json:"employer"`
+	Total        money.Amount `json:"total"`
+	Branches     []Branch     `json:"branches"`
+}
+
+// Add เพิ่มเงินสมทบของผู้ประกันตนเข้าบัญชีนายจ้าง/สาขา (สาขาเรียงตามบัญชีแล้วลำดับที่สาขา)
+func (r *Report) Add(account, branch string, c Contribution) {
+	i := sort.Search(len(r.Branches), func(i int) bool {
+		b := r.Branches[i]
+		return b.Account > account || (b.Account == account && b.Branch >= branch)
+	})
+	if i == len(r.Branches) || r.Branches[i].Account != account || r.Branches[i].Branch != branch {
+		r.Branches = append(r.Branches, Branch{})
+		copy(r.Branches[i+1:], r.Branches[i:])
+		r.Branches[i] = Branch{Account: account, Branch: branch}
+	}
+	r.Branches[i].add(c)
+	r.Count++
+	r.Wage += c.Wage
+	r.Employee += c.Employee
+	r.Employer += c.Employer
+	r.Total += c.Employee + c.Employer
+}
+
+// Problem ข้อมูลที่ทำให้สร้างไฟล์ไม่ได้
+type Problem struct {
+	Ref     string `json:"ref"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ReportError ข้อมูลที่ไม่ผ่านการตรวจทั้งหมด (ระบบ e-Service ปฏิเสธทั้งไฟล์ จึงไม่สร้างไฟล์บางส่วน)
+type ReportError struct {
+	Problems []Problem
+}
+
+func (e *ReportError) Error() string {
+	return fmt.Sprintf("SSO file has %d problem(s)", len(e.Problems))
+}
+
+// validate ตรวจรายการก่อนเขียนไฟล์: ทะเบียนนายจ้าง เลขประจำตัวประชาชน ชื่อ และยอดที่ต้องพอดีกับ field
+func validate(r Report) error {
+	var probs []Problem
+	if len(r.Branches) == 0 {
+		probs = append(probs, Problem{Field: "contributions", Message: "no insured employees in the month"})
+	}
+	for _, b := range r.Branches {
+		if !numeric(b.Account, 10) {
+			probs = append(probs, Problem{Field: "employer", Message: "employer account must be 10 digits (SSO_EMPLOYER_ACCOUNT)"})
+		}
+		if !ValidBranch(b.Branch) {
+			probs = append(probs, Problem{Field: "branch", Message: fmt.Sprintf("branch %q must be 6 digits", b.Branch)})
+		}
+		if b.Count > maxCount || b.Wage > maxWageTotal || b.Total > maxContributionTotal ||
+			b.Employee > maxSideTotal || b.Employer > maxSideTotal {
+			probs = append(probs, Problem{Field: "total", Message: fmt.Sprintf("branch %s totals do not fit the file layout", b.Branch)})
+		}
+		for _, c := range b.Contributions {
+			if !models.ValidNationalID(c.NationalID) {
+				probs = append(probs, Problem{c.EmpCode, "nationalId", "national ID must be 13 digits with a valid check digit"})
+			}
+			if c.FirstName == "" || c.LastName == "" {
+				probs = append(probs, Problem{c.EmpCode, "name", "first and last name are required"})
+			}
+			if c.Wage < 0 || c.Wage > maxWage || c.Employee < 0 || c.Employee > maxContribution {
+				probs = append(probs, Problem{c.EmpCode, "amount", "wage and contribution must be >= 0 and fit the file layout"})
+			}
+		}
+	}
+	if len(probs) > 0 {
+		return &ReportError{Problems: probs}
+	}
+	return nil
+}
+
+// numeric ตัวเลขล้วนยาว n หลัก
+func numeric(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package ssofile
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/money"
+)
+
+// WriteSummary เขียนใบสรุปการนำส่งเงินสมทบ (HTML สำหรับพิมพ์) หนึ่งหน้าต่อบัญชีนายจ้าง/สาขา
+// ใช้ตรวจยอดและแนบเป็นหลักฐานคู่กับไฟล์ที่อัปโหลด
+func WriteSummary(w io.Writer, r Report) error {
+	return summaryTmpl.Execute(w, r)
+}
+
+var thaiMonths = []string{"", "มกราคม", "กุมภาพันธ์", "มีนาคม", "เมษายน", "พฤษภาคม", "มิถุนายน",
+	"กรกฎาคม", "สิงหาคม", "กันยายน", "ตุลาคม", "พฤศจิกายน", "ธันวาคม"}
+
+var summaryTmpl = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"baht":  baht,
+	"month": func(m int) string { return thaiMonths[m] },
+	"be":    buddhistYear,
+	"pct":   func(r float64) string { return strconv.FormatFloat(r*100, 'f', -1, 64) },
+	"inc":   func(i int) int { return i + 1 },
+	"date":  func(t time.Time) string { return t.Format("02/01/") + strconv.Itoa(buddhistYear(t.Year())) },
+}).Parse(`<!DOCTYPE html>
+<html lang="th">
+<head>
+<meta charset="utf-8">
+<title>สปส.1-10 {{month .Month}} {{be .Year}}</title>
+<style>
+body { font-family: "TH Sarabun New", Tahoma, sans-serif; font-size: 14px; margin: 24px; }
+section { page-break-after: always; }
+section:last-child { page-break-after: auto; }
+h1 { font-size: 18px; margin: 0 0 8px; }
+table { border-collapse: collapse; width: 100%; margin-top: 8px; }
+th, td { border: 1px solid #444; padding: 3px 6px; }
+td.n { text-align: right; white-space: nowrap; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 2px 12px; margin: 0; }
+dt { font-weight: bold; }
+</style>
+</head>
+<body>
+{{- $r := .}}
+{{- range .Branches}}
+<section>
+<h1>แบบรายการแสดงการส่งเงินสมทบ (สปส.1-10) ประจำเดือน {{month $r.Month}} {{be $r.Year}}</h1>
+<dl>
+<dt>ชื่อสถานประกอบการ</dt><dd>{{$r.Name}}</dd>
+<dt>เลขที่บัญชีนายจ้าง</dt><dd>{{.Account}}</dd>
+<dt>ลำดับที่สาขา</dt><dd>{{.Branch}}</dd>
+<dt>วันที่จ่ายค่าจ้าง</dt><dd>{{date $r.PayDate}}</dd>
+<dt>อัตราเงินสมทบ</dt><dd>ผู้ประกันตนร้อยละ {{pct $r.Rate}} / นายจ้างร้อยละ {{pct $r.EmployerRate}}</dd>
+</dl>
+<table>
+<tr><th>1. ค่าจ้างทั้งสิ้น</th><td class="n">{{baht .Wage}}</td></tr>
+<tr><th>2. เงินสมทบผู้ประกันตน</th><td class="n">{{baht .Employee}}</td></tr>
+<tr><th>3. เงินสมทบนายจ้าง</th><td class="n">{{baht .Employer}}</td></tr>
+<tr><th>4. รวมเงินสมทบที่นำส่ง</th><td class="n">{{baht .Total}}</td></tr>
+<tr><th>5. จำนวนผู้ประกันตน</th><td class="n">{{.Count}} คน</td></tr>
+</table>
+<table>
+<tr><th>ลำดับ</th><th>เลขประจำตัวประชาชน</th><th>ชื่อ - ชื่อสกุล</th><th>ค่าจ้างที่จ่ายจริง</th><th>ค่าจ้างที่ใช้คำนวณ</th><th>เงินสมทบผู้ประกันตน</th><th>เงินสมทบนายจ้าง</th></tr>
+{{- range $i, $c := .Contributions}}
+<tr><td class="n">{{inc $i}}</td><td>{{$c.NationalID}}</td><td>{{$c.FirstName}} {{$c.LastName}}</td><td class="n">{{baht $c.ActualWage}}</td><td class="n">{{baht $c.Wage}}</td><td class="n">{{baht $c.Employee}}</td><td class="n">{{baht $c.Employer}}</td></tr>
+{{- end}}
+</table>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// baht ยอดเงินมีตัวคั่นหลักพัน เช่น 17,500.00
+func baht(a money.Amount) string {
+	s := a.String()
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 {
+		dot = len(s)
+	}
+	var b strings.Builder
+	for i, r := range s[:dot] {
+		if i > 0 && (dot-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(s[dot:])
+	if neg {
+		return "-" + b.String()
+	}
+	return b.String()
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 
 	"backend/internal/models"
+	"backend/internal/storage"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Storage struct {
@@ -14,10 +16,28 @@ type Storage struct {
 
 func New(db *gorm.DB) *Storage { return &Storage{DB: db} }
 
+// WithTx ทำ fn ใน transaction ของฐานข้อมูล (ซ้อนใน transaction เดิม = savepoint)
+func (s *Storage) WithTx(fn func(tx storage.Port) error) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error { return fn(&Storage{DB: tx}) })
+}
+
 // ---------- Employees ----------
 func (s *Storage) CreateEmployee(e *models.Employee) error {
 	return s.DB.Create(e).Error
 }
+func (s *Storage) GetEmployee(id uint) (*models.Employee, error) {
+	var e models.Employee
+	if err := s.DB.First(&e, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("employee not found")
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+func (s *Storage) UpdateEmployee(e *models.Employee) error {
+	return s.DB.Save(e).Error
+}
 func (s *Storage) ListEmployees() ([]models.Employee, error) {
 	var out []models.Employee
 	return out, s.DB.Order("id ASC").Find(&out).Error
@@ -43,7 +63,7 @@ func (s *Storage) GetPayrollRun(id uint) (*models.PayrollRun, error) {
 }
 func (s *Storage) GetPayrollRunByPeriod(year, month int) (*models.PayrollRun, error) {
 	var run models.PayrollRun
-	if err := s.DB.Where("period_year = ? AND period_month = ?", year, month).First(&run).Error; err != nil {
+	if err := s.DB.Where("period_year = ? AND period_month = ? AND run_type = ? AND pay_group_id IS NULL", year, month, models.RunRegular).First(&run).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // ไม่พบก็ส่ง nil กลับ ไม่ error
 		}
@@ -52,6 +72,68 @@ func (s *Storage) GetPayrollRunByPeriod(year, month int) (*models.PayrollRun, er
 	return &run, nil
 }
 
+func (s *Storage) ListPayrollRuns(year, month int) ([]models.PayrollRun, error) {
+	q := s.DB.Order("period_year ASC, period_month ASC, id ASC")
+	if year != 0 {
+		q = q.Where("period_year = ?", year)
+	}
+	if month != 0 {
+		q = q.Where("period_month = ?", month)
+	}
+	var out []models.PayrollRun
+	return out, q.Find(&out).Error
+}
+func (s *Storage) UpdatePayrollRun(run *models.PayrollRun) error {
+	return s.DB.Omit("Items").Save(run).Error
+}
+func (s *Storage) DeletePayrollRun(id uint) error {
+	return s.DB.Delete(&models.PayrollRun{}, id).Error
+}
+func (s *Storage) CreateRunTransition(t *models.RunTransition) error {
+	return s.DB.Create(t).Error
+}
+func (s *Storage) ListRunTransitions(runID uint) ([]models.RunTransition, error) {
+	var out []models.RunTransition
+	return out, s.DB.Where("payroll_run_id = ?", runID).Order("id ASC").Find(&out).Error
+}
+
+func (s *Storage) CreateRunInput(ri *models.RunInput) error {
+	return s.DB.Create(ri).Error
+}
+func (s *Storage) ListRunInputs(runID uint) ([]models.RunInput, error) {
+	var out []models.RunInput
+	return out, s.DB.Where("payroll_run_id = ?", runID).Order("id ASC").Find(&out).Error
+}
+func (s *Storage) DeleteRunInput(id uint) error {
+	res := s.DB.Delete(&models.RunInput{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("run input not found")
+	}
+	return nil
+}
+
+// ---------- Pay groups ----------
+func (s *Storage) CreatePayGroup(g *models.PayGroup) error {
+	return s.DB.Create(g).Error
+}
+func (s *Storage) GetPayGroup(id uint) (*models.PayGroup, error) {
+	var g models.PayGroup
+	if err := s.DB.First(&g, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pay group not found")
+		}
+		return nil, err
+	}
+	return &g, nil
+}
+func (s *Storage) ListPayGroups() ([]models.PayGroup, error) {
+	var out []models.PayGroup
+	return out, s.DB.Order("id ASC").Find(&out).Error
+}
+
 // ---------- Payroll Items (payslips) ----------
 func (s *Storage) ClearPayrollItems(runID uint) error {
 	return s.DB.Where("payroll_run_id = ?", runID).Delete(&models.PayrollItem{}).Error
@@ -61,11 +143,11 @@ func (s *Storage) SavePayrollItem(item *models.PayrollItem) error {
 }
 func (s *Storage) ListPayrollItems(runID uint) ([]models.PayrollItem, error) {
 	var out []models.PayrollItem
-	return out, s.DB.Where("payroll_run_id = ?", runID).Order("id ASC").Find(&out).Error
+	return out, s.DB.Preload("Lines", orderLines).Where("payroll_run_id = ?", runID).Order("id ASC").Find(&out).Error
 }
 func (s *Storage) GetPayrollItem(id uint) (*models.PayrollItem, error) {
 	var item models.PayrollItem
-	if err := s.DB.First(&item, id).Error; err != nil {
+	if err := s.DB.Preload("Lines", orderLines).First(&item, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("payroll item not found")
 		}
@@ -73,8 +155,29 @@ func (s *Storage) GetPayrollItem(id uint) (*models.PayrollItem, error) {
 	}
 	return &item, nil
 }
+
+// UpdatePayrollItem บันทึกยอดรวมและแทนที่ lines ทั้งชุดใน transaction เดียว
 func (s *Storage) UpdatePayrollItem(item *models.PayrollItem) error {
-	return s.DB.Save(item).Error
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Lines").Save(item).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payslip_id = ?", item.ID).Delete(&models.PayrollLine{}).Error; err != nil {
+			return err
+		}
+		for i := range item.Lines {
+			item.Lines[i].ID = 0
+			item.Lines[i].ItemID = item.ID
+		}
+		if len(item.Lines) == 0 {
+			return nil
+		}
+		return tx.Create(&item.Lines).Error
+	})
+}
+
+func orderLines(db *gorm.DB) *gorm.DB {
+	return db.Order("seq ASC")
 }
 
 // ---------- Payslips (ถ้าใช้งาน) ----------
@@ -100,7 +203,144 @@ func (s *Storage) DeletePayslipsByRun(runID uint) error {
 func (s *Storage) CreateLeave(lv *models.Leave) error {
 	return s.DB.Create(lv).Error
 }
+func (s *Storage) GetLeave(id uint) (*models.Leave, error) {
+	var lv models.Leave
+	if err := s.DB.First(&lv, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("leave not found")
+		}
+		return nil, err
+	}
+	return &lv, nil
+}
+func (s *Storage) UpdateLeave(lv *models.Leave) error {
+	return s.DB.Save(lv).Error
+}
 func (s *Storage) ListLeaves() ([]models.Leave, error) {
 	var out []models.Leave
 	return out, s.DB.Order("id ASC").Find(&out).Error
 }
+
+// ---------- Leave policies ----------
+func (s *Storage) ListLeavePolicies() ([]models.LeavePolicy, error) {
+	var out []models.LeavePolicy
+	return out, s.DB.Order("leave_type ASC").Find(&out).Error
+}
+func (s *Storage) SaveLeavePolicy(p *models.LeavePolicy) error {
+	return s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "leave_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tiers", "max_carry_over", "over_balance", "updated_at"}),
+	}).Create(p).Error
+}
+
+// ---------- Pay components ----------
+func (s *Storage) CreatePayComponent(pc *models.PayComponent) error {
+	return s.DB.Create(pc).Error
+}
+func (s *Storage) GetPayComponent(id uint) (*models.PayComponent, error) {
+	var pc models.PayComponent
+	if err := s.DB.First(&pc, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pay component not found")
+		}
+		return nil, err
+	}
+	return &pc, nil
+}
+func (s *Storage) UpdatePayComponent(pc *models.PayComponent) error {
+	return s.DB.Save(pc).Error
+}
+func (s *Storage) ListPayComponents(employeeID uint) ([]models.PayComponent, error) {
+	var out []models.PayComponent
+	return out, s.DB.Where("employee_id = ?", employeeID).Order("id ASC").Find(&out).Error
+}
+
+// ---------- Salary history ----------
+func (s *Storage) CreateSalaryRecord(r *models.SalaryRecord) error {
+	return s.DB.Create(r).Error
+}
+func (s *Storage) ListSalaryRecords(employeeID uint) ([]models.SalaryRecord, error) {
+	var out []models.SalaryRecord
+	return out, s.DB.Where("employee_id = ?", employeeID).Order("effective_from ASC, id ASC").Find(&out).Error
+}
+
+// ---------- Loans ----------
+func (s *Storage) CreateLoan(l *models.Loan) error {
+	return s.DB.Create(l).Error
+}
+func (s *Storage) GetLoan(id uint) (*models.Loan, error) {
+	var l models.Loan
+	if err := s.DB.First(&l, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("loan not found")
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+func (s *Storage) UpdateLoan(l *models.Loan) error {
+	return s.DB.Save(l).Error
+}
+func (s *Storage) ListLoans(employeeID uint) ([]models.Loan, error) {
+	var out []models.Loan
+	q := s.DB.Order("id ASC")
+	if employeeID != 0 {
+		q = q.Where("employee_id = ?", employeeID)
+	}
+	return out, q.Find(&out).Error
+}
+func (s *Storage) CreateLoanRepayment(r *models.LoanRepayment) error {
+	return s.DB.Create(r).Error
+}
+func (s *Storage) ListLoanRepayments(loanID uint) ([]models.LoanRepayment, error) {
+	var out []models.LoanRepayment
+	return out, s.DB.Where("loan_id = ?", loanID).Order("id ASC").Find(&out).Error
+}
+func (s *Storage) DeleteLoanRepayment(id uint) error {
+	return s.DB.Delete(&models.LoanRepayment{}, id).Error
+}
+
+// ---------- Garnishments ----------
+func (s *Storage) CreateGarnishment(g *models.Garnishment) error {
+	return s.DB.Create(g).Error
+}
+func (s *Storage) GetGarnishment(id uint) (*models.Garnishment, error) {
+	var g models.Garnishment
+	if err := s.DB.First(&g, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("garnishment not found")
+		}
+		return nil, err
+	}
+	return &g, nil
+}
+func (s *Storage) UpdateGarnishment(g *models.Garnishment) error {
+	return s.DB.Save(g).Error
+}
+func (s *Storage) ListGarnishments(employeeID uint) ([]models.Garnishment, error) {
+	var out []models.Garnishment
+	q := s.DB.Order("id ASC")
+	if employeeID != 0 {
+		q = q.Where("employee_id = ?", employeeID)
+	}
+	return out, q.Find(&out).Error
+}
+func (s *Storage) CreateGarnishmentRemittance(r *models.GarnishmentRemittance) error {
+	return s.DB.Create(r).Error
+}
+func (s *Storage) ListGarnishmentRemittances(garnishmentID uint) ([]models.GarnishmentRemittance, error) {
+	var out []models.GarnishmentRemittance
+	return out, s.DB.Where("garnishment_id = ?", garnishmentID).Order("id ASC").Find(&out).Error
+}
+func (s *Storage) DeleteGarnishmentRemittance(id uint) error {
+	return s.DB.Delete(&models.GarnishmentRemittance{}, id).Error
+}
+
+// ---------- Statutory rates ----------
+func (s *Storage) CreateStatutoryRate(r *models.StatutoryRate) error {
+	return s.DB.Create(r).Error
+}
+func (s *Storage) ListStatutoryRates() ([]models.StatutoryRate, error) {
+	var out []models.StatutoryRate
+	return out, s.DB.Order("effective_from ASC").Find(&out).Error
+}
@@ -4,15 +4,33 @@ import "backend/internal/models"
 
 // Port: อินเตอร์เฟซกลางที่ทั้ง in-memory และ Postgres ต้องทำให้ครบ
 type Port interface {
+	// WithTx ทำ fn เป็นหน่วยเดียว: fn คืน error = ย้อนทุกการเขียนผ่าน tx (เรียกซ้อนได้ ทำงานแบบ savepoint)
+	WithTx(fn func(tx Port) error) error
+
 	// Employees
 	CreateEmployee(*models.Employee) error
+	GetEmployee(uint) (*models.Employee, error)
+	UpdateEmployee(*models.Employee) error
 	ListEmployees() ([]models.Employee, error)
 	ListActiveEmployees() ([]models.Employee, error)
 
+	// Pay groups (กลุ่มจ่ายเงินตามความถี่)
+	CreatePayGroup(*models.PayGroup) error
+	GetPayGroup(uint) (*models.PayGroup, error)
+	ListPayGroups() ([]models.PayGroup, error)
+
 	// Payroll runs & items (ใช้ตาราง payslips เป็น items)
 	CreatePayrollRun(*models.PayrollRun) error
 	GetPayrollRun(uint) (*models.PayrollRun, error)
-	GetPayrollRunByPeriod(year, month int) (*models.PayrollRun, error)
+	GetPayrollRunByPeriod(year, month int) (*models.PayrollRun, error) // run ปกติ (regular) ของงวด
+	ListPayrollRuns(year, month int) ([]models.PayrollRun, error)      // 0 = ไม่กรอง
+	UpdatePayrollRun(*models.PayrollRun) error
+	DeletePayrollRun(uint) error
+	CreateRunTransition(*models.RunTransition) error
+	ListRunTransitions(runID uint) ([]models.RunTransition, error)
+	CreateRunInput(*models.RunInput) error
+	ListRunInputs(runID uint) ([]models.RunInput, error)
+	DeleteRunInput(id uint) error
 	ClearPayrollItems(uint) error
 	SavePayrollItem(*models.PayrollItem) error
 	ListPayrollItems(uint) ([]models.PayrollItem, error)
@@ -25,7 +43,45 @@ type Port interface {
 	FindPayslip(uint, uint) (*models.Payslip, error)
 	DeletePayslipsByRun(uint) error
 
+	// Pay components (เงินได้/เงินหักประจำของพนักงาน)
+	CreatePayComponent(*models.PayComponent) error
+	GetPayComponent(uint) (*models.PayComponent, error)
+	UpdatePayComponent(*models.PayComponent) error
+	ListPayComponents(employeeID uint) ([]models.PayComponent, error)
+
+	// Salary history (เงินเดือนตามวันที่มีผล)
+	CreateSalaryRecord(*models.SalaryRecord) error
+	ListSalaryRecords(employeeID uint) ([]models.SalaryRecord, error)
+
+	// Loans (เงินกู้/เงินเดือนล่วงหน้าที่ผ่อนชำระผ่าน payroll)
+	CreateLoan(*models.Loan) error
+	GetLoan(uint) (*models.Loan, error)
+	UpdateLoan(*models.Loan) error
+	ListLoans(employeeID uint) ([]models.Loan, error) // 0 = ทุกคน
+	CreateLoanRepayment(*models.LoanRepayment) error
+	ListLoanRepayments(loanID uint) ([]models.LoanRepayment, error)
+	DeleteLoanRepayment(id uint) error
+
+	// Garnishments (คำสั่งอายัดเงินเดือนและยอดนำส่งต่อ run)
+	CreateGarnishment(*models.Garnishment) error
+	GetGarnishment(uint) (*models.Garnishment, error)
+	UpdateGarnishment(*models.Garnishment) error
+	ListGarnishments(employeeID uint) ([]models.Garnishment, error) // 0 = ทุกคน
+	CreateGarnishmentRemittance(*models.GarnishmentRemittance) error
+	ListGarnishmentRemittances(garnishmentID uint) ([]models.GarnishmentRemittance, error)
+	DeleteGarnishmentRemittance(id uint) error
+
+	// Statutory rate tables (SSO/ภาษี ตามวันที่มีผล)
+	CreateStatutoryRate(*models.StatutoryRate) error
+	ListStatutoryRates() ([]models.StatutoryRate, error)
+
 	// Leaves
 	CreateLeave(*models.Leave) error
+	GetLeave(id uint) (*models.Leave, error)
+	UpdateLeave(*models.Leave) error
 	ListLeaves() ([]models.Leave, error)
+
+	// Leave policies (สิทธิการลาแต่ละประเภท; บันทึกซ้ำประเภทเดิม = แทนที่)
+	ListLeavePolicies() ([]models.LeavePolicy, error)
+	SaveLeavePolicy(*models.LeavePolicy) error
 }
@@ -11,30 +11,69 @@ import (
 
 // Storage provides an in-memory persistence layer for the backend.
 type Storage struct {
-	mu sync.RWMutex
+	mu   sync.RWMutex
+	txMu sync.Mutex // serializes WithTx
+	data
+}
 
+// data holds every table of the in-memory store; WithTx snapshots it to roll back.
+type data struct {
 	nextEmployee    uint
 	nextPayrollRun  uint
 	nextPayrollItem uint
+	nextPayrollLine uint
 	nextPayslip     uint
 	nextLeave       uint
+	nextRate        uint
+	nextComponent   uint
+	nextPolicy      uint
+	nextTransition  uint
+	nextRunInput    uint
+	nextPayGroup    uint
+	nextSalary      uint
+	nextLoan        uint
+	nextRepayment   uint
+	nextGarnishment uint
+	nextRemittance  uint
 
 	employees    map[uint]*models.Employee
 	payrollRuns  map[uint]*models.PayrollRun
 	payrollItems map[uint]map[uint]*models.PayrollItem // runID -> (itemID -> item)
 	payslips     map[uint]*models.Payslip
 	leaves       map[uint]*models.Leave
+	rates        map[uint]*models.StatutoryRate
+	components   map[uint]*models.PayComponent
+	policies     map[string]*models.LeavePolicy  // leave type -> policy
+	transitions  map[uint][]models.RunTransition // runID -> history
+	runInputs    map[uint]*models.RunInput
+	payGroups    map[uint]*models.PayGroup
+	salaries     map[uint]*models.SalaryRecord
+	loans        map[uint]*models.Loan
+	repayments   map[uint]*models.LoanRepayment
+	garnishments map[uint]*models.Garnishment
+	remittances  map[uint]*models.GarnishmentRemittance
 }
 
 // New creates an empty Storage instance.
 func New() *Storage {
-	return &Storage{
+	return &Storage{data: data{
 		employees:    make(map[uint]*models.Employee),
 		payrollRuns:  make(map[uint]*models.PayrollRun),
 		payrollItems: make(map[uint]map[uint]*models.PayrollItem),
 		payslips:     make(map[uint]*models.Payslip),
 		leaves:       make(map[uint]*models.Leave),
-	}
+		rates:        make(map[uint]*models.StatutoryRate),
+		components:   make(map[uint]*models.PayComponent),
+		policies:     make(map[string]*models.LeavePolicy),
+		transitions:  make(map[uint][]models.RunTransition),
+		runInputs:    make(map[uint]*models.RunInput),
+		payGroups:    make(map[uint]*models.PayGroup),
+		salaries:     make(map[uint]*models.SalaryRecord),
+		loans:        make(map[uint]*models.Loan),
+		repayments:   make(map[uint]*models.LoanRepayment),
+		garnishments: make(map[uint]*models.Garnishment),
+		remittances:  make(map[uint]*models.GarnishmentRemittance),
+	}}
 }
 
 // CreateEmployee persists a new employee.
@@ -50,6 +89,19 @@ func (s *Storage) CreateEmployee(e *models.Employee) error {
 	return nil
 }
 
+// GetEmployee fetches an employee by ID.
+func (s *Storage) GetEmployee(id uint) (*models.Employee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.employees[id]
+	if !ok {
+		return nil, errors.New("employee not found")
+	}
+	cp := copyEmployee(e)
+	return &cp, nil
+}
+
 // ListEmployees returns all employees.
 func (s *Storage) ListEmployees() ([]models.Employee, error) {
 	s.mu.RLock()
@@ -81,6 +133,64 @@ func (s *Storage) ListActiveEmployees() ([]models.Employee, error) {
 	return out, nil
 }
 
+// UpdateEmployee replaces an existing employee.
+func (s *Storage) UpdateEmployee(e *models.Employee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.employees[e.ID]; !ok {
+		return errors.New("employee not found")
+	}
+	cp := copyEmployee(e)
+	s.employees[e.ID] = &cp
+	return nil
+}
+
+// CreatePayGroup stores a new pay group; codes are unique.
+func (s *Storage) CreatePayGroup(g *models.PayGroup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.payGroups {
+		if existing.Code == g.Code {
+			return errors.New("pay group code already exists")
+		}
+	}
+	s.nextPayGroup++
+	g.ID = s.nextPayGroup
+	g.CreatedAt = time.Now().UTC()
+
+	cp := *g
+	s.payGroups[g.ID] = &cp
+	return nil
+}
+
+// GetPayGroup returns a pay group by ID.
+func (s *Storage) GetPayGroup(id uint) (*models.PayGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.payGroups[id]
+	if !ok {
+		return nil, errors.New("pay group not found")
+	}
+	cp := *g
+	return &cp, nil
+}
+
+// ListPayGroups returns every pay group ordered by ID.
+func (s *Storage) ListPayGroups() ([]models.PayGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.PayGroup, 0, len(s.payGroups))
+	for _, g := range s.payGroups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
 // CreatePayrollRun stores a new payroll run.
 func (s *Storage) CreatePayrollRun(run *models.PayrollRun) error {
 	s.mu.Lock()
@@ -90,7 +200,7 @@ func (s *Storage) CreatePayrollRun(run *models.PayrollRun) error {
 	run.ID = s.nextPayrollRun
 	run.CreatedAt = time.Now().UTC()
 
-	cp := *run
+	cp := copyPayrollRun(run)
 	s.payrollRuns[run.ID] = &cp
 	return nil
 }
@@ -104,24 +214,143 @@ func (s *Storage) GetPayrollRun(id uint) (*models.PayrollRun, error) {
 	if !ok {
 		return nil, errors.New("payroll run not found")
 	}
-	cp := *run
+	cp := copyPayrollRun(run)
 	return &cp, nil
 }
 
-// GetPayrollRunByPeriod fetches a payroll run by year and month.
+// GetPayrollRunByPeriod fetches the regular run of the default monthly group by year and month.
 func (s *Storage) GetPayrollRunByPeriod(year, month int) (*models.PayrollRun, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	for _, run := range s.payrollRuns {
-		if run.PeriodYear == year && run.PeriodMonth == month {
-			cp := *run
+		if run.PeriodYear == year && run.PeriodMonth == month && !run.OffCycle() && run.PayGroupID == nil {
+			cp := copyPayrollRun(run)
 			return &cp, nil
 		}
 	}
 	return nil, nil // Not found
 }
 
+// ListPayrollRuns returns runs ordered by period then ID; zero year/month matches any.
+func (s *Storage) ListPayrollRuns(year, month int) ([]models.PayrollRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.PayrollRun, 0, len(s.payrollRuns))
+	for _, run := range s.payrollRuns {
+		if (year != 0 && run.PeriodYear != year) || (month != 0 && run.PeriodMonth != month) {
+			continue
+		}
+		out = append(out, copyPayrollRun(run))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PeriodYear != out[j].PeriodYear {
+			return out[i].PeriodYear < out[j].PeriodYear
+		}
+		if out[i].PeriodMonth != out[j].PeriodMonth {
+			return out[i].PeriodMonth < out[j].PeriodMonth
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// UpdatePayrollRun replaces an existing payroll run.
+func (s *Storage) UpdatePayrollRun(run *models.PayrollRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.payrollRuns[run.ID]; !ok {
+		return errors.New("payroll run not found")
+	}
+	cp := copyPayrollRun(run)
+	s.payrollRuns[run.ID] = &cp
+	return nil
+}
+
+// DeletePayrollRun removes a run together with its items and transitions.
+func (s *Storage) DeletePayrollRun(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.payrollRuns[id]; !ok {
+		return errors.New("payroll run not found")
+	}
+	delete(s.payrollRuns, id)
+	delete(s.payrollItems, id)
+	delete(s.transitions, id)
+	for inputID, ri := range s.runInputs {
+		if ri.RunID == id {
+			delete(s.runInputs, inputID)
+		}
+	}
+	return nil
+}
+
+// CreateRunInput stores a one-off pay entry for a run.
+func (s *Storage) CreateRunInput(ri *models.RunInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRunInput++
+	ri.ID = s.nextRunInput
+	ri.CreatedAt = time.Now().UTC()
+
+	cp := *ri
+	s.runInputs[ri.ID] = &cp
+	return nil
+}
+
+// ListRunInputs returns the one-off entries of a run ordered by ID.
+func (s *Storage) ListRunInputs(runID uint) ([]models.RunInput, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.RunInput, 0)
+	for _, ri := range s.runInputs {
+		if ri.RunID == runID {
+			out = append(out, *ri)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// DeleteRunInput removes a one-off entry.
+func (s *Storage) DeleteRunInput(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.runInputs[id]; !ok {
+		return errors.New("run input not found")
+	}
+	delete(s.runInputs, id)
+	return nil
+}
+
+// CreateRunTransition appends a status change to the run history.
+func (s *Storage) CreateRunTransition(t *models.RunTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextTransition++
+	t.ID = s.nextTransition
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	s.transitions[t.RunID] = append(s.transitions[t.RunID], *t)
+	return nil
+}
+
+// ListRunTransitions returns the status history of a run, oldest first.
+func (s *Storage) ListRunTransitions(runID uint) ([]models.RunTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.RunTransition{}, s.transitions[runID]...), nil
+}
+
 // ClearPayrollItems removes all items for a run.
 func (s *Storage) ClearPayrollItems(runID uint) error {
 	s.mu.Lock()
@@ -139,11 +368,12 @@ func (s *Storage) SavePayrollItem(item *models.PayrollItem) error {
 	s.nextPayrollItem++
 	item.ID = s.nextPayrollItem
 	item.GeneratedAt = time.Now().UTC()
+	s.assignLineIDs(item)
 
 	if _, ok := s.payrollItems[item.RunID]; !ok {
 		s.payrollItems[item.RunID] = make(map[uint]*models.PayrollItem)
 	}
-	cp := *item
+	cp := copyPayrollItem(item)
 	s.payrollItems[item.RunID][item.ID] = &cp
 	return nil
 }
@@ -156,8 +386,7 @@ func (s *Storage) ListPayrollItems(runID uint) ([]models.PayrollItem, error) {
 	bucket := s.payrollItems[runID]
 	out := make([]models.PayrollItem, 0, len(bucket))
 	for _, it := range bucket {
-		cp := *it
-		out = append(out, cp)
+		out = append(out, copyPayrollItem(it))
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
 	return out, nil
@@ -170,7 +399,7 @@ func (s *Storage) GetPayrollItem(id uint) (*models.PayrollItem, error) {
 
 	for _, bucket := range s.payrollItems {
 		if item, ok := bucket[id]; ok {
-			cp := *item
+			cp := copyPayrollItem(item)
 			return &cp, nil
 		}
 	}
@@ -189,8 +418,9 @@ func (s *Storage) UpdatePayrollItem(item *models.PayrollItem) error {
 	if _, ok := bucket[item.ID]; !ok {
 		return errors.New("payroll item not found")
 	}
+	s.assignLineIDs(item)
 
-	cp := *item
+	cp := copyPayrollItem(item)
 	bucket[item.ID] = &cp
 	return nil
 }
@@ -262,7 +492,35 @@ func (s *Storage) CreateLeave(lv *models.Leave) error {
 	s.nextLeave++
 	lv.ID = s.nextLeave
 	lv.CreatedAt = time.Now().UTC()
-	// ถ้ามี UpdatedAt ในโมเดลค่อยตั้งเพิ่ม
+	lv.UpdatedAt = lv.CreatedAt
+
+	cp := *lv
+	s.leaves[lv.ID] = &cp
+	return nil
+}
+
+// GetLeave returns a leave entry by id.
+func (s *Storage) GetLeave(id uint) (*models.Leave, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lv, ok := s.leaves[id]
+	if !ok {
+		return nil, errors.New("leave not found")
+	}
+	cp := *lv
+	return &cp, nil
+}
+
+// UpdateLeave replaces an existing leave entry.
+func (s *Storage) UpdateLeave(lv *models.Leave) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.leaves[lv.ID]; !ok {
+		return errors.New("leave not found")
+	}
+	lv.UpdatedAt = time.Now().UTC()
 
 	cp := *lv
 	s.leaves[lv.ID] = &cp
@@ -283,8 +541,387 @@ func (s *Storage) ListLeaves() ([]models.Leave, error) {
 	return out, nil
 }
 
+// ListLeavePolicies returns stored leave policies ordered by type.
+func (s *Storage) ListLeavePolicies() ([]models.LeavePolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.LeavePolicy, 0, len(s.policies))
+	for _, p := range s.policies {
+		cp := *p
+		cp.Tiers = append([]models.AccrualTier(nil), p.Tiers...)
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out, nil
+}
+
+// SaveLeavePolicy inserts or replaces the policy for a leave type.
+func (s *Storage) SaveLeavePolicy(p *models.LeavePolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.policies[p.Type]; ok {
+		p.ID = existing.ID
+	} else {
+		s.nextPolicy++
+		p.ID = s.nextPolicy
+	}
+	p.UpdatedAt = time.Now().UTC()
+
+	cp := *p
+	cp.Tiers = append([]models.AccrualTier(nil), p.Tiers...)
+	s.policies[p.Type] = &cp
+	return nil
+}
+
+// CreateStatutoryRate stores a rate table; one table per effective date.
+func (s *Storage) CreateStatutoryRate(r *models.StatutoryRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.rates {
+		if existing.EffectiveFrom.Equal(r.EffectiveFrom) {
+			return errors.New("rate table already exists for this effective date")
+		}
+	}
+
+	s.nextRate++
+	r.ID = s.nextRate
+	r.CreatedAt = time.Now().UTC()
+
+	cp := *r
+	cp.TaxBrackets = append([]models.TaxBracket(nil), r.TaxBrackets...)
+	s.rates[r.ID] = &cp
+	return nil
+}
+
+// ListStatutoryRates returns rate tables ordered by effective date.
+func (s *Storage) ListStatutoryRates() ([]models.StatutoryRate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.StatutoryRate, 0, len(s.rates))
+	for _, r := range s.rates {
+		cp := *r
+		cp.TaxBrackets = append([]models.TaxBracket(nil), r.TaxBrackets...)
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EffectiveFrom.Before(out[j].EffectiveFrom) })
+	return out, nil
+}
+
+// CreatePayComponent stores a recurring earning/deduction for an employee.
+func (s *Storage) CreatePayComponent(pc *models.PayComponent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextComponent++
+	pc.ID = s.nextComponent
+	pc.CreatedAt = time.Now().UTC()
+
+	cp := *pc
+	s.components[pc.ID] = &cp
+	return nil
+}
+
+// GetPayComponent returns a pay component by ID.
+func (s *Storage) GetPayComponent(id uint) (*models.PayComponent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pc, ok := s.components[id]
+	if !ok {
+		return nil, errors.New("pay component not found")
+	}
+	cp := *pc
+	return &cp, nil
+}
+
+// UpdatePayComponent replaces an existing pay component.
+func (s *Storage) UpdatePayComponent(pc *models.PayComponent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.components[pc.ID]; !ok {
+		return errors.New("pay component not found")
+	}
+	cp := *pc
+	s.components[pc.ID] = &cp
+	return nil
+}
+
+// ListPayComponents returns the pay components of an employee.
+func (s *Storage) ListPayComponents(employeeID uint) ([]models.PayComponent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.PayComponent, 0)
+	for _, pc := range s.components {
+		if pc.EmployeeID != employeeID {
+			continue
+		}
+		out = append(out, *pc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// CreateSalaryRecord appends a salary history record.
+func (s *Storage) CreateSalaryRecord(r *models.SalaryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSalary++
+	r.ID = s.nextSalary
+	r.CreatedAt = time.Now().UTC()
+
+	cp := *r
+	s.salaries[r.ID] = &cp
+	return nil
+}
+
+// ListSalaryRecords returns an employee's salary history ordered by effective date.
+func (s *Storage) ListSalaryRecords(employeeID uint) ([]models.SalaryRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.SalaryRecord, 0)
+	for _, r := range s.salaries {
+		if r.EmployeeID == employeeID {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].EffectiveFrom.Equal(out[j].EffectiveFrom) {
+			return out[i].EffectiveFrom.Before(out[j].EffectiveFrom)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// CreateLoan stores a new employee loan.
+func (s *Storage) CreateLoan(l *models.Loan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextLoan++
+	l.ID = s.nextLoan
+	l.CreatedAt = time.Now().UTC()
+
+	cp := *l
+	s.loans[l.ID] = &cp
+	return nil
+}
+
+// GetLoan returns a loan by ID.
+func (s *Storage) GetLoan(id uint) (*models.Loan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.loans[id]
+	if !ok {
+		return nil, errors.New("loan not found")
+	}
+	cp := *l
+	return &cp, nil
+}
+
+// UpdateLoan replaces an existing loan.
+func (s *Storage) UpdateLoan(l *models.Loan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.loans[l.ID]; !ok {
+		return errors.New("loan not found")
+	}
+	cp := *l
+	s.loans[l.ID] = &cp
+	return nil
+}
+
+// ListLoans returns the loans of an employee (0 = all employees).
+func (s *Storage) ListLoans(employeeID uint) ([]models.Loan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.Loan, 0)
+	for _, l := range s.loans {
+		if employeeID != 0 && l.EmployeeID != employeeID {
+			continue
+		}
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// CreateLoanRepayment records a repayment of a loan.
+func (s *Storage) CreateLoanRepayment(r *models.LoanRepayment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRepayment++
+	r.ID = s.nextRepayment
+	r.CreatedAt = time.Now().UTC()
+
+	cp := *r
+	s.repayments[r.ID] = &cp
+	return nil
+}
+
+// ListLoanRepayments returns the repayments of a loan in the order recorded.
+func (s *Storage) ListLoanRepayments(loanID uint) ([]models.LoanRepayment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.LoanRepayment, 0)
+	for _, r := range s.repayments {
+		if r.LoanID == loanID {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// DeleteLoanRepayment removes a repayment (used when an approved run is reopened).
+func (s *Storage) DeleteLoanRepayment(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.repayments[id]; !ok {
+		return errors.New("loan repayment not found")
+	}
+	delete(s.repayments, id)
+	return nil
+}
+
+// CreateGarnishment stores a new garnishment order.
+func (s *Storage) CreateGarnishment(g *models.Garnishment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextGarnishment++
+	g.ID = s.nextGarnishment
+	g.CreatedAt = time.Now().UTC()
+
+	cp := *g
+	s.garnishments[g.ID] = &cp
+	return nil
+}
+
+// GetGarnishment returns a garnishment order by ID.
+func (s *Storage) GetGarnishment(id uint) (*models.Garnishment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.garnishments[id]
+	if !ok {
+		return nil, errors.New("garnishment not found")
+	}
+	cp := *g
+	return &cp, nil
+}
+
+// UpdateGarnishment replaces an existing garnishment order.
+func (s *Storage) UpdateGarnishment(g *models.Garnishment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.garnishments[g.ID]; !ok {
+		return errors.New("garnishment not found")
+	}
+	cp := *g
+	s.garnishments[g.ID] = &cp
+	return nil
+}
+
+// ListGarnishments returns the garnishment orders of an employee (0 = all employees).
+func (s *Storage) ListGarnishments(employeeID uint) ([]models.Garnishment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.Garnishment, 0)
+	for _, g := range s.garnishments {
+		if employeeID != 0 && g.EmployeeID != employeeID {
+			continue
+		}
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// CreateGarnishmentRemittance records an amount withheld for a garnishment in a run.
+func (s *Storage) CreateGarnishmentRemittance(r *models.GarnishmentRemittance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRemittance++
+	r.ID = s.nextRemittance
+	r.CreatedAt = time.Now().UTC()
+
+	cp := *r
+	s.remittances[r.ID] = &cp
+	return nil
+}
+
+// ListGarnishmentRemittances returns the remittances of a garnishment in the order recorded.
+func (s *Storage) ListGarnishmentRemittances(garnishmentID uint) ([]models.GarnishmentRemittance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]models.GarnishmentRemittance, 0)
+	for _, r := range s.remittances {
+		if r.GarnishmentID == garnishmentID {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// DeleteGarnishmentRemittance removes a remittance (used when an approved run is reopened).
+func (s *Storage) DeleteGarnishmentRemittance(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.remittances[id]; !ok {
+		return errors.New("garnishment remittance not found")
+	}
+	delete(s.remittances, id)
+	return nil
+}
+
+// assignLineIDs gives new lines an ID and links every line to its item.
+func (s *Storage) assignLineIDs(item *models.PayrollItem) {
+	for i := range item.Lines {
+		if item.Lines[i].ID == 0 {
+			s.nextPayrollLine++
+			item.Lines[i].ID = s.nextPayrollLine
+		}
+		item.Lines[i].ItemID = item.ID
+	}
+}
+
+func copyPayrollItem(it *models.PayrollItem) models.PayrollItem {
+	cp := *it
+	cp.Lines = append([]models.PayrollLine(nil), it.Lines...)
+	return cp
+}
+
 func copyEmployee(e *models.Employee) models.Employee {
 	cp := *e
 	// ไม่มี Employment ในสคีมาใหม่แล้ว
 	return cp
 }
+
+func copyPayrollRun(run *models.PayrollRun) models.PayrollRun {
+	cp := *run
+	cp.Items = nil
+	cp.EmployeeIDs = append([]uint(nil), run.EmployeeIDs...)
+	cp.ComponentCodes = append([]string(nil), run.ComponentCodes...)
+	return cp
+}
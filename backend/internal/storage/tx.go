@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"maps"
+
+	"backend/internal/models"
+)
+
+// WithTx ทำ fn เป็นหน่วยเดียว: fn คืน error = ย้อนทุกการเขียนที่ทำผ่าน tx
+// transaction ทำทีละรายการ การเขียนนอก transaction ระหว่างที่อีกรายการกำลังย้อนกลับจะหายไปด้วย
+func (s *Storage) WithTx(fn func(tx Port) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	return memTx{s}.WithTx(fn)
+}
+
+// memTx store ที่ส่งให้ fn (WithTx ซ้อน = savepoint)
+type memTx struct{ *Storage }
+
+func (t memTx) WithTx(fn func(tx Port) error) error {
+	snap := t.snapshot()
+	if err := fn(t); err != nil {
+		t.mu.Lock()
+		t.data = snap
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// snapshot สำเนาทุกตาราง: record ที่เก็บไว้ถูกแทนที่ทั้งก้อนเสมอ (ไม่แก้ในที่) จึงสำเนาแค่ map ก็พอ
+func (s *Storage) snapshot() data {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d := s.data
+	d.employees = maps.Clone(s.employees)
+	d.payrollRuns = maps.Clone(s.payrollRuns)
+	d.payrollItems = make(map[uint]map[uint]*models.PayrollItem, len(s.payrollItems))
+	for runID, items := range s.payrollItems {
+		d.payrollItems[runID] = maps.Clone(items)
+	}
+	d.payslips = maps.Clone(s.payslips)
+	d.leaves = maps.Clone(s.leaves)
+	d.rates = maps.Clone(s.rates)
+	d.components = maps.Clone(s.components)
+	d.policies = maps.Clone(s.policies)
+	d.transitions = maps.Clone(s.transitions)
+	d.runInputs = maps.Clone(s.runInputs)
+	d.payGroups = maps.Clone(s.payGroups)
+	d.salaries = maps.Clone(s.salaries)
+	d.loans = maps.Clone(s.loans)
+	d.repayments = maps.Clone(s.repayments)
+	d.garnishments = maps.Clone(s.garnishments)
+	d.remittances = maps.Clone(s.remittances)
+	return d
+}
@@ -4,11 +4,11 @@ BEGIN;
 INSERT INTO employees
 (emp_code, first_name, last_name, department, position, base_salary, bank_account, pvd_rate, withholding_rate, sso_enabled, status, hired_at)
 VALUES
-('E001', 'สมชาย',  'สุขใจ',    'ฝ่ายบุคคล',  'HR Manager',       50000, '123-456-7890', 0.03, 0.05, TRUE,  'active', DATE '2023-01-01'),
-('E002', 'สุดา',   'ดีงาม',    'ฝ่ายบัญชี',  'Accountant',       40000, '987-654-3210', 0.03, 0.05, TRUE,  'active', DATE '2023-03-01'),
-('E003', 'อนันต์', 'มีชัย',    'ฝ่ายไอที',   'Developer',        60000, '111-222-3333', 0.03, 0.05, TRUE,  'active', DATE '2024-01-01'),
-('E004', 'กมล',    'ใจดี',     'ฝ่ายขาย',     'Sales Executive',  45000, '222-333-4444', 0.03, 0.05, TRUE,  'active', DATE '2022-06-01'),
-('E005', 'พรทิพย์','รุ่งเรือง','ฝ่ายการเงิน', 'Finance Officer', 48000, '555-666-7777', 0.03, 0.05, TRUE,  'active', DATE '2021-10-01');
+('E001', 'สมชาย',  'สุขใจ',    'ฝ่ายบุคคล',  'HR Manager',       50000, '123-456-7890', 0.03, 0.00, TRUE,  'active', DATE '2023-01-01'),
+('E002', 'สุดา',   'ดีงาม',    'ฝ่ายบัญชี',  'Accountant',       40000, '987-654-3210', 0.03, 0.00, TRUE,  'active', DATE '2023-03-01'),
+('E003', 'อนันต์', 'มีชัย',    'ฝ่ายไอที',   'Developer',        60000, '111-222-3333', 0.03, 0.00, TRUE,  'active', DATE '2024-01-01'),
+('E004', 'กมล',    'ใจดี',     'ฝ่ายขาย',     'Sales Executive',  45000, '222-333-4444', 0.03, 0.00, TRUE,  'active', DATE '2022-06-01'),
+('E005', 'พรทิพย์','รุ่งเรือง','ฝ่ายการเงิน', 'Finance Officer', 48000, '555-666-7777', 0.03, 0.00, TRUE,  'active', DATE '2021-10-01');
 
 -- Payroll run (ตัวอย่างเดือนกันยายน 2025) ✅ ใช้ period_year/period_month
 INSERT INTO payroll_runs (period_year, period_month, locked)
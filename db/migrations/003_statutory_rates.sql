@@ -0,0 +1,16 @@
+-- statutory_rates: ตารางอัตรา SSO/ภาษีตามวันที่มีผล
+CREATE TABLE statutory_rates (
+  id SERIAL PRIMARY KEY,
+  effective_from DATE UNIQUE NOT NULL,
+  sso_min_base NUMERIC(12,2) NOT NULL,
+  sso_max_base NUMERIC(12,2) NOT NULL,
+  sso_employee_rate NUMERIC(5,4) NOT NULL,
+  sso_employer_rate NUMERIC(5,4) NOT NULL,
+  personal_allowance NUMERIC(12,2) NOT NULL,
+  expense_rate NUMERIC(5,4) NOT NULL,
+  expense_cap NUMERIC(12,2) NOT NULL,
+  tax_brackets JSONB,
+  note TEXT,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  CHECK (sso_max_base >= sso_min_base)
+);
@@ -0,0 +1,19 @@
+-- pay_components: เงินได้/เงินหักประจำของพนักงาน
+CREATE TABLE pay_components (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('earning','deduction')),
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+  start_date DATE NOT NULL,
+  end_date DATE,
+  taxable BOOLEAN DEFAULT TRUE,
+  sso_able BOOLEAN DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  CHECK (end_date IS NULL OR end_date >= start_date)
+);
+CREATE INDEX idx_pay_components_employee_id ON pay_components(employee_id);
+
+-- snapshot ของรายการประจำที่ถูกรวมใน payslip แต่ละใบ
+ALTER TABLE payslips ADD COLUMN components JSONB;
@@ -0,0 +1,37 @@
+-- payslip_lines: บรรทัดรายการของ payslip แต่ละใบ (เงินได้/เงินหัก/ต้นทุนนายจ้าง/ข้อมูลประกอบ)
+CREATE TABLE payslip_lines (
+  id SERIAL PRIMARY KEY,
+  payslip_id INT NOT NULL REFERENCES payslips(id) ON DELETE CASCADE,
+  seq INT NOT NULL,
+  code TEXT NOT NULL,
+  description TEXT,
+  category TEXT NOT NULL CHECK (category IN ('earning','deduction','employer','info')),
+  amount NUMERIC(12,2) NOT NULL,
+  quantity NUMERIC(12,4) DEFAULT 0,
+  rate NUMERIC(12,4) DEFAULT 0,
+  taxable BOOLEAN DEFAULT FALSE,
+  sso_able BOOLEAN DEFAULT FALSE
+);
+CREATE INDEX idx_payslip_lines_payslip_id ON payslip_lines(payslip_id);
+
+-- ย้ายยอดจากคอลัมน์เดิมของ payslips ที่มีอยู่แล้วเป็น lines
+INSERT INTO payslip_lines (payslip_id, seq, code, description, category, amount, taxable, sso_able)
+SELECT id, 1, 'BASE', 'Base Salary', 'earning', base_salary, TRUE, TRUE FROM payslips;
+
+INSERT INTO payslip_lines (payslip_id, seq, code, description, category, amount, taxable, sso_able)
+SELECT p.id, 1 + c.ord, c.elem->>'code', c.elem->>'name',
+       CASE WHEN c.elem->>'kind' = 'earning' THEN 'earning' ELSE 'deduction' END,
+       (c.elem->>'amount')::NUMERIC(12,2),
+       COALESCE((c.elem->>'taxable')::BOOLEAN, FALSE),
+       COALESCE((c.elem->>'ssoAble')::BOOLEAN, FALSE)
+FROM payslips p, jsonb_array_elements(p.components) WITH ORDINALITY AS c(elem, ord)
+WHERE p.components IS NOT NULL AND jsonb_typeof(p.components) = 'array';
+
+INSERT INTO payslip_lines (payslip_id, seq, code, description, category, amount)
+SELECT id, 100, 'TAX', 'Tax Withheld', 'deduction', tax_withheld FROM payslips
+UNION ALL
+SELECT id, 101, 'SSO', 'Social Security (SSO)', 'deduction', sso FROM payslips
+UNION ALL
+SELECT id, 102, 'PVD', 'Provident Fund (PVD)', 'deduction', pvd FROM payslips;
+
+ALTER TABLE payslips DROP COLUMN components;
@@ -0,0 +1,13 @@
+-- leaves: ให้ตรงกับ models.Leave (ช่วงวันที่ลา + ประเภทการลา)
+ALTER TABLE leaves
+  ADD COLUMN start_date DATE,
+  ADD COLUMN end_date DATE,
+  ADD COLUMN reason TEXT,
+  ADD COLUMN updated_at TIMESTAMPTZ DEFAULT now(),
+  ADD COLUMN leave_type TEXT NOT NULL DEFAULT 'personal'
+    CHECK (leave_type IN ('sick','personal','annual','unpaid','maternity'));
+
+UPDATE leaves SET start_date = leave_date, end_date = leave_date, reason = note
+WHERE start_date IS NULL;
+
+ALTER TABLE leaves ALTER COLUMN leave_date DROP NOT NULL;
@@ -0,0 +1,13 @@
+-- leave_policies: สิทธิวันลาแต่ละประเภท (อัตราตามอายุงาน, ยกยอดสูงสุด, กรณีลาเกินสิทธิ)
+CREATE TABLE leave_policies (
+  id SERIAL PRIMARY KEY,
+  leave_type TEXT NOT NULL UNIQUE
+    CHECK (leave_type IN ('sick','personal','annual','maternity')),
+  tiers JSONB NOT NULL DEFAULT '[]',
+  max_carry_over INT NOT NULL DEFAULT 0,
+  over_balance TEXT NOT NULL DEFAULT 'reject' CHECK (over_balance IN ('reject','unpaid')),
+  updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- ส่วนที่ลาเกินสิทธิ (นโยบาย unpaid) ถูกบันทึกเป็นลาไม่รับค่าจ้าง
+ALTER TABLE leaves ADD COLUMN unpaid BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,14 @@
+-- leaves: สถานะคำขอลา (submitted → approved/rejected, submitted/approved → cancelled)
+-- requested_by = ผู้ยื่นคำขอ (ห้ามอนุมัติใบลาที่ตนเองยื่น)
+-- ใบลาที่มีอยู่เดิมถือว่าอนุมัติแล้ว เพื่อให้ยอดสิทธิและการหักเงินเดือนไม่เปลี่ยน
+ALTER TABLE leaves
+  ADD COLUMN status TEXT NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('submitted','approved','rejected','cancelled')),
+  ADD COLUMN requested_by TEXT,
+  ADD COLUMN decided_by TEXT,
+  ADD COLUMN decided_at TIMESTAMPTZ,
+  ADD COLUMN decision_note TEXT;
+
+ALTER TABLE leaves ALTER COLUMN status SET DEFAULT 'submitted';
+
+CREATE INDEX idx_leaves_status ON leaves(status);
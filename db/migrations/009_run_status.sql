@@ -0,0 +1,22 @@
+-- payroll_runs: สถานะ run (draft → calculated → approved → paid → closed)
+-- locked คงไว้ให้ frontend เดิม โดย derive จากสถานะ approved ขึ้นไป
+ALTER TABLE payroll_runs
+  ADD COLUMN status TEXT NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft','calculated','approved','paid','closed'));
+
+UPDATE payroll_runs SET status = 'approved' WHERE locked;
+UPDATE payroll_runs r SET status = 'calculated'
+WHERE NOT r.locked AND EXISTS (SELECT 1 FROM payslips p WHERE p.payroll_run_id = r.id);
+
+-- ประวัติการเปลี่ยนสถานะ (ใคร เมื่อไร)
+CREATE TABLE payroll_run_transitions (
+  id SERIAL PRIMARY KEY,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  from_status TEXT NOT NULL,
+  to_status TEXT NOT NULL,
+  changed_by TEXT,
+  note TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_payroll_run_transitions_run_id ON payroll_run_transitions(payroll_run_id);
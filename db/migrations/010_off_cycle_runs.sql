@@ -0,0 +1,29 @@
+-- payroll_runs: ประเภท run (regular/bonus/correction/termination) และหลาย run ต่องวด
+-- run ปกติ (regular) ยังมีได้หนึ่ง run ต่องวด ส่วน run นอกรอบมีได้ไม่จำกัด
+ALTER TABLE payroll_runs DROP CONSTRAINT IF EXISTS payroll_runs_period_year_period_month_key;
+
+ALTER TABLE payroll_runs
+  ADD COLUMN run_type TEXT NOT NULL DEFAULT 'regular'
+    CHECK (run_type IN ('regular','bonus','correction','termination')),
+  ADD COLUMN description TEXT,
+  ADD COLUMN employee_ids JSONB,
+  ADD COLUMN component_codes JSONB;
+
+CREATE UNIQUE INDEX idx_payroll_runs_regular_period ON payroll_runs(period_year, period_month)
+  WHERE run_type = 'regular';
+
+-- payroll_run_inputs: รายการจ่ายครั้งเดียวของพนักงานใน run (โบนัส, ปรับปรุงย้อนหลัง ฯลฯ)
+CREATE TABLE payroll_run_inputs (
+  id SERIAL PRIMARY KEY,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  description TEXT,
+  kind TEXT NOT NULL DEFAULT 'earning' CHECK (kind IN ('earning','deduction')),
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  taxable BOOLEAN NOT NULL DEFAULT TRUE,
+  sso_able BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_payroll_run_inputs_run_id ON payroll_run_inputs(payroll_run_id);
@@ -0,0 +1,28 @@
+-- pay_groups: กลุ่มจ่ายเงินตามความถี่ (monthly, semi_monthly, biweekly, weekly)
+-- พนักงาน/run ที่ไม่มี pay_group_id อยู่ในกลุ่มรายเดือนตั้งต้น
+CREATE TABLE pay_groups (
+  id SERIAL PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  frequency TEXT NOT NULL DEFAULT 'monthly'
+    CHECK (frequency IN ('monthly','semi_monthly','biweekly','weekly')),
+  anchor_date DATE,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+ALTER TABLE employees ADD COLUMN pay_group_id INT REFERENCES pay_groups(id) ON DELETE SET NULL;
+
+-- run ของกลุ่มจ่ายเงินระบุช่วงงวด; period_year/period_month คือเดือนของวันสิ้นงวด
+ALTER TABLE payroll_runs
+  ADD COLUMN pay_group_id INT REFERENCES pay_groups(id) ON DELETE RESTRICT,
+  ADD COLUMN period_start DATE,
+  ADD COLUMN period_end DATE;
+
+-- run ปกติ: หนึ่ง run ต่อกลุ่มต่องวด
+DROP INDEX IF EXISTS idx_payroll_runs_regular_period;
+CREATE UNIQUE INDEX idx_payroll_runs_regular_period ON payroll_runs(period_year, period_month)
+  WHERE run_type = 'regular' AND pay_group_id IS NULL;
+CREATE UNIQUE INDEX idx_payroll_runs_regular_group_period ON payroll_runs(pay_group_id, period_start)
+  WHERE run_type = 'regular' AND pay_group_id IS NOT NULL;
+
+CREATE INDEX idx_employees_pay_group_id ON employees(pay_group_id);
@@ -0,0 +1,18 @@
+-- salary_records: ประวัติเงินเดือนตามวันที่มีผล (employees.base_salary = เงินเดือน ณ ปัจจุบัน)
+-- record ที่มีผลวันเดียวกันหลายรายการ ใช้ record ที่บันทึกทีหลัง (id มากกว่า)
+CREATE TABLE salary_records (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  effective_from DATE NOT NULL,
+  base_salary NUMERIC(12,2) NOT NULL CHECK (base_salary >= 0),
+  reason TEXT NOT NULL CHECK (reason IN ('hire','promotion','merit','adjustment','correction')),
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_salary_records_employee_id ON salary_records(employee_id, effective_from);
+
+-- เงินเดือนปัจจุบันของพนักงานเดิมเป็นอัตราแรกเข้า
+INSERT INTO salary_records (employee_id, effective_from, base_salary, reason)
+SELECT id, COALESCE(hired_at, CURRENT_DATE), base_salary, 'hire' FROM employees;
@@ -0,0 +1,5 @@
+-- บรรทัด RETRO (ส่วนต่างเงินเดือนย้อนหลัง) อ้างอิง run เดิมของงวดที่คำนวณใหม่
+-- ใช้กันจ่ายซ้ำและออกรายงานแยกตามงวดเดิม
+ALTER TABLE payslip_lines ADD COLUMN ref_run_id INT REFERENCES payroll_runs(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_payslip_lines_ref_run_id ON payslip_lines(ref_run_id) WHERE ref_run_id IS NOT NULL;
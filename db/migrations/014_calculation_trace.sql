@@ -0,0 +1,3 @@
+-- trace: ขั้นตอนการคำนวณของ payroll item (วันทำงาน, เงินเดือนตามสัดส่วน, ฐาน SSO,
+-- การประมาณการภาษีทั้งปี และเวอร์ชันตารางอัตรา) บันทึกตอนคำนวณ run
+ALTER TABLE payslips ADD COLUMN trace JSONB;
@@ -0,0 +1,7 @@
+-- การพ้นสภาพ: เหตุที่พ้นสภาพ วันที่บอกกล่าวล่วงหน้า และหมายเหตุ
+-- (ใช้คำนวณค่าชดเชย ค่าจ้างแทนการบอกกล่าวล่วงหน้า และค่าพักร้อนคงเหลือใน run ประเภท termination)
+-- ค่าว่าง = ยังไม่พ้นสภาพ (gorm เขียน string ว่าง ไม่ใช่ NULL)
+ALTER TABLE employees ADD COLUMN termination_reason TEXT
+  CHECK (termination_reason IN ('', 'resignation','layoff','retirement','misconduct'));
+ALTER TABLE employees ADD COLUMN notice_date DATE;
+ALTER TABLE employees ADD COLUMN termination_note TEXT;
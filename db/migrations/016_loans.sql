@@ -0,0 +1,35 @@
+-- เงินกู้/เงินเดือนล่วงหน้าที่ผ่อนชำระผ่าน payroll
+-- outstanding ลดลงเมื่ออนุมัติ run (บันทึกใน loan_repayments) และคืนเมื่อยกเลิกอนุมัติ
+CREATE TABLE loans (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  loan_type TEXT NOT NULL CHECK (loan_type IN ('advance','welfare')),
+  description TEXT,
+  principal NUMERIC(12,2) NOT NULL CHECK (principal > 0),
+  interest_rate NUMERIC(5,4) DEFAULT 0,
+  interest NUMERIC(12,2) DEFAULT 0,
+  installments INT NOT NULL CHECK (installments > 0),
+  installment NUMERIC(12,2) NOT NULL,
+  start_date DATE NOT NULL,
+  outstanding NUMERIC(12,2) NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','paused','paid_off')),
+  payoff_in_payroll BOOLEAN DEFAULT FALSE,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE loan_repayments (
+  id SERIAL PRIMARY KEY,
+  loan_id INT NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+  payroll_run_id INT REFERENCES payroll_runs(id) ON DELETE SET NULL,
+  amount NUMERIC(12,2) NOT NULL,
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- บรรทัด LOAN อ้างอิงเงินกู้ที่หัก
+ALTER TABLE payslip_lines ADD COLUMN ref_loan_id INT REFERENCES loans(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_loans_employee_id ON loans(employee_id);
+CREATE INDEX idx_loan_repayments_loan_id ON loan_repayments(loan_id);
@@ -0,0 +1,36 @@
+-- คำสั่งอายัดเงินเดือนของกรมบังคับคดี: หักตามอัตราหรือยอดคงที่ต่องวด ตามลำดับความสำคัญ
+-- โดยเงินสุทธิไม่ต่ำกว่ายอดคุ้มครอง outstanding ลดลงเมื่ออนุมัติ run (บันทึกใน garnishment_remittances)
+CREATE TABLE garnishments (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  order_no TEXT NOT NULL,
+  creditor TEXT NOT NULL,
+  creditor_account TEXT,
+  priority INT DEFAULT 0,
+  rate NUMERIC(5,4) DEFAULT 0,
+  amount NUMERIC(12,2) DEFAULT 0,
+  cap NUMERIC(12,2) DEFAULT 0,
+  protected_amount NUMERIC(12,2) NOT NULL DEFAULT 20000,
+  total NUMERIC(12,2) NOT NULL CHECK (total > 0),
+  outstanding NUMERIC(12,2) NOT NULL,
+  start_date DATE NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','suspended','satisfied','released')),
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE garnishment_remittances (
+  id SERIAL PRIMARY KEY,
+  garnishment_id INT NOT NULL REFERENCES garnishments(id) ON DELETE CASCADE,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  amount NUMERIC(12,2) NOT NULL,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- บรรทัด GARNISH อ้างอิงคำสั่งอายัด
+ALTER TABLE payslip_lines ADD COLUMN ref_garnishment_id INT REFERENCES garnishments(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_garnishments_employee_id ON garnishments(employee_id);
+CREATE INDEX idx_garnishment_remittances_garnishment_id ON garnishment_remittances(garnishment_id);
@@ -0,0 +1,2 @@
+-- รหัสธนาคาร 3 หลัก (ธปท.) ของบัญชีรับเงินเดือน ใช้ในไฟล์โอนเงินของธนาคาร (ว่าง = ธนาคารเดียวกับบริษัท)
+ALTER TABLE employees ADD COLUMN bank_code TEXT;
@@ -0,0 +1,6 @@
+-- ข้อมูลบัญชีรับเงินเดือนแบบมีโครงสร้าง: สาขา ชื่อบัญชี และเลขบัญชีเป็นตัวเลขเท่านั้น
+ALTER TABLE employees ADD COLUMN bank_branch TEXT;
+ALTER TABLE employees ADD COLUMN bank_account_name TEXT;
+
+-- เลขบัญชีเดิมบันทึกแบบ 001-234567-8 ตัดขีด/ช่องว่างออก
+UPDATE employees SET bank_account = regexp_replace(bank_account, '[- ]', '', 'g') WHERE bank_account IS NOT NULL;
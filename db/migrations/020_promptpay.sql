@@ -0,0 +1,5 @@
+-- วิธีรับเงินเดือน: โอนเข้าบัญชีธนาคาร หรือโอนผ่านพร้อมเพย์ (เบอร์มือถือ/เลขประจำตัวประชาชน)
+-- ประเภทพร้อมเพย์ว่าง = รับเงินผ่านบัญชีธนาคาร (gorm เขียน string ว่าง ไม่ใช่ NULL)
+ALTER TABLE employees ADD COLUMN payment_method TEXT NOT NULL DEFAULT 'bank' CHECK (payment_method IN ('bank','promptpay'));
+ALTER TABLE employees ADD COLUMN promptpay_type TEXT CHECK (promptpay_type IN ('', 'mobile','national_id'));
+ALTER TABLE employees ADD COLUMN promptpay_id TEXT;
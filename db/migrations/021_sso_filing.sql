@@ -0,0 +1,4 @@
+-- ข้อมูลผู้ประกันตนสำหรับยื่นเงินสมทบรายเดือน (สปส.1-10): เลขประจำตัวประชาชน และลำดับที่สาขาตามทะเบียนนายจ้าง
+-- ค่าว่าง = ยังไม่ได้กรอก (สาขาว่าง = สาขาตั้งต้นของนายจ้าง)
+ALTER TABLE employees ADD COLUMN national_id TEXT CHECK (national_id ~ '^([0-9]{13})?$');
+ALTER TABLE employees ADD COLUMN sso_branch TEXT CHECK (sso_branch ~ '^([0-9]{6})?$');
@@ -1,3 +1,14 @@
+-- Pay groups (กลุ่มจ่ายเงินตามความถี่)
+CREATE TABLE pay_groups (
+  id SERIAL PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  frequency TEXT NOT NULL DEFAULT 'monthly'
+    CHECK (frequency IN ('monthly','semi_monthly','biweekly','weekly')),
+  anchor_date DATE,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
 -- Employees
 CREATE TABLE employees (
   id SERIAL PRIMARY KEY,
@@ -8,21 +19,46 @@ CREATE TABLE employees (
   position   TEXT,
   base_salary NUMERIC(12,2) NOT NULL CHECK (base_salary >= 0),
   bank_account TEXT,
+  bank_code TEXT,
+  bank_branch TEXT,
+  bank_account_name TEXT,
+  payment_method TEXT NOT NULL DEFAULT 'bank' CHECK (payment_method IN ('bank','promptpay')),
+  promptpay_type TEXT CHECK (promptpay_type IN ('', 'mobile','national_id')),
+  promptpay_id TEXT,
   pvd_rate NUMERIC(5,4) DEFAULT 0.03,
   withholding_rate NUMERIC(5,4) DEFAULT 0,
   sso_enabled BOOLEAN DEFAULT TRUE,
+  national_id TEXT CHECK (national_id ~ '^([0-9]{13})?$'),
+  sso_branch TEXT CHECK (sso_branch ~ '^([0-9]{6})?$'),
   status TEXT DEFAULT 'active' CHECK (status IN ('active','terminated')),
   hired_at DATE DEFAULT CURRENT_DATE,
-  terminated_at DATE
+  terminated_at DATE,
+  termination_reason TEXT CHECK (termination_reason IN ('', 'resignation','layoff','retirement','misconduct')),
+  notice_date DATE,
+  termination_note TEXT,
+  pay_group_id INT REFERENCES pay_groups(id) ON DELETE SET NULL
 );
 
 -- Leaves
 CREATE TABLE leaves (
   id SERIAL PRIMARY KEY,
   employee_id INT REFERENCES employees(id) ON DELETE CASCADE,
-  leave_date DATE NOT NULL,
+  leave_type TEXT NOT NULL DEFAULT 'personal'
+    CHECK (leave_type IN ('sick','personal','annual','unpaid','maternity')),
+  start_date DATE,
+  end_date DATE,
+  reason TEXT,
+  unpaid BOOLEAN DEFAULT FALSE,
+  status TEXT NOT NULL DEFAULT 'submitted'
+    CHECK (status IN ('submitted','approved','rejected','cancelled')),
+  requested_by TEXT,
+  decided_by TEXT,
+  decided_at TIMESTAMPTZ,
+  decision_note TEXT,
+  leave_date DATE,
+  note TEXT,
   created_at TIMESTAMPTZ DEFAULT now(),
-  note TEXT
+  updated_at TIMESTAMPTZ DEFAULT now()
 );
 
 -- Payroll Runs
@@ -30,9 +66,18 @@ CREATE TABLE payroll_runs (
   id SERIAL PRIMARY KEY,
   period_year  INT NOT NULL,
   period_month INT NOT NULL CHECK (period_month BETWEEN 1 AND 12),
+  run_type TEXT NOT NULL DEFAULT 'regular'
+    CHECK (run_type IN ('regular','bonus','correction','termination')),
+  description TEXT,
+  employee_ids JSONB,
+  component_codes JSONB,
+  pay_group_id INT REFERENCES pay_groups(id) ON DELETE RESTRICT,
+  period_start DATE,
+  period_end DATE,
+  status TEXT NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft','calculated','approved','paid','closed')),
   locked BOOLEAN DEFAULT FALSE,
-  created_at TIMESTAMPTZ DEFAULT now(),
-  UNIQUE (period_year, period_month)
+  created_at TIMESTAMPTZ DEFAULT now()
 );
 
 -- Payslips
@@ -46,6 +91,7 @@ CREATE TABLE payslips (
   pvd NUMERIC(12,2) NOT NULL,
   net_pay NUMERIC(12,2) NOT NULL,
   generated_at TIMESTAMPTZ DEFAULT now(),
+  trace JSONB,
   UNIQUE (payroll_run_id, employee_id)
 );
 
@@ -58,7 +104,179 @@ CREATE TABLE exports (
   created_at TIMESTAMPTZ DEFAULT now()
 );
 
+-- statutory_rates: ตารางอัตรา SSO/ภาษีตามวันที่มีผล
+CREATE TABLE statutory_rates (
+  id SERIAL PRIMARY KEY,
+  effective_from DATE UNIQUE NOT NULL,
+  sso_min_base NUMERIC(12,2) NOT NULL,
+  sso_max_base NUMERIC(12,2) NOT NULL,
+  sso_employee_rate NUMERIC(5,4) NOT NULL,
+  sso_employer_rate NUMERIC(5,4) NOT NULL,
+  personal_allowance NUMERIC(12,2) NOT NULL,
+  expense_rate NUMERIC(5,4) NOT NULL,
+  expense_cap NUMERIC(12,2) NOT NULL,
+  tax_brackets JSONB,
+  note TEXT,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  CHECK (sso_max_base >= sso_min_base)
+);
+
+-- Pay components (เงินได้/เงินหักประจำของพนักงาน)
+CREATE TABLE pay_components (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('earning','deduction')),
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+  start_date DATE NOT NULL,
+  end_date DATE,
+  taxable BOOLEAN DEFAULT TRUE,
+  sso_able BOOLEAN DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+-- Salary history (เงินเดือนตามวันที่มีผล)
+CREATE TABLE salary_records (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  effective_from DATE NOT NULL,
+  base_salary NUMERIC(12,2) NOT NULL CHECK (base_salary >= 0),
+  reason TEXT NOT NULL CHECK (reason IN ('hire','promotion','merit','adjustment','correction')),
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Loans (เงินกู้/เงินเดือนล่วงหน้า ผ่อนชำระผ่าน payroll)
+CREATE TABLE loans (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  loan_type TEXT NOT NULL CHECK (loan_type IN ('advance','welfare')),
+  description TEXT,
+  principal NUMERIC(12,2) NOT NULL CHECK (principal > 0),
+  interest_rate NUMERIC(5,4) DEFAULT 0,
+  interest NUMERIC(12,2) DEFAULT 0,
+  installments INT NOT NULL CHECK (installments > 0),
+  installment NUMERIC(12,2) NOT NULL,
+  start_date DATE NOT NULL,
+  outstanding NUMERIC(12,2) NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','paused','paid_off')),
+  payoff_in_payroll BOOLEAN DEFAULT FALSE,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE loan_repayments (
+  id SERIAL PRIMARY KEY,
+  loan_id INT NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+  payroll_run_id INT REFERENCES payroll_runs(id) ON DELETE SET NULL,
+  amount NUMERIC(12,2) NOT NULL,
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Garnishments (คำสั่งอายัดเงินเดือนของกรมบังคับคดี)
+CREATE TABLE garnishments (
+  id SERIAL PRIMARY KEY,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  order_no TEXT NOT NULL,
+  creditor TEXT NOT NULL,
+  creditor_account TEXT,
+  priority INT DEFAULT 0,
+  rate NUMERIC(5,4) DEFAULT 0,
+  amount NUMERIC(12,2) DEFAULT 0,
+  cap NUMERIC(12,2) DEFAULT 0,
+  protected_amount NUMERIC(12,2) NOT NULL DEFAULT 20000,
+  total NUMERIC(12,2) NOT NULL CHECK (total > 0),
+  outstanding NUMERIC(12,2) NOT NULL,
+  start_date DATE NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','suspended','satisfied','released')),
+  note TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE garnishment_remittances (
+  id SERIAL PRIMARY KEY,
+  garnishment_id INT NOT NULL REFERENCES garnishments(id) ON DELETE CASCADE,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  amount NUMERIC(12,2) NOT NULL,
+  created_by TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Payslip lines (บรรทัดรายการของ payslip)
+CREATE TABLE payslip_lines (
+  id SERIAL PRIMARY KEY,
+  payslip_id INT NOT NULL REFERENCES payslips(id) ON DELETE CASCADE,
+  seq INT NOT NULL,
+  code TEXT NOT NULL,
+  description TEXT,
+  category TEXT NOT NULL CHECK (category IN ('earning','deduction','employer','info')),
+  amount NUMERIC(12,2) NOT NULL,
+  quantity NUMERIC(12,4) DEFAULT 0,
+  rate NUMERIC(12,4) DEFAULT 0,
+  taxable BOOLEAN DEFAULT FALSE,
+  sso_able BOOLEAN DEFAULT FALSE,
+  ref_run_id INT REFERENCES payroll_runs(id) ON DELETE SET NULL,
+  ref_loan_id INT REFERENCES loans(id) ON DELETE SET NULL,
+  ref_garnishment_id INT REFERENCES garnishments(id) ON DELETE SET NULL
+);
+
+-- Leave policies (สิทธิวันลาตามประเภท)
+CREATE TABLE leave_policies (
+  id SERIAL PRIMARY KEY,
+  leave_type TEXT NOT NULL UNIQUE
+    CHECK (leave_type IN ('sick','personal','annual','maternity')),
+  tiers JSONB NOT NULL DEFAULT '[]',
+  max_carry_over INT NOT NULL DEFAULT 0,
+  over_balance TEXT NOT NULL DEFAULT 'reject' CHECK (over_balance IN ('reject','unpaid')),
+  updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Payroll run status history
+CREATE TABLE payroll_run_transitions (
+  id SERIAL PRIMARY KEY,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  from_status TEXT NOT NULL,
+  to_status TEXT NOT NULL,
+  changed_by TEXT,
+  note TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Payroll run inputs (รายการจ่ายครั้งเดียวใน run)
+CREATE TABLE payroll_run_inputs (
+  id SERIAL PRIMARY KEY,
+  payroll_run_id INT NOT NULL REFERENCES payroll_runs(id) ON DELETE CASCADE,
+  employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  description TEXT,
+  kind TEXT NOT NULL DEFAULT 'earning' CHECK (kind IN ('earning','deduction')),
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  taxable BOOLEAN NOT NULL DEFAULT TRUE,
+  sso_able BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
 -- Indexes
 CREATE INDEX idx_leaves_employee_id ON leaves(employee_id);
+CREATE INDEX idx_leaves_status ON leaves(status);
 CREATE INDEX idx_payslips_employee_id ON payslips(employee_id);
-CREATE INDEX idx_payslips_payroll_run_id ON payslips(payroll_run_id);
\ No newline at end of file
+CREATE INDEX idx_payslips_payroll_run_id ON payslips(payroll_run_id);
+CREATE INDEX idx_pay_components_employee_id ON pay_components(employee_id);
+CREATE INDEX idx_payslip_lines_payslip_id ON payslip_lines(payslip_id);
+CREATE INDEX idx_payroll_run_transitions_run_id ON payroll_run_transitions(payroll_run_id);
+CREATE UNIQUE INDEX idx_payroll_runs_regular_period ON payroll_runs(period_year, period_month) WHERE run_type = 'regular' AND pay_group_id IS NULL;
+CREATE UNIQUE INDEX idx_payroll_runs_regular_group_period ON payroll_runs(pay_group_id, period_start) WHERE run_type = 'regular' AND pay_group_id IS NOT NULL;
+CREATE INDEX idx_payroll_run_inputs_run_id ON payroll_run_inputs(payroll_run_id);
+CREATE INDEX idx_employees_pay_group_id ON employees(pay_group_id);
+CREATE INDEX idx_salary_records_employee_id ON salary_records(employee_id, effective_from);
+CREATE INDEX idx_payslip_lines_ref_run_id ON payslip_lines(ref_run_id) WHERE ref_run_id IS NOT NULL;
+CREATE INDEX idx_loans_employee_id ON loans(employee_id);
+CREATE INDEX idx_loan_repayments_loan_id ON loan_repayments(loan_id);
+CREATE INDEX idx_garnishments_employee_id ON garnishments(employee_id);
+CREATE INDEX idx_garnishment_remittances_garnishment_id ON garnishment_remittances(garnishment_id);
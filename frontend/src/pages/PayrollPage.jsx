@@ -155,7 +155,7 @@ export default function PayrollPage() {
 
     try {
       // Call backend to export CSV
-      window.open(`${process.env.REACT_APP_API_URL}/payroll/runs/${currentRun.id}/export-bank-csv`, '_blank');
+      window.open(`${process.env.REACT_APP_API_URL}/payroll/runs/${currentRun.id}/bank-file`, '_blank');
     } catch (err) {
       console.error("Failed to export CSV:", err);
       setError("ไม่สามารถ export CSV ได้");